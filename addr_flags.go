@@ -0,0 +1,360 @@
+package sockaddr
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AddrFlags are the flags the kernel keeps for an interface address, e.g.
+// whether an IPv6 address is an RFC 4941 temporary address.  They are only
+// known on Linux; elsewhere the AddrFlags of an IfAddr are zero.
+type AddrFlags uint32
+
+const (
+	// AddrFlagTemporary is set on RFC 4941 temporary (privacy) IPv6
+	// addresses.
+	AddrFlagTemporary AddrFlags = 1 << iota
+
+	// AddrFlagDeprecated is set on addresses whose preferred lifetime has
+	// expired.  They are not used as the source of new connections.
+	AddrFlagDeprecated
+
+	// AddrFlagTentative is set on IPv6 addresses that have not passed
+	// duplicate address detection yet.
+	AddrFlagTentative
+
+	// AddrFlagDADFailed is set on IPv6 addresses that failed duplicate
+	// address detection.
+	AddrFlagDADFailed
+
+	// AddrFlagPermanent is set on addresses that do not expire, e.g.
+	// statically configured addresses.
+	AddrFlagPermanent
+
+	// AddrFlagDynamic is set on addresses that expire, e.g. addresses
+	// configured by DHCP or SLAAC.  It is the opposite of
+	// AddrFlagPermanent.
+	AddrFlagDynamic
+
+	// AddrFlagNoPrefixRoute is set on addresses for which the kernel does
+	// not add a route to the network of the address.
+	AddrFlagNoPrefixRoute
+)
+
+// LifetimeForever is the preferred or valid lifetime of an address that does
+// not expire.
+const LifetimeForever = time.Duration(math.MaxInt64)
+
+// addrFlagNames are the names of the AddrFlags in the order they are printed.
+var addrFlagNames = []struct {
+	flag AddrFlags
+	name string
+}{
+	{AddrFlagTemporary, "temporary"},
+	{AddrFlagDeprecated, "deprecated"},
+	{AddrFlagTentative, "tentative"},
+	{AddrFlagDADFailed, "dadfailed"},
+	{AddrFlagPermanent, "permanent"},
+	{AddrFlagDynamic, "dynamic"},
+	{AddrFlagNoPrefixRoute, "noprefixroute"},
+}
+
+// String returns the names of the flags separated by "|", or an empty string
+// if no flags are set.
+func (f AddrFlags) String() string {
+	var names []string
+	for _, n := range addrFlagNames {
+		if f&n.flag != 0 {
+			names = append(names, n.name)
+		}
+	}
+	return strings.Join(names, "|")
+}
+
+// lifetimeString returns the lifetime in seconds, "forever" for
+// LifetimeForever, or an empty string if the flags of the address, and hence
+// its lifetimes, are unknown.
+func lifetimeString(flags AddrFlags, lifetime time.Duration) string {
+	switch {
+	case flags == 0:
+		return ""
+	case lifetime == LifetimeForever:
+		return "forever"
+	default:
+		return strconv.FormatInt(int64(lifetime/time.Second), 10)
+	}
+}
+
+// parseAddrFlags parses the names of AddrFlags joined by "|" as printed by
+// AddrFlags.String.
+func parseAddrFlags(s string) (AddrFlags, error) {
+	var flags AddrFlags
+	if s == "" {
+		return flags, nil
+	}
+
+	for _, name := range strings.Split(s, "|") {
+		var found bool
+		for _, n := range addrFlagNames {
+			if n.name == name {
+				flags |= n.flag
+				found = true
+				break
+			}
+		}
+		if !found {
+			return 0, fmt.Errorf("unknown address flag %q", name)
+		}
+	}
+	return flags, nil
+}
+
+// parseLifetime parses a lifetime in seconds or "forever" as printed by
+// lifetimeString.
+func parseLifetime(s string) (time.Duration, error) {
+	if s == "forever" {
+		return LifetimeForever, nil
+	}
+
+	seconds, err := strconv.ParseUint(s, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid lifetime %q", s)
+	}
+	return time.Duration(seconds) * time.Second, nil
+}
+
+// addrState is the state the kernel keeps for an interface address.
+type addrState struct {
+	flags             AddrFlags
+	preferredLifetime time.Duration
+	validLifetime     time.Duration
+}
+
+// addrStateKey identifies an address by the index of its interface and the
+// string form of its host address.
+type addrStateKey struct {
+	index   int
+	address string
+}
+
+// addrStateReader is implemented by SystemProviders that know the state of
+// the interface addresses.  GetAllInterfaces uses it to set the AddrFlags and
+// lifetimes of each IfAddr.
+type addrStateReader interface {
+	addrStates() (map[addrStateKey]addrState, error)
+}
+
+// Address flags and attributes from the Linux kernel's <linux/if_addr.h>.
+const (
+	rtmNewAddr          = 20
+	ifAddrMsgLen        = 8
+	ifaAddress          = 1
+	ifaLocal            = 2
+	ifaCacheInfo        = 6
+	ifaFlags            = 8
+	ifaFTemporary       = 0x01
+	ifaFDADFailed       = 0x08
+	ifaFDeprecated      = 0x20
+	ifaFTentative       = 0x40
+	ifaFPermanent       = 0x80
+	ifaFNoPrefixRoute   = 0x200
+	ifaInfinityLifetime = 0xffffffff
+)
+
+// kernelAddrFlags returns the AddrFlags for the IFA_F_* flags of an address.
+// IFA_F_SECONDARY has the value of IFA_F_TEMPORARY, so the temporary flag is
+// only set on IPv6 addresses.
+func kernelAddrFlags(flags uint32, family uint8) AddrFlags {
+	var f AddrFlags
+	if flags&ifaFTemporary != 0 && family == afInet6 {
+		f |= AddrFlagTemporary
+	}
+	if flags&ifaFDeprecated != 0 {
+		f |= AddrFlagDeprecated
+	}
+	if flags&ifaFTentative != 0 {
+		f |= AddrFlagTentative
+	}
+	if flags&ifaFDADFailed != 0 {
+		f |= AddrFlagDADFailed
+	}
+	if flags&ifaFPermanent != 0 {
+		f |= AddrFlagPermanent
+	} else {
+		f |= AddrFlagDynamic
+	}
+	if flags&ifaFNoPrefixRoute != 0 {
+		f |= AddrFlagNoPrefixRoute
+	}
+	return f
+}
+
+// kernelLifetime returns the lifetime for a lifetime in seconds reported by
+// the kernel.
+func kernelLifetime(seconds uint32) time.Duration {
+	if seconds == ifaInfinityLifetime {
+		return LifetimeForever
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// parseNetlinkAddrs parses the RTM_NEWADDR messages of a netlink address dump
+// in host byte order.
+func parseNetlinkAddrs(b []byte) (map[addrStateKey]addrState, error) {
+	states := make(map[addrStateKey]addrState)
+	for len(b) > 0 {
+		if len(b) < nlmsgHdrLen {
+			return nil, fmt.Errorf("truncated netlink message header")
+		}
+		msgLen := int(binary.NativeEndian.Uint32(b[0:4]))
+		msgType := binary.NativeEndian.Uint16(b[4:6])
+		if msgLen < nlmsgHdrLen || msgLen > len(b) {
+			return nil, fmt.Errorf("invalid netlink message length %d", msgLen)
+		}
+		data := b[nlmsgHdrLen:msgLen]
+		b = b[min(netlinkAlign(msgLen), len(b)):]
+
+		switch msgType {
+		case nlmsgDone:
+			continue
+		case nlmsgError:
+			return nil, fmt.Errorf("netlink address dump failed")
+		case rtmNewAddr:
+		default:
+			continue
+		}
+
+		key, state, ok, err := parseNetlinkAddr(data)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			states[key] = state
+		}
+	}
+
+	return states, nil
+}
+
+// parseNetlinkAddr parses the body of a single RTM_NEWADDR message.  Returns
+// false if the address is not an IPv4 or IPv6 address.
+func parseNetlinkAddr(data []byte) (addrStateKey, addrState, bool, error) {
+	if len(data) < ifAddrMsgLen {
+		return addrStateKey{}, addrState{}, false, fmt.Errorf("truncated address message")
+	}
+
+	family := data[0]
+	var addrLen int
+	switch family {
+	case afInet:
+		addrLen = IPv4len
+	case afInet6:
+		addrLen = IPv6len
+	default:
+		return addrStateKey{}, addrState{}, false, nil
+	}
+
+	flags := uint32(data[2])
+	index := int(binary.NativeEndian.Uint32(data[4:8]))
+	state := addrState{
+		preferredLifetime: LifetimeForever,
+		validLifetime:     LifetimeForever,
+	}
+
+	// IFA_LOCAL is the address of the interface; IFA_ADDRESS is the address
+	// of the peer on point-to-point interfaces and otherwise the same.
+	var address, local IPAddr
+	attrs := data[ifAddrMsgLen:]
+	for len(attrs) >= rtaHdrLen {
+		attrLen := int(binary.NativeEndian.Uint16(attrs[0:2]))
+		attrType := binary.NativeEndian.Uint16(attrs[2:4])
+		if attrLen < rtaHdrLen || attrLen > len(attrs) {
+			return addrStateKey{}, addrState{}, false, fmt.Errorf("invalid address attribute length %d", attrLen)
+		}
+		value := attrs[rtaHdrLen:attrLen]
+		attrs = attrs[min(netlinkAlign(attrLen), len(attrs)):]
+
+		switch attrType {
+		case ifaAddress, ifaLocal:
+			if len(value) != addrLen {
+				return addrStateKey{}, addrState{}, false, fmt.Errorf("invalid address length %d in address attribute %d", len(value), attrType)
+			}
+			if attrType == ifaAddress {
+				address = netlinkIPAddr(value, addrLen*8)
+			} else {
+				local = netlinkIPAddr(value, addrLen*8)
+			}
+		case ifaCacheInfo:
+			if len(value) < 8 {
+				return addrStateKey{}, addrState{}, false, fmt.Errorf("invalid length %d in address attribute %d", len(value), attrType)
+			}
+			state.preferredLifetime = kernelLifetime(binary.NativeEndian.Uint32(value[0:4]))
+			state.validLifetime = kernelLifetime(binary.NativeEndian.Uint32(value[4:8]))
+		case ifaFlags:
+			if len(value) != 4 {
+				return addrStateKey{}, addrState{}, false, fmt.Errorf("invalid length %d in address attribute %d", len(value), attrType)
+			}
+			flags = binary.NativeEndian.Uint32(value)
+		}
+	}
+
+	if local != nil {
+		address = local
+	}
+	if address == nil {
+		return addrStateKey{}, addrState{}, false, nil
+	}
+
+	state.flags = kernelAddrFlags(flags, family)
+	return addrStateKey{index: index, address: address.NetIP().String()}, state, true, nil
+}
+
+// parseProcNetIfInet6 parses the IPv6 addresses of /proc/net/if_inet6.  The
+// file has no lifetimes, so the lifetimes of permanent addresses are reported
+// as LifetimeForever and those of dynamic addresses as zero.
+func parseProcNetIfInet6(r io.Reader) (map[addrStateKey]addrState, error) {
+	states := make(map[addrStateKey]addrState)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 6 {
+			return nil, fmt.Errorf("unable to parse /proc/net/if_inet6 line %q", scanner.Text())
+		}
+
+		ip, err := hex.DecodeString(fields[0])
+		if err != nil || len(ip) != IPv6len {
+			return nil, fmt.Errorf("unable to parse address %q", fields[0])
+		}
+
+		index, err := strconv.ParseUint(fields[1], 16, 32)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse interface index %q: %v", fields[1], err)
+		}
+		flags, err := strconv.ParseUint(fields[4], 16, 32)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse address flags %q: %v", fields[4], err)
+		}
+
+		address := netlinkIPAddr(ip, IPv6len*8)
+		state := addrState{flags: kernelAddrFlags(uint32(flags), afInet6)}
+		if state.flags&AddrFlagPermanent != 0 {
+			state.preferredLifetime, state.validLifetime = LifetimeForever, LifetimeForever
+		}
+		states[addrStateKey{index: int(index), address: address.NetIP().String()}] = state
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return states, nil
+}
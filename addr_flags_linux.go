@@ -0,0 +1,25 @@
+package sockaddr
+
+import (
+	"os"
+	"syscall"
+)
+
+// addrStates reads the flags and lifetimes of the addresses of the host from
+// netlink, falling back to the IPv6 addresses of /proc/net/if_inet6 if netlink
+// is unavailable.
+func (hostSystem) addrStates() (map[addrStateKey]addrState, error) {
+	if b, err := syscall.NetlinkRIB(syscall.RTM_GETADDR, syscall.AF_UNSPEC); err == nil {
+		if states, err := parseNetlinkAddrs(b); err == nil {
+			return states, nil
+		}
+	}
+
+	f, err := os.Open("/proc/net/if_inet6")
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return parseProcNetIfInet6(f)
+}
@@ -0,0 +1,11 @@
+// +build !linux
+
+package sockaddr
+
+import "errors"
+
+// addrStates is not supported on this platform, so the AddrFlags and lifetimes
+// of interface addresses are left unset.
+func (hostSystem) addrStates() (map[addrStateKey]addrState, error) {
+	return nil, errors.New("address flags are not supported on this platform")
+}
@@ -0,0 +1,198 @@
+package sockaddr
+
+import (
+	"encoding/binary"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_parseNetlinkAddrs(t *testing.T) {
+	if binary.NativeEndian.Uint16([]byte{1, 0}) != 1 {
+		t.Skip("fixture was captured on a little-endian host")
+	}
+
+	b, err := os.ReadFile(filepath.Join("testdata", "netlink_addrs"))
+	if err != nil {
+		t.Fatalf("unable to read fixture: %v", err)
+	}
+
+	states, err := parseNetlinkAddrs(b)
+	if err != nil {
+		t.Fatalf("unable to parse fixture: %v", err)
+	}
+
+	// The dynamic lifetimes were counting down when the fixture was
+	// captured, so only their order of magnitude is checked.
+	tests := []struct {
+		index     int
+		address   string
+		flags     string
+		preferred time.Duration
+		valid     time.Duration
+	}{
+		{1, "127.0.0.1", "permanent", LifetimeForever, LifetimeForever},
+		{2, "198.51.100.7", "permanent", LifetimeForever, LifetimeForever},
+		{2, "198.51.100.8", "dynamic", 900 * time.Second, 900 * time.Second},
+		{2, "2001:db8:1::10", "dynamic", 3600 * time.Second, 7200 * time.Second},
+		{2, "2001:db8:1::20", "deprecated|dynamic|noprefixroute", 0, 600 * time.Second},
+		{2, "fe80::ce8:e4ff:fede:a145", "permanent", LifetimeForever, LifetimeForever},
+		{4, "fd00::2", "permanent", LifetimeForever, LifetimeForever},
+	}
+	for _, test := range tests {
+		state, found := states[addrStateKey{index: test.index, address: test.address}]
+		if !found {
+			t.Errorf("%d %s: not found", test.index, test.address)
+			continue
+		}
+		if got := state.flags.String(); got != test.flags {
+			t.Errorf("%d %s: want flags %q, got %q", test.index, test.address, test.flags, got)
+		}
+		if !lifetimeNear(state.preferredLifetime, test.preferred) || !lifetimeNear(state.validLifetime, test.valid) {
+			t.Errorf("%d %s: want lifetimes %v/%v, got %v/%v", test.index, test.address, test.preferred, test.valid, state.preferredLifetime, state.validLifetime)
+		}
+	}
+
+	if _, err := parseNetlinkAddrs(b[:len(b)-1]); err == nil {
+		t.Errorf("expected a truncated dump to fail")
+	}
+}
+
+// lifetimeNear returns true if got is within ten seconds below want.
+func lifetimeNear(got, want time.Duration) bool {
+	if want == LifetimeForever {
+		return got == LifetimeForever
+	}
+	return got <= want && want-got <= 10*time.Second
+}
+
+func Test_parseProcNetIfInet6(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "proc_net_if_inet6"))
+	if err != nil {
+		t.Fatalf("unable to open fixture: %v", err)
+	}
+	defer f.Close()
+
+	states, err := parseProcNetIfInet6(f)
+	if err != nil {
+		t.Fatalf("unable to parse fixture: %v", err)
+	}
+
+	var got []string
+	for key, state := range states {
+		got = append(got, key.address+" "+state.flags.String()+" "+lifetimeString(state.flags, state.validLifetime))
+	}
+	sort.Strings(got)
+	want := []string{
+		"2001:db8:1::10 dynamic 0",
+		"2001:db8:1::20 deprecated|dynamic 0",
+		"::1 permanent forever",
+		"fd00::2 permanent forever",
+		"fe80::ce8:e4ff:fede:a145 permanent forever",
+		"fe80::fc:ff:fe00:1 permanent forever",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("want:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+
+	for _, input := range []string{
+		"fe80 02 40 20 80 eth0",
+		"zz800000000000000ce8e4fffedea145 02 40 20 80 eth0",
+		"fe800000000000000ce8e4fffedea145 02 40 20 eth0",
+	} {
+		if _, err := parseProcNetIfInet6(strings.NewReader(input)); err == nil {
+			t.Errorf("expected %q to fail", input)
+		}
+	}
+}
+
+func Test_kernelAddrFlags(t *testing.T) {
+	tests := []struct {
+		flags  uint32
+		family uint8
+		want   string
+	}{
+		{0x80, afInet, "permanent"},
+		{0x01, afInet, "dynamic"},
+		{0x01, afInet6, "temporary|dynamic"},
+		{0x48, afInet6, "tentative|dadfailed|dynamic"},
+		{0x2a0, afInet6, "deprecated|permanent|noprefixroute"},
+	}
+	for _, test := range tests {
+		if got := kernelAddrFlags(test.flags, test.family).String(); got != test.want {
+			t.Errorf("%#x/%d: want %q, got %q", test.flags, test.family, test.want, got)
+		}
+	}
+}
+
+// addrStateSystem is a FakeSystem that knows the state of its addresses.
+type addrStateSystem struct {
+	*FakeSystem
+	states map[addrStateKey]addrState
+}
+
+func (s addrStateSystem) addrStates() (map[addrStateKey]addrState, error) {
+	return s.states, nil
+}
+
+func TestGetAllInterfacesAddrFlags(t *testing.T) {
+	SetSystemProvider(addrStateSystem{
+		FakeSystem: NewFakeSystem().
+			AddInterface(net.Interface{Name: "eth0", Flags: net.FlagUp},
+				MustIPv6Addr("2001:db8::5/64"),
+				MustIPv6Addr("2001:db8::1234:5678/64"),
+				MustIPv4Addr("10.0.0.5/24")),
+		states: map[addrStateKey]addrState{
+			{1, "2001:db8::5"}:         {AddrFlagPermanent, LifetimeForever, LifetimeForever},
+			{1, "2001:db8::1234:5678"}: {AddrFlagTemporary | AddrFlagDynamic, 3500 * time.Second, 86000 * time.Second},
+		},
+	})
+	defer SetSystemProvider(nil)
+
+	ifAddrs, err := GetAllInterfaces()
+	if err != nil {
+		t.Fatalf("unable to get interfaces: %v", err)
+	}
+
+	var got []string
+	for _, ifAddr := range ifAddrs {
+		got = append(got, IfAddrAttr(ifAddr, "address_flags")+"/"+IfAddrAttr(ifAddr, "preferred_lifetime")+"/"+IfAddrAttr(ifAddr, "valid_lifetime"))
+	}
+	want := "permanent/forever/forever,temporary|dynamic/3500/86000,//"
+	if strings.Join(got, ",") != want {
+		t.Errorf("want %s, got %s", want, strings.Join(got, ","))
+	}
+
+	_, remainder, err := IfByFlag("temporary|deprecated|tentative", ifAddrs)
+	if err != nil {
+		t.Fatalf("unable to filter: %v", err)
+	}
+	if len(remainder) != 2 || remainder[0].SockAddr.String() != "2001:db8::5/64" {
+		t.Errorf("expected the temporary address to be excluded, got %v", remainder)
+	}
+
+	// Address flags and the other flags must both match.
+	for _, test := range []struct {
+		flags string
+		want  int
+	}{
+		{flags: "broadcast|up", want: 0},
+		{flags: "broadcast|up|temporary", want: 0},
+		{flags: "up|temporary", want: 1},
+		{flags: "up|temporary|permanent", want: 2},
+		{flags: "global unicast|temporary", want: 1},
+		{flags: "loopback|temporary", want: 0},
+	} {
+		matched, _, err := IfByFlag(test.flags, ifAddrs)
+		if err != nil {
+			t.Fatalf("unable to filter by %q: %v", test.flags, err)
+		}
+		if len(matched) != test.want {
+			t.Errorf("%q: want %d matches, got %v", test.flags, test.want, matched)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package sockaddr
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// bogonList holds the "full bogons" list: address space that has not been
+// allocated to a regional registry.  Networks are kept as sorted, merged
+// ranges per address family so that lookups in lists with tens of thousands
+// of entries are cheap.
+type bogonList struct {
+	lock   sync.RWMutex
+	ranges map[SockAddrType][]ipRange
+}
+
+var bogons bogonList
+
+// IsBogon returns true if sa is a bogon: a martian address that must never be
+// seen on the public internet or, if a full bogons list has been loaded (see
+// LoadBogonsFile), an address from unallocated space.  Martians are multicast
+// addresses and addresses within a block of the RFC registry that is not
+// globally reachable (see IsMartian).  Non-IP addresses are never bogons.
+func IsBogon(sa SockAddr) bool {
+	return IsMartian(sa) || isFullBogon(sa)
+}
+
+// IsMartian returns true if sa is a multicast address or is within a block of
+// the RFC registry that is not globally reachable (e.g. RFC 1918 or
+// documentation space).  Blocks whose global reachability is not applicable,
+// such as 6to4 (2002::/16) and Teredo (2001::/32), are not martian.  The mask
+// of sa is ignored.
+func IsMartian(sa SockAddr) bool {
+	if sa == nil {
+		return false
+	}
+
+	ip := ToIPAddr(sa)
+	if ip == nil {
+		return false
+	}
+	host := (*ip).Host()
+
+	if IsMulticast(host) {
+		return true
+	}
+
+	sp, found := SpecialPurposeOf(host)
+	return found && !sp.Global && !sp.GlobalNotApplicable
+}
+
+// LoadBogonsFile replaces the full bogons list with the networks read from
+// path (see LoadBogons).
+func LoadBogonsFile(path string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("unable to open bogons file: %v", err)
+	}
+	defer f.Close()
+
+	return LoadBogons(f)
+}
+
+// LoadBogons replaces the full bogons list with the networks read from r, one
+// network in CIDR notation per line, as published by Team Cymru (e.g.
+// fullbogons-ipv4.txt and fullbogons-ipv6.txt).  Blank lines and text
+// following a '#' are ignored.  IPv4 and IPv6 lists are combined by loading
+// them from a single reader, e.g. with io.MultiReader.  Returns the number of
+// networks that were loaded.
+func LoadBogons(r io.Reader) (int, error) {
+	var networks SockAddrs
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if i := strings.IndexByte(text, '#'); i >= 0 {
+			text = text[:i]
+		}
+
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+
+		network, err := NewIPAddr(text)
+		if err != nil {
+			return 0, fmt.Errorf("line %d: invalid network %q: %v", line, text, err)
+		}
+		networks = append(networks, network)
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, fmt.Errorf("unable to read bogons: %v", err)
+	}
+
+	if err := SetBogons(networks); err != nil {
+		return 0, err
+	}
+	return len(networks), nil
+}
+
+// SetBogons replaces the full bogons list.  Calling SetBogons with an empty
+// list clears it, leaving only martians to be reported as bogons.
+func SetBogons(networks SockAddrs) error {
+	ranges := make(map[SockAddrType][]ipRange, 2)
+	for _, network := range networks {
+		ip := ToIPAddr(network)
+		if network == nil || ip == nil {
+			return fmt.Errorf("unable to use %v as a bogon: only IP networks are supported", network)
+		}
+
+		first, last := ipAddrRange(*ip)
+		ranges[network.Type()] = append(ranges[network.Type()], ipRange{first: first, last: last})
+	}
+
+	for sockType := range ranges {
+		ranges[sockType] = mergeRanges(ranges[sockType])
+	}
+
+	bogons.lock.Lock()
+	defer bogons.lock.Unlock()
+	bogons.ranges = ranges
+	return nil
+}
+
+// isFullBogon returns true if the address of sa is in the full bogons list.
+func isFullBogon(sa SockAddr) bool {
+	if sa == nil {
+		return false
+	}
+
+	ip := ToIPAddr(sa)
+	if ip == nil {
+		return false
+	}
+	addr, _ := ipAddrRange((*ip).Host())
+
+	bogons.lock.RLock()
+	defer bogons.lock.RUnlock()
+
+	ranges := bogons.ranges[sa.Type()]
+	i := sort.Search(len(ranges), func(i int) bool {
+		return ranges[i].first.Cmp(addr) > 0
+	})
+	return i > 0 && ranges[i-1].last.Cmp(addr) >= 0
+}
+
+// mergeRanges sorts ranges and merges the ones that overlap or are adjacent.
+func mergeRanges(ranges []ipRange) []ipRange {
+	sort.Slice(ranges, func(i, j int) bool {
+		return ranges[i].first.Cmp(ranges[j].first) < 0
+	})
+
+	merged := ranges[:0]
+	one := big.NewInt(1)
+	for _, r := range ranges {
+		if n := len(merged); n > 0 {
+			prev := &merged[n-1]
+			if next := new(big.Int).Add(prev.last, one); r.first.Cmp(next) <= 0 {
+				if r.last.Cmp(prev.last) > 0 {
+					prev.last = r.last
+				}
+				continue
+			}
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
@@ -0,0 +1,91 @@
+package sockaddr_test
+
+import (
+	"strings"
+	"testing"
+
+	sockaddr "github.com/hashicorp/go-sockaddr"
+)
+
+const testFullBogons = `# last updated 1700000000 (Tue Nov 14 22:13:20 2023 GMT)
+0.0.0.0/8
+41.62.0.0/16
+41.63.0.0/16
+102.0.0.0/8
+2001:db8::/32
+3ffe::/16
+`
+
+func TestIsBogon(t *testing.T) {
+	defer sockaddr.SetBogons(nil)
+
+	tests := []struct {
+		addr    string
+		martian bool
+		bogon   bool
+	}{
+		{addr: "8.8.8.8"},
+		{addr: "10.1.2.3/8", martian: true, bogon: true},
+		{addr: "100.64.0.1", martian: true, bogon: true},
+		{addr: "192.0.2.1", martian: true, bogon: true},
+		{addr: "224.0.0.1", martian: true, bogon: true},
+		{addr: "240.0.0.1", martian: true, bogon: true},
+		{addr: "41.62.1.1", bogon: true},
+		{addr: "41.63.255.255", bogon: true},
+		{addr: "41.64.0.0"},
+		{addr: "102.1.1.1/16", bogon: true},
+		{addr: "2600::1"},
+		{addr: "2001::1"},
+		{addr: "2001:0:4136:e378::1"},
+		{addr: "2001:2::1", martian: true, bogon: true},
+		{addr: "2002:c000:204::1"},
+		{addr: "3ffe::1", bogon: true},
+		{addr: "fe80::1", martian: true, bogon: true},
+		{addr: "ff02::1", martian: true, bogon: true},
+	}
+
+	n, err := sockaddr.LoadBogons(strings.NewReader(testFullBogons))
+	if err != nil {
+		t.Fatalf("unable to load bogons: %v", err)
+	}
+	if n != 6 {
+		t.Fatalf("want 6 networks, got %d", n)
+	}
+
+	for _, test := range tests {
+		t.Run(test.addr, func(t *testing.T) {
+			ip := sockaddr.MustIPAddr(test.addr)
+			if martian := sockaddr.IsMartian(ip); martian != test.martian {
+				t.Fatalf("want martian %t", test.martian)
+			}
+			if bogon := sockaddr.IsBogon(ip); bogon != test.bogon {
+				t.Fatalf("want bogon %t", test.bogon)
+			}
+		})
+	}
+
+	ifAddrs := sockaddr.IfAddrs{
+		{SockAddr: sockaddr.MustIPAddr("41.62.1.1/16")},
+		{SockAddr: sockaddr.MustIPAddr("8.8.8.8/24")},
+		{SockAddr: sockaddr.MustIPAddr("fd00::1/64")},
+	}
+	matched, remainder, err := sockaddr.IfByFlag("bogon", ifAddrs)
+	if err != nil {
+		t.Fatalf("unable to filter: %v", err)
+	}
+	if len(matched) != 2 || len(remainder) != 1 || remainder[0].SockAddr.String() != "8.8.8.8/24" {
+		t.Fatalf("unexpected result: %v and %v", matched, remainder)
+	}
+
+	// Without a full bogons list only martians are bogons.
+	if err := sockaddr.SetBogons(nil); err != nil {
+		t.Fatalf("unable to clear bogons: %v", err)
+	}
+	if sockaddr.IsBogon(sockaddr.MustIPAddr("41.62.1.1")) {
+		t.Fatalf("cleared bogons still matched")
+	}
+
+	if _, err := sockaddr.LoadBogons(strings.NewReader("10.0.0.0/8\nbogus\n")); err == nil {
+		t.Fatalf("expected an invalid network to fail")
+	}
+}
@@ -0,0 +1,114 @@
+package sockaddr
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// addressClassNetworks maps the name of each address class to the networks
+// that make up the class.
+var addressClassNetworks = map[string][]string{
+	// RFC 2544 and RFC 5180
+	"benchmarking": {"198.18.0.0/15", "2001:2::/48"},
+
+	// RFC 6598 Shared Address Space
+	"cgnat": {"100.64.0.0/10"},
+
+	// RFC 5737 and RFC 3849
+	"documentation": {"192.0.2.0/24", "198.51.100.0/24", "203.0.113.0/24", "2001:db8::/32"},
+
+	// RFC 3927 and RFC 4291
+	"link-local": {"169.254.0.0/16", "fe80::/10"},
+
+	// RFC 1122 and RFC 4291
+	"loopback": {"127.0.0.0/8", "::1/128"},
+
+	// RFC 5771 and RFC 4291
+	"multicast": {"224.0.0.0/4", "ff00::/8"},
+
+	// RFC 1918 and RFC 4193
+	"private": {"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "fc00::/7"},
+
+	// RFC 4193 Unique Local Addresses
+	"ula": {"fc00::/7"},
+}
+
+var (
+	addressClassesOnce sync.Once
+	addressClasses     map[string]SockAddrs
+)
+
+// classNetworks returns the networks of every address class.  The networks
+// are parsed on first use because NewIPAddr depends on state that is only set
+// up in init().
+func classNetworks() map[string]SockAddrs {
+	addressClassesOnce.Do(func() {
+		addressClasses = make(map[string]SockAddrs, len(addressClassNetworks))
+		for name, networks := range addressClassNetworks {
+			sas := make(SockAddrs, 0, len(networks))
+			for _, network := range networks {
+				sas = append(sas, MustIPAddr(network))
+			}
+			addressClasses[name] = sas
+		}
+	})
+	return addressClasses
+}
+
+// KnownClasses returns the sorted names of the known address classes (e.g.
+// "private", "loopback", or "documentation").
+func KnownClasses() []string {
+	names := make([]string, 0, len(addressClassNetworks))
+	for name := range addressClassNetworks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ClassesOf returns the sorted names of every address class that contains
+// sa.
+func ClassesOf(sa SockAddr) []string {
+	var names []string
+	for _, name := range KnownClasses() {
+		if IsClass(name, sa) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// IsClass returns true if sa is part of the named address class.  Returns
+// false for unknown classes.
+func IsClass(className string, sa SockAddr) bool {
+	networks, found := classNetworks()[strings.ToLower(className)]
+	if !found {
+		return false
+	}
+
+	for _, network := range networks {
+		if network.Contains(sa) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsRFCOrClass is like IsRFC but accepts either an RFC number or the name of
+// an address class (see KnownClasses).
+func IsRFCOrClass(rfcOrClass string, sa SockAddr) (bool, error) {
+	if rfcNum, err := strconv.ParseUint(rfcOrClass, 10, 32); err == nil {
+		if _, found := LookupRFC(uint(rfcNum)); !found {
+			return false, fmt.Errorf("unsupported RFC %d", rfcNum)
+		}
+		return IsRFC(uint(rfcNum), sa), nil
+	}
+
+	if _, found := classNetworks()[strings.ToLower(rfcOrClass)]; !found {
+		return false, fmt.Errorf("unknown RFC number or address class %q", rfcOrClass)
+	}
+	return IsClass(rfcOrClass, sa), nil
+}
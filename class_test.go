@@ -0,0 +1,154 @@
+package sockaddr_test
+
+import (
+	"strings"
+	"testing"
+
+	sockaddr "github.com/hashicorp/go-sockaddr"
+)
+
+func TestClassesOf(t *testing.T) {
+	tests := []struct {
+		addr    string
+		classes string
+	}{
+		{addr: "8.8.8.8", classes: ""},
+		{addr: "10.1.2.3", classes: "private"},
+		{addr: "100.64.0.1", classes: "cgnat"},
+		{addr: "127.0.0.1", classes: "loopback"},
+		{addr: "169.254.1.1", classes: "link-local"},
+		{addr: "198.19.0.1", classes: "benchmarking"},
+		{addr: "203.0.113.7", classes: "documentation"},
+		{addr: "239.255.255.250", classes: "multicast"},
+		{addr: "::1", classes: "loopback"},
+		{addr: "2001:db8::1", classes: "documentation"},
+		{addr: "fd00::1", classes: "private|ula"},
+		{addr: "fe80::1", classes: "link-local"},
+		{addr: "ff02::1", classes: "multicast"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.addr, func(t *testing.T) {
+			ip := sockaddr.MustIPAddr(test.addr)
+			if classes := strings.Join(sockaddr.ClassesOf(ip), "|"); classes != test.classes {
+				t.Fatalf("want %q, got %q", test.classes, classes)
+			}
+			if class, _ := sockaddr.Attr(ip, "class"); class != test.classes {
+				t.Fatalf("want class attribute %q, got %q", test.classes, class)
+			}
+		})
+	}
+}
+
+func TestIsRFCOrClass(t *testing.T) {
+	tests := []struct {
+		selector string
+		addr     string
+		want     bool
+		fail     bool
+	}{
+		{selector: "1918", addr: "192.168.1.1", want: true},
+		{selector: "private", addr: "192.168.1.1", want: true},
+		{selector: "Documentation", addr: "192.0.2.1", want: true},
+		{selector: "cgnat", addr: "10.0.0.1", want: false},
+		{selector: "4294967294", addr: "10.0.0.1", fail: true},
+		{selector: "martian", addr: "10.0.0.1", fail: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.selector, func(t *testing.T) {
+			got, err := sockaddr.IsRFCOrClass(test.selector, sockaddr.MustIPAddr(test.addr))
+			if test.fail {
+				if err == nil {
+					t.Fatalf("expected failure")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unable to test %s: %v", test.selector, err)
+			}
+			if got != test.want {
+				t.Fatalf("want %t, got %t", test.want, got)
+			}
+		})
+	}
+}
+
+func TestIfByClass(t *testing.T) {
+	ifAddrs := sockaddr.IfAddrs{
+		{SockAddr: sockaddr.MustIPAddr("10.0.0.1")},
+		{SockAddr: sockaddr.MustIPAddr("100.64.1.1")},
+		{SockAddr: sockaddr.MustIPAddr("203.0.113.1")},
+		{SockAddr: sockaddr.MustIPAddr("fd00::1")},
+	}
+
+	included, err := sockaddr.IncludeIfs("class", "private|cgnat", ifAddrs)
+	if err != nil {
+		t.Fatalf("unable to include: %v", err)
+	}
+	if len(included) != 3 {
+		t.Fatalf("want 3 addresses, got %v", included)
+	}
+
+	excluded, err := sockaddr.ExcludeIfs("class", "private|cgnat", ifAddrs)
+	if err != nil {
+		t.Fatalf("unable to exclude: %v", err)
+	}
+	if len(excluded) != 1 || excluded[0].SockAddr.String() != "203.0.113.1" {
+		t.Fatalf("unexpected result: %v", excluded)
+	}
+
+	matched, _, err := sockaddr.IfByRFCs("5737|ula", ifAddrs)
+	if err != nil {
+		t.Fatalf("unable to filter by RFC and class: %v", err)
+	}
+	if len(matched) != 2 {
+		t.Fatalf("want 2 addresses, got %v", matched)
+	}
+
+	if _, err := sockaddr.IncludeIfs("class", "private|martian", ifAddrs); err == nil {
+		t.Fatalf("expected an unknown class to fail")
+	}
+}
+
+func TestIfByRFCList(t *testing.T) {
+	ifAddrs := sockaddr.IfAddrs{
+		{SockAddr: sockaddr.MustIPAddr("10.0.0.1")},
+		{SockAddr: sockaddr.MustIPAddr("100.64.1.1")},
+		{SockAddr: sockaddr.MustIPAddr("203.0.113.1")},
+		{SockAddr: sockaddr.MustIPAddr("fd00::1")},
+	}
+
+	tests := []struct {
+		selector string
+		want     int
+	}{
+		{selector: "1918|cgnat", want: 2},
+		{selector: "private|cgnat", want: 3},
+		{selector: "5737|ula|6598", want: 3},
+	}
+
+	for _, test := range tests {
+		t.Run(test.selector, func(t *testing.T) {
+			matched, remainder, err := sockaddr.IfByRFC(test.selector, ifAddrs)
+			if err != nil {
+				t.Fatalf("unable to filter: %v", err)
+			}
+			if len(matched) != test.want || len(matched)+len(remainder) != len(ifAddrs) {
+				t.Fatalf("want %d addresses, got %v (remainder %v)", test.want, matched, remainder)
+			}
+
+			included, err := sockaddr.IncludeIfs("rfc", test.selector, ifAddrs)
+			if err != nil {
+				t.Fatalf("unable to include: %v", err)
+			}
+			if len(included) != test.want {
+				t.Fatalf("want %d included addresses, got %v", test.want, included)
+			}
+		})
+	}
+
+	if _, _, err := sockaddr.IfByRFC("1918|martian", ifAddrs); err == nil {
+		t.Fatalf("expected an unknown class to fail")
+	}
+}
@@ -40,6 +40,13 @@ type DumpCommand struct {
 
 	// unixOnly parses the input exclusively as a UNIX Socket
 	unixOnly bool
+
+	// tagsFile is a file of network tags used by the "tag" attribute.
+	tagsFile string
+
+	// snapshotFile is a snapshot of a host (see `sockaddr snapshot`) used
+	// instead of the live host.
+	snapshotFile string
 }
 
 // Description is the long-form command help.
@@ -64,6 +71,8 @@ func (c *DumpCommand) InitOpts() {
 	c.flags.BoolVar(&c.ifOnly, "I", false, "Parse the argument as an interface name")
 	c.flags.BoolVar(&c.ipOnly, "i", false, "Parse the input as IP address (either IPv4 or IPv6)")
 	c.flags.BoolVar(&c.unixOnly, "u", false, "Parse the input as a UNIX Socket only")
+	c.flags.StringVar(&c.tagsFile, "tags", "", "File of network tags (CIDR and label per line)")
+	c.flags.StringVar(&c.snapshotFile, "snapshot", "", "Snapshot file written by \"sockaddr snapshot\" to use instead of the live host")
 	c.flags.Var((*MultiArg)(&c.attrNames), "o", "Name of an attribute to pass through")
 }
 
@@ -82,6 +91,18 @@ func (c *DumpCommand) Run(args []string) int {
 		}
 		return 1
 	}
+
+	if err := loadTagsFile(c.tagsFile); err != nil {
+		c.Ui.Error(fmt.Sprintf("Unable to load tags: %v", err))
+		return 1
+	}
+
+	system, err := loadSnapshotFile(c.snapshotFile)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Unable to load snapshot: %v", err))
+		return 1
+	}
+
 	for _, addr := range addrs {
 		var sa sockaddr.SockAddr
 		var ifAddrs sockaddr.IfAddrs
@@ -96,7 +117,7 @@ func (c *DumpCommand) Run(args []string) int {
 		case c.ipOnly:
 			sa, err = sockaddr.NewIPAddr(addr)
 		case c.ifOnly:
-			ifAddrs, err = sockaddr.GetAllInterfaces()
+			ifAddrs, err = system.GetAllInterfaces()
 			if err != nil {
 				break
 			}
@@ -141,6 +162,15 @@ func (c *DumpCommand) dumpIfAddrs(ifAddrs sockaddr.IfAddrs) {
 	}
 }
 
+// multicastAttrs are the attributes that are only dumped by default for
+// multicast addresses (true) or for unicast addresses (false).
+var multicastAttrs = map[sockaddr.AttrName]bool{
+	"multicast_flags": true,
+	"multicast_mac":   true,
+	"multicast_scope": true,
+	"solicited_node":  false,
+}
+
 func (c *DumpCommand) dumpSockAddr(sa sockaddr.SockAddr) {
 	reservedAttrs := []sockaddr.AttrName{"Attribute"}
 	const maxNumAttrs = 32
@@ -155,10 +185,13 @@ func (c *DumpCommand) dumpSockAddr(sa sockaddr.SockAddr) {
 	}
 
 	// allowedAttr returns true if the attribute is allowed to be appended
-	// to the output.
+	// to the output.  Unless they are requested, the multicast attributes
+	// are only appended for multicast addresses and the solicited-node
+	// group only for unicast addresses.
 	allowedAttr := func(k sockaddr.AttrName) bool {
 		if len(allowedAttrs) == len(reservedAttrs) {
-			return true
+			multicast, found := multicastAttrs[k]
+			return !found || multicast == sockaddr.IsMulticast(sa)
 		}
 
 		_, found := allowedAttrs[k]
@@ -272,3 +305,15 @@ func (c *DumpCommand) parseOpts(args []string) ([]string, error) {
 
 	return c.flags.Args(), nil
 }
+
+// loadTagsFile loads the network tags from path, if one was given.
+func loadTagsFile(path string) error {
+	if path == "" {
+		return nil
+	}
+
+	if err := sockaddr.LoadTagsFile(path); err != nil {
+		return fmt.Errorf("unable to load tags %+q: %v", path, err)
+	}
+	return nil
+}
@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/hashicorp/errwrap"
+	sockaddr "github.com/hashicorp/go-sockaddr"
 	"github.com/hashicorp/go-sockaddr/template"
 	"github.com/mitchellh/cli"
 )
@@ -29,6 +30,17 @@ type EvalCommand struct {
 	// suppressNewline changes whether or not there's a newline between each
 	// arg passed to the eval subcommand.
 	suppressNewline bool
+
+	// tagsFile is a file of network tags used by the "tag" selector and
+	// attribute.
+	tagsFile string
+
+	// bogonsFiles is a list of full bogons files used by the "bogon" flag.
+	bogonsFiles []string
+
+	// snapshotFile is a snapshot of a host (see `sockaddr snapshot`) used
+	// instead of the live host.
+	snapshotFile string
 }
 
 // Description is the long-form command help.
@@ -58,6 +70,9 @@ func (c *EvalCommand) InitOpts() {
 	c.flags.BoolVar(&c.debugOutput, "d", false, "Debug output")
 	c.flags.BoolVar(&c.suppressNewline, "n", false, "Suppress newlines between args")
 	c.flags.BoolVar(&c.rawInput, "r", false, "Suppress wrapping the input with {{ }} delimiters")
+	c.flags.StringVar(&c.tagsFile, "tags", "", "File of network tags (CIDR and label per line)")
+	c.flags.Var((*MultiArg)(&c.bogonsFiles), "bogons", "Full bogons file (CIDR per line) used by the \"bogon\" flag")
+	c.flags.StringVar(&c.snapshotFile, "snapshot", "", "Snapshot file written by \"sockaddr snapshot\" to use instead of the live host")
 }
 
 // Run executes this command.
@@ -75,6 +90,23 @@ func (c *EvalCommand) Run(args []string) int {
 		}
 		return 1
 	}
+
+	if err := loadTagsFile(c.tagsFile); err != nil {
+		c.Ui.Error(fmt.Sprintf("[ERROR]: %v", err))
+		return 1
+	}
+
+	if err := loadBogonsFiles(c.bogonsFiles); err != nil {
+		c.Ui.Error(fmt.Sprintf("[ERROR]: %v", err))
+		return 1
+	}
+
+	system, err := loadSnapshotFile(c.snapshotFile)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("[ERROR]: %v", err))
+		return 1
+	}
+
 	inputs, outputs := make([]string, len(tmpls)), make([]string, len(tmpls))
 	var rawInput, readStdin bool
 	for i, in := range tmpls {
@@ -104,7 +136,7 @@ func (c *EvalCommand) Run(args []string) int {
 			inputs[i] = in
 		}
 
-		out, err := template.Parse(in)
+		out, err := template.ParseWithSystem(in, system)
 		if err != nil {
 			c.Ui.Error(fmt.Sprintf("ERROR[%d] in: %q\n[%d] msg: %v\n", i, in, i, err))
 			return 1
@@ -156,3 +188,26 @@ func (c *EvalCommand) parseOpts(args []string) ([]string, error) {
 
 	return c.flags.Args(), nil
 }
+
+// loadBogonsFiles loads the full bogons list from the given files, if any.
+// The files are combined, e.g. to load both the IPv4 and IPv6 lists.
+func loadBogonsFiles(paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+
+	readers := make([]io.Reader, 0, len(paths))
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("unable to open bogons %+q: %v", path, err)
+		}
+		defer f.Close()
+		readers = append(readers, f, strings.NewReader("\n"))
+	}
+
+	if _, err := sockaddr.LoadBogons(io.MultiReader(readers...)); err != nil {
+		return fmt.Errorf("unable to load bogons: %v", err)
+	}
+	return nil
+}
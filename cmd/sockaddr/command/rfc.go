@@ -20,11 +20,15 @@ type RFCCommand struct {
 	// IP address is NOT a member of the known RFC.  Unknown RFCs return a
 	// status code of 2.
 	silentMode bool
+
+	// registryFiles is a list of IANA special-purpose address registries to
+	// load into the RFC registry.
+	registryFiles []string
 }
 
 // Description is the long-form command help.
 func (c *RFCCommand) Description() string {
-	return `Tests a given IP address to see if it is part of a known RFC.  If the IP address belongs to a known RFC, return exit code 0 and print the status.  If the IP does not belong to an RFC, return 1.  If the RFC is not known, return 2.`
+	return `Tests a given IP address to see if it is part of a known RFC or address class (e.g. "private" or "documentation").  If the IP address belongs to a known RFC, return exit code 0 and print the status.  If the IP does not belong to an RFC, return 1.  If the RFC is not known, return 2.`
 }
 
 // Help returns the full help output expected by `sockaddr -h cmd`
@@ -38,6 +42,7 @@ func (c *RFCCommand) InitOpts() {
 	c.flags = flag.NewFlagSet("rfc", flag.ContinueOnError)
 	c.flags.Usage = func() { c.Ui.Output(c.Help()) }
 	c.flags.BoolVar(&c.silentMode, "s", false, "Silent, only return different exit codes")
+	c.flags.Var((*MultiArg)(&c.registryFiles), "registry", "IANA special-purpose address registry (CSV or XML) to load")
 }
 
 // Run executes this command.
@@ -65,13 +70,6 @@ func (c *RFCCommand) Run(args []string) int {
 		return 1
 	}
 
-	// Parse the RFC Number
-	rfcNum, err := strconv.ParseUint(unprocessedArgs[0], 10, 32)
-	if err != nil {
-		c.Ui.Error(fmt.Sprintf("ERROR: Invalid RFC Number %+q: %v", unprocessedArgs[0], err))
-		return 2
-	}
-
 	// Parse the IP address
 	ipAddr, err := sockaddr.NewIPAddr(unprocessedArgs[1])
 	if err != nil {
@@ -79,14 +77,34 @@ func (c *RFCCommand) Run(args []string) int {
 		return 3
 	}
 
-	switch inRFC := sockaddr.IsRFC(uint(rfcNum), ipAddr); {
+	if err := loadRegistryFiles(c.registryFiles); err != nil {
+		c.Ui.Error(fmt.Sprintf("ERROR: %v", err))
+		return 4
+	}
+
+	// The RFC Number may also be the name of an address class
+	rfcOrClass := unprocessedArgs[0]
+	name := rfcOrClass
+	if _, err := strconv.ParseUint(rfcOrClass, 10, 32); err == nil {
+		name = "RFC " + rfcOrClass
+	}
+
+	inRFC, err := sockaddr.IsRFCOrClass(rfcOrClass, ipAddr)
+	if err != nil {
+		if !c.silentMode {
+			c.Ui.Error(fmt.Sprintf("ERROR: %v", err))
+		}
+		return 2
+	}
+
+	switch {
 	case inRFC && !c.silentMode:
-		c.Ui.Output(fmt.Sprintf("%s is part of RFC %d", ipAddr, rfcNum))
+		c.Ui.Output(fmt.Sprintf("%s is part of %s", ipAddr, name))
 		fallthrough
 	case inRFC:
 		return 0
 	case !inRFC && !c.silentMode:
-		c.Ui.Output(fmt.Sprintf("%s is not part of RFC %d", ipAddr, rfcNum))
+		c.Ui.Output(fmt.Sprintf("%s is not part of %s", ipAddr, name))
 		fallthrough
 	case !inRFC:
 		return 1
@@ -102,7 +120,7 @@ func (c *RFCCommand) Synopsis() string {
 
 // Usage is the one-line usage description
 func (c *RFCCommand) Usage() string {
-	return `sockaddr rfc [RFC Number] [IP Address]`
+	return `sockaddr rfc [RFC Number|Class] [IP Address]`
 }
 
 // VisitAllFlags forwards the visitor function to the FlagSet
@@ -110,6 +128,17 @@ func (c *RFCCommand) VisitAllFlags(fn func(*flag.Flag)) {
 	c.flags.VisitAll(fn)
 }
 
+// loadRegistryFiles loads each IANA special-purpose address registry into the
+// RFC registry.
+func loadRegistryFiles(paths []string) error {
+	for _, path := range paths {
+		if _, err := sockaddr.LoadIANARegistryFile(path); err != nil {
+			return fmt.Errorf("unable to load registry %+q: %v", path, err)
+		}
+	}
+	return nil
+}
+
 // parseOpts is responsible for parsing the options set in InitOpts().  Returns
 // a list of non-parsed flags.
 func (c *RFCCommand) parseOpts(args []string) ([]string, error) {
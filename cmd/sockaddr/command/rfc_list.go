@@ -4,10 +4,12 @@ import (
 	"flag"
 	"fmt"
 	"sort"
+	"strconv"
 
 	"github.com/hashicorp/errwrap"
 	sockaddr "github.com/hashicorp/go-sockaddr"
 	"github.com/mitchellh/cli"
+	"github.com/ryanuber/columnize"
 )
 
 type RFCListCommand struct {
@@ -15,6 +17,13 @@ type RFCListCommand struct {
 
 	// flags is a list of options belonging to this command
 	flags *flag.FlagSet
+
+	// registryFiles is a list of IANA special-purpose address registries to
+	// load into the RFC registry.
+	registryFiles []string
+
+	// verboseMode lists the title and blocks of each RFC
+	verboseMode bool
 }
 
 // Description is the long-form command help.
@@ -32,6 +41,8 @@ func (c *RFCListCommand) Help() string {
 func (c *RFCListCommand) InitOpts() {
 	c.flags = flag.NewFlagSet("list", flag.ContinueOnError)
 	c.flags.Usage = func() { c.Ui.Output(c.Help()) }
+	c.flags.Var((*MultiArg)(&c.registryFiles), "registry", "IANA special-purpose address registry (CSV or XML) to load")
+	c.flags.BoolVar(&c.verboseMode, "v", false, "Show the title, blocks, and RFC 6890 properties of each RFC")
 }
 
 type rfcNums []uint
@@ -42,13 +53,8 @@ func (s rfcNums) Less(i, j int) bool { return s[i] < s[j] }
 
 // Run executes this command.
 func (c *RFCListCommand) Run(args []string) int {
-	if len(args) != 0 {
-		c.Ui.Error(c.Help())
-		return 1
-	}
-
 	c.InitOpts()
-	_, err := c.parseOpts(args)
+	unprocessedArgs, err := c.parseOpts(args)
 	if err != nil {
 		if errwrap.Contains(err, "flag: help requested") {
 			return 0
@@ -56,6 +62,16 @@ func (c *RFCListCommand) Run(args []string) int {
 		return 1
 	}
 
+	if len(unprocessedArgs) != 0 {
+		c.Ui.Error(c.Help())
+		return 1
+	}
+
+	if err := loadRegistryFiles(c.registryFiles); err != nil {
+		c.Ui.Error(fmt.Sprintf("ERROR: %v", err))
+		return 1
+	}
+
 	var rfcs rfcNums
 	sockaddr.VisitAllRFCs(func(rfcNum uint, sas sockaddr.SockAddrs) {
 		rfcs = append(rfcs, rfcNum)
@@ -64,12 +80,53 @@ func (c *RFCListCommand) Run(args []string) int {
 	sort.Sort(rfcs)
 
 	for _, rfcNum := range rfcs {
+		if c.verboseMode {
+			c.outputRFC(rfcNum)
+			continue
+		}
+
 		c.Ui.Output(fmt.Sprintf("%d", rfcNum))
 	}
 
 	return 0
 }
 
+// outputRFC prints an RFC's title followed by a table of its blocks and their
+// RFC 6890 properties.  Unknown properties are printed as "-".
+func (c *RFCListCommand) outputRFC(rfcNum uint) {
+	entry, found := sockaddr.LookupRFC(rfcNum)
+	if !found {
+		return
+	}
+
+	c.Ui.Output(fmt.Sprintf("%d\t%s", entry.Number, entry.Title))
+
+	output := []string{"Block | Name | Source | Destination | Forwardable | Global | Reserved"}
+	for _, block := range entry.Blocks {
+		props := []string{"-", "-", "-", "-", "-"}
+		if sp := block.SpecialPurpose; sp != nil {
+			props = []string{
+				strconv.FormatBool(sp.Source),
+				strconv.FormatBool(sp.Destination),
+				strconv.FormatBool(sp.Forwardable),
+				strconv.FormatBool(sp.Global),
+				strconv.FormatBool(sp.ReservedByProtocol),
+			}
+		}
+
+		name := block.Name
+		if name == "" {
+			name = "-"
+		}
+
+		output = append(output, fmt.Sprintf("%s | %s | %s | %s | %s | %s | %s",
+			block.Network, name, props[0], props[1], props[2], props[3], props[4]))
+	}
+
+	c.Ui.Output(columnize.SimpleFormat(output))
+	c.Ui.Output("")
+}
+
 // Synopsis returns a terse description used when listing sub-commands.
 func (c *RFCListCommand) Synopsis() string {
 	return `Lists all known RFCs`
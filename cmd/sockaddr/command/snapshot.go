@@ -0,0 +1,147 @@
+package command
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/errwrap"
+	sockaddr "github.com/hashicorp/go-sockaddr"
+	"github.com/mitchellh/cli"
+)
+
+type SnapshotCommand struct {
+	Ui cli.Ui
+
+	// flags is a list of options belonging to this command
+	flags *flag.FlagSet
+
+	// outputFile is the file the snapshot is written to.  The snapshot is
+	// written to stdout if no file is given.
+	outputFile string
+}
+
+// Description is the long-form command help.
+func (c *SnapshotCommand) Description() string {
+	return `Writes a snapshot of the interfaces, addresses, flags and routes of this host.
+
+` + "The snapshot is a versioned JSON file that can be passed to the `-snapshot` " +
+		"flag of `sockaddr eval` and `sockaddr dump` in order to evaluate templates " +
+		"against the captured host instead of the live host."
+}
+
+// Help returns the full help output expected by `sockaddr -h cmd`
+func (c *SnapshotCommand) Help() string {
+	return MakeHelp(c)
+}
+
+// InitOpts is responsible for setup of this command's configuration via the
+// command line.  InitOpts() does not parse the arguments (see parseOpts()).
+func (c *SnapshotCommand) InitOpts() {
+	c.flags = flag.NewFlagSet("snapshot", flag.ContinueOnError)
+	c.flags.Usage = func() { c.Ui.Output(c.Help()) }
+	c.flags.StringVar(&c.outputFile, "o", "", "File to write the snapshot to (default stdout)")
+}
+
+// Run executes this command.
+func (c *SnapshotCommand) Run(args []string) int {
+	c.InitOpts()
+	unprocessedArgs, err := c.parseOpts(args)
+	if err != nil {
+		if errwrap.Contains(err, "flag: help requested") {
+			return 0
+		}
+		return 1
+	}
+
+	if len(unprocessedArgs) != 0 {
+		c.Ui.Error(c.Help())
+		return 1
+	}
+
+	if c.outputFile == "" {
+		if err := sockaddr.WriteSnapshot(os.Stdout); err != nil {
+			c.Ui.Error(fmt.Sprintf("ERROR: %v", err))
+			return 1
+		}
+		return 0
+	}
+
+	if err := writeSnapshotFile(c.outputFile); err != nil {
+		c.Ui.Error(fmt.Sprintf("ERROR: %v", err))
+		return 1
+	}
+
+	return 0
+}
+
+// writeSnapshotFile writes a snapshot to a temporary file next to path and
+// renames it to path once it is complete, so that a failed snapshot neither
+// leaves a truncated file behind nor replaces an earlier snapshot.
+func writeSnapshotFile(path string) error {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("unable to create %+q: %v", path, err)
+	}
+	tmpPath := f.Name()
+
+	err = f.Chmod(0644)
+	if err == nil {
+		err = sockaddr.WriteSnapshot(f)
+	}
+	if closeErr := f.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("unable to write %+q: %v", path, closeErr)
+	}
+	if err == nil {
+		if renameErr := os.Rename(tmpPath, path); renameErr != nil {
+			err = fmt.Errorf("unable to write %+q: %v", path, renameErr)
+		}
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	return nil
+}
+
+// Synopsis returns a terse description used when listing sub-commands.
+func (c *SnapshotCommand) Synopsis() string {
+	return `Writes a snapshot of this host's interfaces and routes`
+}
+
+// Usage is the one-line usage description
+func (c *SnapshotCommand) Usage() string {
+	return `sockaddr snapshot [options]`
+}
+
+// VisitAllFlags forwards the visitor function to the FlagSet
+func (c *SnapshotCommand) VisitAllFlags(fn func(*flag.Flag)) {
+	c.flags.VisitAll(fn)
+}
+
+// parseOpts is responsible for parsing the options set in InitOpts().  Returns
+// a list of non-parsed flags.
+func (c *SnapshotCommand) parseOpts(args []string) ([]string, error) {
+	if err := c.flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	return c.flags.Args(), nil
+}
+
+// loadSnapshotFile returns a System that inspects the host captured in the
+// snapshot at path, or the live host if no snapshot was given.
+func loadSnapshotFile(path string) (*sockaddr.System, error) {
+	if path == "" {
+		return sockaddr.NewSystem(context.Background(), nil), nil
+	}
+
+	snapshot, err := sockaddr.LoadFakeSystemFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load snapshot %+q: %v", path, err)
+	}
+	return sockaddr.NewSystem(context.Background(), snapshot), nil
+}
@@ -34,6 +34,11 @@ func init() {
 				Ui: ui,
 			}, nil
 		},
+		"snapshot": func() (cli.Command, error) {
+			return &command.SnapshotCommand{
+				Ui: ui,
+			}, nil
+		},
 		"tech-support": func() (cli.Command, error) {
 			return &command.TechSupportCommand{
 				Ui: ui,
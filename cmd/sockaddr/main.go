@@ -17,10 +17,10 @@ func realMain() int {
 	log.SetOutput(ioutil.Discard)
 
 	// Get the command line args. We shortcut "--version" and "-v" to just
-	// show the version.
+	// show the version unless they follow a subcommand (e.g. "rfc list -v").
 	args := os.Args[1:]
 	for _, arg := range args {
-		if arg == "--" {
+		if arg == "--" || (arg != "" && arg[0] != '-') {
 			break
 		}
 		if arg == "-v" || arg == "--version" {
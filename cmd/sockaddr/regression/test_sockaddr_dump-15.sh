@@ -0,0 +1,5 @@
+#!/bin/sh --
+
+set -e
+exec 2>&1
+exec ../sockaddr dump -snapshot ../../../testdata/fake_system.json -I -n -o address eth1 eth0
@@ -0,0 +1,6 @@
+#!/bin/sh --
+
+set -e
+exec 2>&1
+../sockaddr dump 239.1.2.3
+../sockaddr dump ff02::1:ff00:7
@@ -0,0 +1,5 @@
+#!/bin/sh --
+
+set -e
+exec 2>&1
+exec ../sockaddr eval -snapshot ../../../testdata/fake_system.json 'GetDefaultInterfaces | attr "name"' 'GetAllInterfaces | include "type" "IPv4" | join "address" " "' 'GetInterfaceForDestination "10.20.1.1" | attr "gateway"'
@@ -0,0 +1,5 @@
+#!/bin/sh --
+
+set -e
+exec 2>&1
+exec ../sockaddr eval -snapshot ../../../testdata/fake_system.json 'GetNeighbors | include "name" "eth0" | join "address" " "' 'GetNeighbors | include "neighbor_state" "reachable|permanent" | include "type" "IPv4" | join "lladdr" " "'
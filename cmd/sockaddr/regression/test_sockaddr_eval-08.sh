@@ -0,0 +1,5 @@
+#!/bin/sh --
+
+set -e
+exec 2>&1
+exec ../sockaddr eval -snapshot ../../../testdata/fake_system.json 'GetNameservers | join "address" " "' 'GetNameservers | exclude "rfc" "1122" | include "name" "eth1" | join "address" " "'
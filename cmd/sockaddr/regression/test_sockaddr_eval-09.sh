@@ -0,0 +1,5 @@
+#!/bin/sh --
+
+set -e
+exec 2>&1
+exec ../sockaddr eval -snapshot ../../../testdata/fake_system_addr_states.json 'GetPrivateInterfaces | join "address" " "' 'GetPublicInterfaces | join "address" " "' 'GetAllInterfaces | include "flags" "temporary|deprecated" | join "address" " "'
@@ -0,0 +1,712 @@
+package sockaddr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FakeSystem is a SystemProvider with a fixed topology, built in code with
+// AddInterface and AddRoute or loaded from a JSON fixture with LoadFakeSystem.
+// Install it with SetSystemProvider to evaluate templates without depending on
+// the interfaces of the host.
+type FakeSystem struct {
+	lock          sync.RWMutex
+	ifs           []net.Interface
+	addrs         map[string][]IPAddr
+	addrStateMap  map[fakeAddrKey]addrState
+	links         map[string]LinkInfo
+	neighborTable []neighborEntry
+	resolver      ResolverConfig
+	routes        []Route
+	defaultIfName string
+}
+
+// NewFakeSystem returns a FakeSystem without any interfaces or routes.
+func NewFakeSystem() *FakeSystem {
+	return &FakeSystem{
+		addrs:        make(map[string][]IPAddr),
+		addrStateMap: make(map[fakeAddrKey]addrState),
+		links:        make(map[string]LinkInfo),
+	}
+}
+
+// fakeAddrKey identifies an address of a FakeSystem by the name of its
+// interface and the string form of its host address.
+type fakeAddrKey struct {
+	ifName  string
+	address string
+}
+
+// AddInterface adds an interface with the given addresses.  The interface
+// index is assigned from the interface's position if it is not set.
+func (f *FakeSystem) AddInterface(ifi net.Interface, addrs ...IPAddr) *FakeSystem {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if ifi.Index == 0 {
+		ifi.Index = len(f.ifs) + 1
+	}
+	f.ifs = append(f.ifs, ifi)
+	f.addrs[ifi.Name] = append(f.addrs[ifi.Name], addrs...)
+	return f
+}
+
+// SetAddrState sets the AddrFlags and the preferred and valid lifetimes of ip
+// on the named interface, e.g. to mark it as a temporary or deprecated
+// address.
+func (f *FakeSystem) SetAddrState(ifName string, ip IPAddr, flags AddrFlags, preferredLifetime, validLifetime time.Duration) *FakeSystem {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.addrStateMap[fakeAddrKey{ifName: ifName, address: ip.NetIP().String()}] = addrState{
+		flags:             flags,
+		preferredLifetime: preferredLifetime,
+		validLifetime:     validLifetime,
+	}
+	return f
+}
+
+// SetLinkInfo sets the kind of the named interface and its relation to other
+// interfaces.
+func (f *FakeSystem) SetLinkInfo(ifName string, link LinkInfo) *FakeSystem {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.links[ifName] = link
+	return f
+}
+
+// AddNeighbor adds an entry for ip to the neighbor table of the named
+// interface.
+func (f *FakeSystem) AddNeighbor(ifName string, ip IPAddr, neighbor NeighborInfo) *FakeSystem {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.neighborTable = append(f.neighborTable, neighborEntry{ifName: ifName, ip: ip, info: neighbor})
+	return f
+}
+
+// SetResolverConfig sets the DNS resolver configuration of the fake topology.
+func (f *FakeSystem) SetResolverConfig(config ResolverConfig) *FakeSystem {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.resolver = config
+	return f
+}
+
+// AddRoute adds a route.  The default interface is the interface of the IPv4
+// default route with the lowest metric, or of the IPv6 default route with the
+// lowest metric if there is no IPv4 default route (see SetDefaultInterface).
+func (f *FakeSystem) AddRoute(route Route) *FakeSystem {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.routes = append(f.routes, route)
+	return f
+}
+
+// SetDefaultInterface overrides the name of the default interface that would
+// otherwise be derived from the default routes.
+func (f *FakeSystem) SetDefaultInterface(ifName string) *FakeSystem {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.defaultIfName = ifName
+	return f
+}
+
+// Interfaces returns the interfaces of the fake topology.
+func (f *FakeSystem) Interfaces() ([]net.Interface, error) {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	return append([]net.Interface(nil), f.ifs...), nil
+}
+
+// Addrs returns the addresses of an interface of the fake topology.
+func (f *FakeSystem) Addrs(ifi net.Interface) ([]net.Addr, error) {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
+	addrs := make([]net.Addr, 0, len(f.addrs[ifi.Name]))
+	for _, ip := range f.addrs[ifi.Name] {
+		addrs = append(addrs, &net.IPNet{IP: *ip.NetIP(), Mask: *ip.NetIPMask()})
+	}
+	return addrs, nil
+}
+
+// addrStates returns the state of the addresses of the fake topology that have
+// one set.
+func (f *FakeSystem) addrStates() (map[addrStateKey]addrState, error) {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
+	states := make(map[addrStateKey]addrState, len(f.addrStateMap))
+	for _, ifi := range f.ifs {
+		for key, state := range f.addrStateMap {
+			if key.ifName == ifi.Name {
+				states[addrStateKey{index: ifi.Index, address: key.address}] = state
+			}
+		}
+	}
+	return states, nil
+}
+
+// linkInfos returns the LinkInfo of the interfaces of the fake topology that
+// have one set.
+func (f *FakeSystem) linkInfos() (map[int]LinkInfo, error) {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
+	links := make(map[int]LinkInfo, len(f.links))
+	for _, ifi := range f.ifs {
+		if link, found := f.links[ifi.Name]; found {
+			links[ifi.Index] = link
+		}
+	}
+	return links, nil
+}
+
+// neighbors returns the neighbor table of the fake topology.
+func (f *FakeSystem) neighbors() ([]neighborEntry, error) {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	return append([]neighborEntry(nil), f.neighborTable...), nil
+}
+
+// resolverConfig returns the DNS resolver configuration of the fake topology.
+func (f *FakeSystem) resolverConfig() (ResolverConfig, error) {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
+	config := ResolverConfig{
+		Nameservers: append(SockAddrs(nil), f.resolver.Nameservers...),
+		Search:      append([]string(nil), f.resolver.Search...),
+		Options:     append([]string(nil), f.resolver.Options...),
+	}
+	if len(f.resolver.LinkNameservers) > 0 {
+		config.LinkNameservers = make(map[string]SockAddrs, len(f.resolver.LinkNameservers))
+		for ifName, nameservers := range f.resolver.LinkNameservers {
+			config.LinkNameservers[ifName] = append(SockAddrs(nil), nameservers...)
+		}
+	}
+	return config, nil
+}
+
+// RouteInfo returns the routes of the fake topology.
+func (f *FakeSystem) RouteInfo(ctx context.Context) (RouteInterface, error) {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
+	return fakeRouteInfo{
+		routes:        append([]Route(nil), f.routes...),
+		defaultIfName: f.defaultIfName,
+	}, nil
+}
+
+// fakeRouteInfo is the RouteInterface of a FakeSystem.
+type fakeRouteInfo struct {
+	routes        []Route
+	defaultIfName string
+}
+
+// GetDefaultInterfaceName returns the default interface of the fake topology.
+func (ri fakeRouteInfo) GetDefaultInterfaceName() (string, error) {
+	if ri.defaultIfName != "" {
+		return ri.defaultIfName, nil
+	}
+
+	if ifNames := defaultIfNames(ri.routes); len(ifNames) > 0 {
+		return ifNames[0], nil
+	}
+	return "", errors.New("No default interface found")
+}
+
+// GetRoutes returns the routes of the fake topology.
+func (ri fakeRouteInfo) GetRoutes() ([]Route, error) {
+	return append([]Route(nil), ri.routes...), nil
+}
+
+// SnapshotVersion is the version of the JSON format written by WriteSnapshot.
+// LoadFakeSystem reads snapshots of this and earlier versions, and fixtures
+// without a version.
+const SnapshotVersion = 3
+
+// fakeSystemJSON is the JSON format of snapshots and fake system fixtures.
+type fakeSystemJSON struct {
+	Version          int                 `json:"version,omitempty"`
+	CapturedAt       string              `json:"captured_at,omitempty"`
+	Platform         string              `json:"platform,omitempty"`
+	Interfaces       []fakeInterfaceJSON `json:"interfaces"`
+	Routes           []fakeRouteJSON     `json:"routes"`
+	DefaultInterface string              `json:"default_interface,omitempty"`
+	Resolver         *fakeResolverJSON   `json:"resolver,omitempty"`
+}
+
+// fakeResolverJSON is the DNS resolver configuration of /etc/resolv.conf.
+type fakeResolverJSON struct {
+	Nameservers []string `json:"nameservers"`
+	Search      []string `json:"search,omitempty"`
+	Options     []string `json:"options,omitempty"`
+}
+
+// fakeInterfaceJSON is an interface and its addresses.
+type fakeInterfaceJSON struct {
+	Index        int      `json:"index"`
+	Name         string   `json:"name"`
+	MTU          int      `json:"mtu"`
+	HardwareAddr string   `json:"hardware_addr,omitempty"`
+	Flags        string   `json:"flags"`
+	Kind         string   `json:"kind,omitempty"`
+	Master       string   `json:"master,omitempty"`
+	VLANID       int      `json:"vlan_id,omitempty"`
+	OperState    string   `json:"operstate,omitempty"`
+	Carrier      bool     `json:"carrier,omitempty"`
+	SpeedMbps    int      `json:"speed_mbps,omitempty"`
+	Duplex       string   `json:"duplex,omitempty"`
+	RxBytes      uint64   `json:"rx_bytes,omitempty"`
+	TxBytes      uint64   `json:"tx_bytes,omitempty"`
+	RxErrors     uint64   `json:"rx_errors,omitempty"`
+	TxErrors     uint64   `json:"tx_errors,omitempty"`
+	Addresses    []string `json:"addresses"`
+
+	// AddressesError is the reason the addresses of the interface could not
+	// be read when the snapshot was taken.
+	AddressesError string `json:"addresses_error,omitempty"`
+
+	AddressStates []fakeAddrStateJSON `json:"address_states,omitempty"`
+	Neighbors     []fakeNeighborJSON  `json:"neighbors,omitempty"`
+	Nameservers   []string            `json:"nameservers,omitempty"`
+}
+
+// fakeAddrStateJSON is the AddrFlags and lifetimes of an address.  Lifetimes
+// are in seconds or "forever".
+type fakeAddrStateJSON struct {
+	Address           string `json:"address"`
+	Flags             string `json:"flags"`
+	PreferredLifetime string `json:"preferred_lifetime,omitempty"`
+	ValidLifetime     string `json:"valid_lifetime,omitempty"`
+}
+
+// fakeNeighborJSON is an entry of the neighbor table of an interface.
+type fakeNeighborJSON struct {
+	Address string `json:"address"`
+	LLAddr  string `json:"lladdr,omitempty"`
+	State   string `json:"state"`
+}
+
+// fakeRouteJSON is a route.  Unset addresses are empty strings.
+type fakeRouteJSON struct {
+	Destination string `json:"destination"`
+	Gateway     string `json:"gateway,omitempty"`
+	Source      string `json:"source,omitempty"`
+	Metric      uint32 `json:"metric"`
+	Interface   string `json:"interface"`
+	Table       string `json:"table,omitempty"`
+	Protocol    string `json:"protocol,omitempty"`
+}
+
+// WriteSnapshot writes the interfaces, addresses, flags, address flags and
+// lifetimes, link information, neighbors, routes and resolver configuration
+// of the current SystemProvider to w as JSON.  The snapshot is read back with
+// LoadFakeSystem, so that templates can be evaluated exactly as they would be
+// on the captured host.  Routes are omitted on platforms that can not list
+// them, and the default interface is recorded if it can be determined.  An
+// interface whose addresses can not be read is recorded without addresses and
+// with the reason in "addresses_error", and addresses that are not IP
+// addresses are left out.
+func WriteSnapshot(w io.Writer) error {
+	provider := CurrentSystemProvider()
+
+	ifs, err := provider.Interfaces()
+	if err != nil {
+		return fmt.Errorf("unable to list interfaces: %v", err)
+	}
+
+	snapshot := fakeSystemJSON{
+		Version:    SnapshotVersion,
+		CapturedAt: time.Now().UTC().Format(time.RFC3339),
+		Platform:   runtime.GOOS,
+		Interfaces: make([]fakeInterfaceJSON, 0, len(ifs)),
+		Routes:     []fakeRouteJSON{},
+	}
+
+	var states map[addrStateKey]addrState
+	if reader, ok := provider.(addrStateReader); ok {
+		states, _ = reader.addrStates()
+	}
+
+	var links map[int]LinkInfo
+	if reader, ok := provider.(linkInfoReader); ok {
+		links, _ = reader.linkInfos()
+	}
+
+	var neighbors []neighborEntry
+	if reader, ok := provider.(neighborReader); ok {
+		neighbors, _ = reader.neighbors()
+	}
+
+	var resolver ResolverConfig
+	if reader, ok := provider.(resolverConfigReader); ok {
+		if config, err := reader.resolverConfig(); err == nil {
+			resolver = config
+			snapshot.Resolver = &fakeResolverJSON{
+				Nameservers: make([]string, 0, len(config.Nameservers)),
+				Search:      config.Search,
+				Options:     config.Options,
+			}
+			for _, nameserver := range config.Nameservers {
+				snapshot.Resolver.Nameservers = append(snapshot.Resolver.Nameservers, nameserver.String())
+			}
+		}
+	}
+
+	for _, ifi := range ifs {
+		addrs, addrsErr := provider.Addrs(ifi)
+
+		link := links[ifi.Index]
+		snapshotIf := fakeInterfaceJSON{
+			Index:        ifi.Index,
+			Name:         ifi.Name,
+			MTU:          ifi.MTU,
+			HardwareAddr: ifi.HardwareAddr.String(),
+			Flags:        ifi.Flags.String(),
+			Kind:         link.Kind,
+			Master:       link.Master,
+			VLANID:       link.VLANID,
+			OperState:    link.OperState,
+			Carrier:      link.Carrier,
+			SpeedMbps:    link.SpeedMbps,
+			Duplex:       link.Duplex,
+			RxBytes:      link.Stats.RxBytes,
+			TxBytes:      link.Stats.TxBytes,
+			RxErrors:     link.Stats.RxErrors,
+			TxErrors:     link.Stats.TxErrors,
+			Addresses:    make([]string, 0, len(addrs)),
+		}
+		if addrsErr != nil {
+			snapshotIf.AddressesError = addrsErr.Error()
+		}
+		for _, addr := range addrs {
+			ip, err := NewIPAddr(addr.String())
+			if err != nil {
+				continue
+			}
+			snapshotIf.Addresses = append(snapshotIf.Addresses, addr.String())
+
+			state, found := states[addrStateKey{index: ifi.Index, address: ip.NetIP().String()}]
+			if !found || state.flags == 0 {
+				continue
+			}
+			snapshotIf.AddressStates = append(snapshotIf.AddressStates, fakeAddrStateJSON{
+				Address:           ip.NetIP().String(),
+				Flags:             state.flags.String(),
+				PreferredLifetime: lifetimeString(state.flags, state.preferredLifetime),
+				ValidLifetime:     lifetimeString(state.flags, state.validLifetime),
+			})
+		}
+		for _, entry := range neighbors {
+			if entry.index != ifi.Index && (entry.index != 0 || entry.ifName != ifi.Name) {
+				continue
+			}
+			snapshotIf.Neighbors = append(snapshotIf.Neighbors, fakeNeighborJSON{
+				Address: entry.ip.String(),
+				LLAddr:  entry.info.HardwareAddr.String(),
+				State:   entry.info.State,
+			})
+		}
+		for _, nameserver := range resolver.LinkNameservers[ifi.Name] {
+			snapshotIf.Nameservers = append(snapshotIf.Nameservers, nameserver.String())
+		}
+		snapshot.Interfaces = append(snapshot.Interfaces, snapshotIf)
+	}
+
+	ri, err := provider.RouteInfo(context.Background())
+	if err != nil {
+		return fmt.Errorf("unable to read routes: %v", err)
+	}
+
+	if routes, err := ri.GetRoutes(); err == nil {
+		addr := func(ip IPAddr) string {
+			if ip == nil {
+				return ""
+			}
+			return ip.String()
+		}
+		for _, route := range routes {
+			snapshot.Routes = append(snapshot.Routes, fakeRouteJSON{
+				Destination: addr(route.Destination),
+				Gateway:     addr(route.Gateway),
+				Source:      addr(route.Source),
+				Metric:      route.Metric,
+				Interface:   route.IfName,
+				Table:       route.Table,
+				Protocol:    route.Protocol,
+			})
+		}
+	}
+
+	if defaultIfName, err := ri.GetDefaultInterfaceName(); err == nil {
+		snapshot.DefaultInterface = defaultIfName
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(snapshot); err != nil {
+		return fmt.Errorf("unable to write snapshot: %v", err)
+	}
+	return nil
+}
+
+// LoadFakeSystemFile reads a FakeSystem from the JSON fixture at path (see
+// LoadFakeSystem).
+func LoadFakeSystemFile(path string) (*FakeSystem, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open fake system fixture: %v", err)
+	}
+	defer f.Close()
+
+	return LoadFakeSystem(f)
+}
+
+// LoadFakeSystem reads a FakeSystem from a JSON fixture or a snapshot written
+// by WriteSnapshot.  Interface flags are joined by "|" as printed by
+// net.Flags, addresses and route networks are in CIDR notation, and the
+// link information of interfaces (e.g. "kind", "operstate" and "speed_mbps",
+// see LinkInfo), the AddrFlags and lifetimes of their addresses in
+// "address_states", their "neighbors" and "nameservers", "addresses_error",
+// "default_interface" and the "resolver" configuration are optional.  Address flags are joined by
+// "|" as printed by AddrFlags, and lifetimes are in seconds or "forever", the
+// default.  For example:
+//
+//	{
+//	  "interfaces": [
+//	    {"name": "lo", "flags": "up|loopback", "addresses": ["127.0.0.1/8", "::1"]},
+//	    {"name": "eth0", "flags": "up|broadcast|multicast", "hardware_addr": "02:42:ac:11:00:02",
+//	     "addresses": ["10.0.0.5/24", "fe80::42:acff:fe11:2/64"],
+//	     "address_states": [{"address": "fe80::42:acff:fe11:2", "flags": "permanent"}],
+//	     "neighbors": [{"address": "10.0.0.1", "lladdr": "02:42:ac:11:00:01", "state": "reachable"}]}
+//	  ],
+//	  "routes": [
+//	    {"destination": "0.0.0.0/0", "gateway": "10.0.0.1", "interface": "eth0", "metric": 100},
+//	    {"destination": "10.0.0.0/24", "source": "10.0.0.5", "interface": "eth0", "protocol": "kernel"}
+//	  ],
+//	  "resolver": {"nameservers": ["127.0.0.53"], "search": ["example.com"], "options": ["edns0"]}
+//	}
+func LoadFakeSystem(r io.Reader) (*FakeSystem, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read fake system fixture: %v", err)
+	}
+
+	// The version is checked before the fields, so that a snapshot of a newer
+	// version is reported as such rather than as a field this version does not
+	// know.
+	var header struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, fmt.Errorf("unable to decode fake system fixture: %v", err)
+	}
+	if header.Version > SnapshotVersion {
+		return nil, fmt.Errorf("unsupported snapshot version %d (latest supported version is %d)", header.Version, SnapshotVersion)
+	}
+
+	var fixture fakeSystemJSON
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&fixture); err != nil {
+		return nil, fmt.Errorf("unable to decode fake system fixture: %v", err)
+	}
+
+	fake := NewFakeSystem()
+	var resolver ResolverConfig
+	for _, fixtureIf := range fixture.Interfaces {
+		ifi := net.Interface{
+			Index: fixtureIf.Index,
+			MTU:   fixtureIf.MTU,
+			Name:  fixtureIf.Name,
+		}
+
+		if fixtureIf.HardwareAddr != "" {
+			hwAddr, err := net.ParseMAC(fixtureIf.HardwareAddr)
+			if err != nil {
+				return nil, fmt.Errorf("interface %s: invalid hardware address %q: %v", ifi.Name, fixtureIf.HardwareAddr, err)
+			}
+			ifi.HardwareAddr = hwAddr
+		}
+
+		flags, err := parseInterfaceFlags(fixtureIf.Flags)
+		if err != nil {
+			return nil, fmt.Errorf("interface %s: %v", ifi.Name, err)
+		}
+		ifi.Flags = flags
+
+		addrs := make([]IPAddr, 0, len(fixtureIf.Addresses))
+		for _, addr := range fixtureIf.Addresses {
+			ip, err := NewIPAddr(addr)
+			if err != nil {
+				return nil, fmt.Errorf("interface %s: invalid address %q: %v", ifi.Name, addr, err)
+			}
+			addrs = append(addrs, ip)
+		}
+		fake.AddInterface(ifi, addrs...)
+
+		for _, fixtureState := range fixtureIf.AddressStates {
+			ip, err := NewIPAddr(fixtureState.Address)
+			if err != nil {
+				return nil, fmt.Errorf("interface %s: invalid address %q: %v", ifi.Name, fixtureState.Address, err)
+			}
+			addrFlags, err := parseAddrFlags(fixtureState.Flags)
+			if err != nil {
+				return nil, fmt.Errorf("interface %s: address %s: %v", ifi.Name, fixtureState.Address, err)
+			}
+
+			lifetimes := []time.Duration{LifetimeForever, LifetimeForever}
+			for i, lifetime := range []string{fixtureState.PreferredLifetime, fixtureState.ValidLifetime} {
+				if lifetime == "" {
+					continue
+				}
+				if lifetimes[i], err = parseLifetime(lifetime); err != nil {
+					return nil, fmt.Errorf("interface %s: address %s: %v", ifi.Name, fixtureState.Address, err)
+				}
+			}
+			fake.SetAddrState(ifi.Name, ip, addrFlags, lifetimes[0], lifetimes[1])
+		}
+
+		link := LinkInfo{
+			Kind:      fixtureIf.Kind,
+			Master:    fixtureIf.Master,
+			VLANID:    fixtureIf.VLANID,
+			OperState: fixtureIf.OperState,
+			Carrier:   fixtureIf.Carrier,
+			SpeedMbps: fixtureIf.SpeedMbps,
+			Duplex:    fixtureIf.Duplex,
+			Stats: LinkStats{
+				RxBytes:  fixtureIf.RxBytes,
+				TxBytes:  fixtureIf.TxBytes,
+				RxErrors: fixtureIf.RxErrors,
+				TxErrors: fixtureIf.TxErrors,
+			},
+		}
+		if link != (LinkInfo{}) {
+			fake.SetLinkInfo(ifi.Name, link)
+		}
+
+		for _, fixtureNeighbor := range fixtureIf.Neighbors {
+			ip, err := NewIPAddr(fixtureNeighbor.Address)
+			if err != nil {
+				return nil, fmt.Errorf("interface %s: invalid neighbor address %q: %v", ifi.Name, fixtureNeighbor.Address, err)
+			}
+
+			neighbor := NeighborInfo{State: fixtureNeighbor.State}
+			if fixtureNeighbor.LLAddr != "" {
+				hwAddr, err := net.ParseMAC(fixtureNeighbor.LLAddr)
+				if err != nil {
+					return nil, fmt.Errorf("interface %s: invalid neighbor link-layer address %q: %v", ifi.Name, fixtureNeighbor.LLAddr, err)
+				}
+				neighbor.HardwareAddr = hwAddr
+			}
+			fake.AddNeighbor(ifi.Name, ip, neighbor)
+		}
+
+		for _, addr := range fixtureIf.Nameservers {
+			nameserver, err := parseNameserver(addr)
+			if err != nil {
+				return nil, fmt.Errorf("interface %s: %v", ifi.Name, err)
+			}
+			if resolver.LinkNameservers == nil {
+				resolver.LinkNameservers = make(map[string]SockAddrs)
+			}
+			resolver.LinkNameservers[ifi.Name] = append(resolver.LinkNameservers[ifi.Name], nameserver)
+		}
+	}
+
+	for i, fixtureRoute := range fixture.Routes {
+		route := Route{
+			Metric:   fixtureRoute.Metric,
+			IfName:   fixtureRoute.Interface,
+			Table:    fixtureRoute.Table,
+			Protocol: fixtureRoute.Protocol,
+		}
+
+		for _, field := range []struct {
+			name  string
+			value string
+			ip    *IPAddr
+		}{
+			{name: "destination", value: fixtureRoute.Destination, ip: &route.Destination},
+			{name: "gateway", value: fixtureRoute.Gateway, ip: &route.Gateway},
+			{name: "source", value: fixtureRoute.Source, ip: &route.Source},
+		} {
+			if field.value == "" {
+				continue
+			}
+
+			ip, err := NewIPAddr(field.value)
+			if err != nil {
+				return nil, fmt.Errorf("route %d: invalid %s %q: %v", i, field.name, field.value, err)
+			}
+			*field.ip = ip
+		}
+		if route.Destination == nil {
+			return nil, fmt.Errorf("route %d: missing destination", i)
+		}
+
+		fake.AddRoute(route)
+	}
+
+	if fixture.DefaultInterface != "" {
+		fake.SetDefaultInterface(fixture.DefaultInterface)
+	}
+
+	if fixture.Resolver != nil {
+		for _, addr := range fixture.Resolver.Nameservers {
+			nameserver, err := parseNameserver(addr)
+			if err != nil {
+				return nil, fmt.Errorf("resolver: %v", err)
+			}
+			resolver.Nameservers = append(resolver.Nameservers, nameserver)
+		}
+		resolver.Search = fixture.Resolver.Search
+		resolver.Options = fixture.Resolver.Options
+	}
+	fake.SetResolverConfig(resolver)
+
+	return fake, nil
+}
+
+// parseInterfaceFlags parses interface flags joined by "|" as printed by
+// net.Flags (e.g. "up|broadcast|multicast").
+func parseInterfaceFlags(s string) (net.Flags, error) {
+	var flags net.Flags
+	if s == "" || s == "0" {
+		return flags, nil
+	}
+
+	for _, name := range strings.Split(s, "|") {
+		var found bool
+		for flag := net.Flags(1); flag != 0 && flag <= net.FlagRunning; flag <<= 1 {
+			if flag.String() == name {
+				flags |= flag
+				found = true
+				break
+			}
+		}
+		if !found {
+			return 0, fmt.Errorf("unknown interface flag %q", name)
+		}
+	}
+	return flags, nil
+}
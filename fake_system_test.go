@@ -0,0 +1,337 @@
+package sockaddr_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	sockaddr "github.com/hashicorp/go-sockaddr"
+)
+
+func TestLoadFakeSystemFile(t *testing.T) {
+	fake, err := sockaddr.LoadFakeSystemFile(filepath.Join("testdata", "fake_system.json"))
+	if err != nil {
+		t.Fatalf("unable to load fixture: %v", err)
+	}
+	sockaddr.SetSystemProvider(fake)
+	defer sockaddr.SetSystemProvider(nil)
+
+	ifAddrs, err := sockaddr.GetAllInterfaces()
+	if err != nil {
+		t.Fatalf("unable to get interfaces: %v", err)
+	}
+
+	var got []string
+	for _, ifAddr := range ifAddrs {
+		got = append(got, ifAddr.Name+" "+ifAddr.SockAddr.String())
+	}
+	want := "lo 127.0.0.1/8,lo ::1,eth0 10.0.0.5/24,eth0 fd00:10::5/64,eth0 fe80::42:aff:fe00:5/64,eth1 34.120.5.10/24"
+	if strings.Join(got, ",") != want {
+		t.Errorf("want %s, got %s", want, strings.Join(got, ","))
+	}
+	if flags := ifAddrs[2].Flags; flags != net.FlagUp|net.FlagBroadcast|net.FlagMulticast|net.FlagRunning {
+		t.Errorf("unexpected eth0 flags %v", flags)
+	}
+
+	tests := []struct {
+		name string
+		fn   func() (string, error)
+		want string
+	}{
+		{name: "GetPrivateIP", fn: sockaddr.GetPrivateIP, want: "10.0.0.5"},
+		{name: "GetPublicIP", fn: sockaddr.GetPublicIP, want: "34.120.5.10"},
+		{
+			name: "GetDefaultInterfaces",
+			fn: func() (string, error) {
+				ifAddrs, err := sockaddr.GetDefaultInterfaces()
+				if err != nil {
+					return "", err
+				}
+				return sockaddr.IfAttrs("name", ifAddrs[:1])
+			},
+			want: "eth0",
+		},
+		{
+			name: "RouteTo",
+			fn: func() (string, error) {
+				src, gateway, err := sockaddr.RouteTo(sockaddr.MustIPv4Addr("10.20.0.1"))
+				return src.SockAddr.String() + " via " + gateway.String(), err
+			},
+			want: "10.0.0.5/24 via 10.0.0.254",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := test.fn()
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+			if got != test.want {
+				t.Errorf("want %q, got %q", test.want, got)
+			}
+		})
+	}
+
+	// The UDP fallback is not used for a fake system.
+	if _, _, err := sockaddr.RouteTo(sockaddr.MustIPv6Addr("2001:db8::1")); err != nil {
+		t.Errorf("expected the IPv6 default route to be used: %v", err)
+	}
+}
+
+func TestFakeSystemInCode(t *testing.T) {
+	eth0 := net.Interface{Name: "eth0", Flags: net.FlagUp | net.FlagBroadcast}
+	fake := sockaddr.NewFakeSystem().
+		AddInterface(net.Interface{Name: "lo", Flags: net.FlagUp | net.FlagLoopback}, sockaddr.MustIPv4Addr("127.0.0.1/8")).
+		AddInterface(eth0, sockaddr.MustIPv4Addr("192.168.7.20/24")).
+		AddRoute(sockaddr.Route{
+			Destination: sockaddr.MustIPv4Addr("0.0.0.0/0"),
+			Gateway:     sockaddr.MustIPv4Addr("192.168.7.1"),
+			IfName:      "eth0",
+		})
+	sockaddr.SetSystemProvider(fake)
+	defer sockaddr.SetSystemProvider(nil)
+
+	ifAddrs, err := sockaddr.GetDefaultInterfaces()
+	if err != nil {
+		t.Fatalf("unable to get default interfaces: %v", err)
+	}
+	if len(ifAddrs) != 1 || ifAddrs[0].Index != 2 {
+		t.Fatalf("expected eth0 with index 2, got %v", ifAddrs)
+	}
+	if gateway := sockaddr.IfAddrAttr(ifAddrs[0], "gateway"); gateway != "192.168.7.1" {
+		t.Errorf("want gateway 192.168.7.1, got %q", gateway)
+	}
+
+	if _, _, err := sockaddr.RouteTo(sockaddr.MustIPv6Addr("2001:db8::1")); err == nil {
+		t.Errorf("expected a destination without a route to fail")
+	}
+}
+
+func TestLoadFakeSystemErrors(t *testing.T) {
+	inputs := []string{
+		`{"interfaces": [{"name": "eth0", "flags": "up|bogus"}]}`,
+		`{"interfaces": [{"name": "eth0", "addresses": ["10.0.0.300/24"]}]}`,
+		`{"routes": [{"gateway": "10.0.0.1"}]}`,
+		`{"interface": []}`,
+		`{"version": 4, "interfaces": []}`,
+		`{"interfaces": [{"name": "eth0", "address_states": [{"address": "fd00::9", "flags": "bogus"}]}]}`,
+		`{"interfaces": [{"name": "eth0", "address_states": [{"address": "fd00::9", "flags": "dynamic", "valid_lifetime": "soon"}]}]}`,
+	}
+	for _, input := range inputs {
+		if _, err := sockaddr.LoadFakeSystem(strings.NewReader(input)); err == nil {
+			t.Errorf("expected %s to fail", input)
+		}
+	}
+}
+
+func TestLoadFakeSystemNewerVersion(t *testing.T) {
+	input := `{"version": 4, "interfaces": [], "tunnels": []}`
+	_, err := sockaddr.LoadFakeSystem(strings.NewReader(input))
+	if err == nil || !strings.Contains(err.Error(), "unsupported snapshot version 4") {
+		t.Errorf("want the version of %s to be rejected, got %v", input, err)
+	}
+}
+
+func TestWriteSnapshot(t *testing.T) {
+	fake, err := sockaddr.LoadFakeSystemFile(filepath.Join("testdata", "fake_system.json"))
+	if err != nil {
+		t.Fatalf("unable to load fixture: %v", err)
+	}
+	sockaddr.SetSystemProvider(fake)
+	defer sockaddr.SetSystemProvider(nil)
+
+	want, err := sockaddr.GetAllInterfaces()
+	if err != nil {
+		t.Fatalf("unable to get interfaces: %v", err)
+	}
+	wantRoutes, err := sockaddr.GetDefaultRoutes(sockaddr.TypeIPv4)
+	if err != nil {
+		t.Fatalf("unable to get default routes: %v", err)
+	}
+	wantNeighbors, err := sockaddr.GetNeighbors()
+	if err != nil {
+		t.Fatalf("unable to get neighbors: %v", err)
+	}
+	wantResolver, err := sockaddr.GetResolverConfig()
+	if err != nil {
+		t.Fatalf("unable to get the resolver configuration: %v", err)
+	}
+	wantNameservers, err := sockaddr.GetNameservers()
+	if err != nil {
+		t.Fatalf("unable to get nameservers: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := sockaddr.WriteSnapshot(&buf); err != nil {
+		t.Fatalf("unable to write snapshot: %v", err)
+	}
+
+	var header struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &header); err != nil {
+		t.Fatalf("unable to decode snapshot: %v", err)
+	}
+	if header.Version != sockaddr.SnapshotVersion {
+		t.Errorf("want version %d, got %d", sockaddr.SnapshotVersion, header.Version)
+	}
+
+	snapshot, err := sockaddr.LoadFakeSystem(&buf)
+	if err != nil {
+		t.Fatalf("unable to load snapshot: %v", err)
+	}
+	sockaddr.SetSystemProvider(snapshot)
+
+	got, err := sockaddr.GetAllInterfaces()
+	if err != nil {
+		t.Fatalf("unable to get interfaces: %v", err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("want %d addresses, got %d", len(want), len(got))
+	}
+	for i := range want {
+		if got[i].Name != want[i].Name || got[i].Flags != want[i].Flags || !got[i].SockAddr.Equal(want[i].SockAddr) {
+			t.Errorf("[%d] want %s %v %s, got %s %v %s", i, want[i].Name, want[i].Flags, want[i].SockAddr, got[i].Name, got[i].Flags, got[i].SockAddr)
+		}
+		if got[i].Link != want[i].Link {
+			t.Errorf("[%d] want link %+v, got %+v", i, want[i].Link, got[i].Link)
+		}
+	}
+	if eth1 := got[len(got)-1]; eth1.Link.Kind != sockaddr.KindVLAN || eth1.Link.VLANID != 120 {
+		t.Errorf("expected eth1 to be VLAN 120, got %+v", eth1.Link)
+	}
+
+	gotRoutes, err := sockaddr.GetDefaultRoutes(sockaddr.TypeIPv4)
+	if err != nil {
+		t.Fatalf("unable to get default routes: %v", err)
+	}
+	if len(wantRoutes) != 2 || len(gotRoutes) != len(wantRoutes) {
+		t.Fatalf("want %d default routes, got %d", len(wantRoutes), len(gotRoutes))
+	}
+	for i := range wantRoutes {
+		if gotRoutes[i].IfName != wantRoutes[i].IfName || gotRoutes[i].Metric != wantRoutes[i].Metric || !gotRoutes[i].Gateway.Equal(wantRoutes[i].Gateway) {
+			t.Errorf("[%d] want %+v, got %+v", i, wantRoutes[i], gotRoutes[i])
+		}
+	}
+
+	gotNeighbors, err := sockaddr.GetNeighbors()
+	if err != nil {
+		t.Fatalf("unable to get neighbors: %v", err)
+	}
+	if len(wantNeighbors) != 5 || len(gotNeighbors) != len(wantNeighbors) {
+		t.Fatalf("want %d neighbors, got %d", len(wantNeighbors), len(gotNeighbors))
+	}
+	for i := range wantNeighbors {
+		if gotNeighbors[i].Name != wantNeighbors[i].Name || !gotNeighbors[i].SockAddr.Equal(wantNeighbors[i].SockAddr) ||
+			gotNeighbors[i].Neighbor.HardwareAddr.String() != wantNeighbors[i].Neighbor.HardwareAddr.String() ||
+			gotNeighbors[i].Neighbor.State != wantNeighbors[i].Neighbor.State {
+			t.Errorf("[%d] want %s %s %+v, got %s %s %+v", i, wantNeighbors[i].Name, wantNeighbors[i].SockAddr, wantNeighbors[i].Neighbor,
+				gotNeighbors[i].Name, gotNeighbors[i].SockAddr, gotNeighbors[i].Neighbor)
+		}
+	}
+
+	gotResolver, err := sockaddr.GetResolverConfig()
+	if err != nil {
+		t.Fatalf("unable to get the resolver configuration: %v", err)
+	}
+	if !reflect.DeepEqual(gotResolver.Search, wantResolver.Search) || !reflect.DeepEqual(gotResolver.Options, wantResolver.Options) {
+		t.Errorf("want search %q and options %q, got %q and %q", wantResolver.Search, wantResolver.Options, gotResolver.Search, gotResolver.Options)
+	}
+	gotNameservers, err := sockaddr.GetNameservers()
+	if err != nil {
+		t.Fatalf("unable to get nameservers: %v", err)
+	}
+	if len(wantNameservers) != 4 || len(gotNameservers) != len(wantNameservers) {
+		t.Fatalf("want %d nameservers, got %d", len(wantNameservers), len(gotNameservers))
+	}
+	for i := range wantNameservers {
+		if gotNameservers[i].Name != wantNameservers[i].Name || !gotNameservers[i].SockAddr.Equal(wantNameservers[i].SockAddr) {
+			t.Errorf("[%d] want %s %s, got %s %s", i, wantNameservers[i].Name, wantNameservers[i].SockAddr, gotNameservers[i].Name, gotNameservers[i].SockAddr)
+		}
+	}
+}
+
+func TestFakeSystemAddrStates(t *testing.T) {
+	fake, err := sockaddr.LoadFakeSystemFile(filepath.Join("testdata", "fake_system_addr_states.json"))
+	if err != nil {
+		t.Fatalf("unable to load fixture: %v", err)
+	}
+
+	// Replay the fixture through a snapshot, as "sockaddr snapshot" and the
+	// -snapshot flag of "sockaddr eval" do.
+	sockaddr.SetSystemProvider(fake)
+	var buf bytes.Buffer
+	err = sockaddr.WriteSnapshot(&buf)
+	sockaddr.SetSystemProvider(nil)
+	if err != nil {
+		t.Fatalf("unable to write snapshot: %v", err)
+	}
+	snapshot, err := sockaddr.LoadFakeSystem(&buf)
+	if err != nil {
+		t.Fatalf("unable to load snapshot: %v", err)
+	}
+	system := sockaddr.NewSystem(context.Background(), snapshot)
+
+	ifAddrs, err := system.GetAllInterfaces()
+	if err != nil {
+		t.Fatalf("unable to get interfaces: %v", err)
+	}
+	var states []string
+	for _, ifAddr := range ifAddrs {
+		address, err := sockaddr.IfAttr("address", ifAddr)
+		if err != nil {
+			t.Fatalf("unable to get address: %v", err)
+		}
+		state := ifAddr.Name + " " + address + " " + ifAddr.AddrFlags.String()
+		if ifAddr.AddrFlags != 0 {
+			state += " " + sockaddr.IfAddrAttr(ifAddr, "preferred_lifetime") + "/" + sockaddr.IfAddrAttr(ifAddr, "valid_lifetime")
+		}
+		states = append(states, state)
+	}
+	want := []string{
+		"lo 127.0.0.1 ",
+		"lo ::1 ",
+		"eth0 10.0.0.5 permanent forever/forever",
+		"eth0 fd00:10::a:5 deprecated|dynamic 0/1200",
+		"eth0 fd00:10::5 dynamic 14400/86400",
+		"eth0 2600:1f18:4a3:6902::a:5 temporary|dynamic 3600/7200",
+		"eth0 2600:1f18:4a3:6902::5 dynamic|noprefixroute 14400/86400",
+		"eth0 fe80::42:aff:fe00:5 permanent forever/forever",
+	}
+	if !reflect.DeepEqual(states, want) {
+		t.Errorf("want %q, got %q", want, states)
+	}
+
+	tests := []struct {
+		name string
+		fn   func() (sockaddr.IfAddrs, error)
+		want string
+	}{
+		{name: "GetPrivateInterfaces", fn: system.GetPrivateInterfaces, want: "10.0.0.5 fd00:10::5"},
+		{name: "GetPublicInterfaces", fn: system.GetPublicInterfaces, want: "2600:1f18:4a3:6902::5"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ifAddrs, err := test.fn()
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+			var addresses []string
+			for _, ifAddr := range ifAddrs {
+				address, err := sockaddr.IfAttr("address", ifAddr)
+				if err != nil {
+					t.Fatalf("unable to get address: %v", err)
+				}
+				addresses = append(addresses, address)
+			}
+			if got := strings.Join(addresses, " "); got != test.want {
+				t.Errorf("want %q, got %q", test.want, got)
+			}
+		})
+	}
+}
@@ -1,8 +1,26 @@
 module github.com/hashicorp/go-sockaddr
 
+// Go 1.21 is needed for min and binary.NativeEndian, and Go 1.20 for
+// net.FlagRunning.
+go 1.21
+
 require (
 	github.com/hashicorp/errwrap v1.0.0
 	github.com/mitchellh/cli v1.0.0
 	github.com/mitchellh/go-wordwrap v1.0.0
 	github.com/ryanuber/columnize v2.1.0+incompatible
 )
+
+// Since Go 1.17, go.mod lists every module that provides a package built by
+// this module.  cmd/sockaddr imports github.com/mitchellh/cli, which needs the
+// modules below, and "go build -mod=readonly ./..." fails without them.
+require (
+	github.com/armon/go-radix v0.0.0-20180808171621-7fddfc383310 // indirect
+	github.com/bgentry/speakeasy v0.1.0 // indirect
+	github.com/fatih/color v1.7.0 // indirect
+	github.com/hashicorp/go-multierror v1.0.0 // indirect
+	github.com/mattn/go-colorable v0.0.9 // indirect
+	github.com/mattn/go-isatty v0.0.3 // indirect
+	github.com/posener/complete v1.1.1 // indirect
+	golang.org/x/sys v0.0.0-20180823144017-11551d06cbcc // indirect
+)
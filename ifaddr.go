@@ -19,7 +19,13 @@ func init() {
 // $ sockaddr eval -r '{{GetPrivateInterfaces | attr "address"}}'
 /// ```
 func GetPrivateIP() (string, error) {
-	privateIfs, err := GetPrivateInterfaces()
+	return defaultSystem().GetPrivateIP()
+}
+
+// GetPrivateIP is like the package-level GetPrivateIP, but inspects the host of
+// s.
+func (s *System) GetPrivateIP() (string, error) {
+	privateIfs, err := s.GetPrivateInterfaces()
 	if err != nil {
 		return "", err
 	}
@@ -41,7 +47,13 @@ func GetPrivateIP() (string, error) {
 // $ sockaddr eval -r '{{GetAllInterfaces | include "RFC" "6890" | join "address" " "}}'
 /// ```
 func GetPrivateIPs() (string, error) {
-	ifAddrs, err := GetAllInterfaces()
+	return defaultSystem().GetPrivateIPs()
+}
+
+// GetPrivateIPs is like the package-level GetPrivateIPs, but inspects the host
+// of s.
+func (s *System) GetPrivateIPs() (string, error) {
+	ifAddrs, err := s.GetAllInterfaces()
 	if err != nil {
 		return "", err
 	} else if len(ifAddrs) < 1 {
@@ -88,7 +100,13 @@ func GetPrivateIPs() (string, error) {
 // $ sockaddr eval -r '{{GetPublicInterfaces | attr "address"}}'
 /// ```
 func GetPublicIP() (string, error) {
-	publicIfs, err := GetPublicInterfaces()
+	return defaultSystem().GetPublicIP()
+}
+
+// GetPublicIP is like the package-level GetPublicIP, but inspects the host of
+// s.
+func (s *System) GetPublicIP() (string, error) {
+	publicIfs, err := s.GetPublicInterfaces()
 	if err != nil {
 		return "", err
 	} else if len(publicIfs) < 1 {
@@ -110,7 +128,13 @@ func GetPublicIP() (string, error) {
 // $ sockaddr eval -r '{{GetAllInterfaces | exclude "RFC" "6890" | join "address" " "}}'
 /// ```
 func GetPublicIPs() (string, error) {
-	ifAddrs, err := GetAllInterfaces()
+	return defaultSystem().GetPublicIPs()
+}
+
+// GetPublicIPs is like the package-level GetPublicIPs, but inspects the host of
+// s.
+func (s *System) GetPublicIPs() (string, error) {
+	ifAddrs, err := s.GetAllInterfaces()
 	if err != nil {
 		return "", err
 	} else if len(ifAddrs) < 1 {
@@ -149,7 +173,13 @@ func GetPublicIPs() (string, error) {
 // $ sockaddr eval -r '{{GetAllInterfaces | include "name" <<ARG>> | sort "type,size" | include "flag" "forwardable" | attr "address" }}'
 /// ```
 func GetInterfaceIP(namedIfRE string) (string, error) {
-	ifAddrs, err := GetAllInterfaces()
+	return defaultSystem().GetInterfaceIP(namedIfRE)
+}
+
+// GetInterfaceIP is like the package-level GetInterfaceIP, but inspects the
+// host of s.
+func (s *System) GetInterfaceIP(namedIfRE string) (string, error) {
+	ifAddrs, err := s.GetAllInterfaces()
 	if err != nil {
 		return "", err
 	}
@@ -190,7 +220,13 @@ func GetInterfaceIP(namedIfRE string) (string, error) {
 // $ sockaddr eval -r '{{GetAllInterfaces | include "name" <<ARG>> | sort "type,size" | join "address" " "}}'
 /// ```
 func GetInterfaceIPs(namedIfRE string) (string, error) {
-	ifAddrs, err := GetAllInterfaces()
+	return defaultSystem().GetInterfaceIPs(namedIfRE)
+}
+
+// GetInterfaceIPs is like the package-level GetInterfaceIPs, but inspects the
+// host of s.
+func (s *System) GetInterfaceIPs(namedIfRE string) (string, error) {
+	ifAddrs, err := s.GetAllInterfaces()
 	if err != nil {
 		return "", err
 	}
@@ -239,16 +275,110 @@ func IfAddrAttr(ifAddr IfAddr, attrName AttrName) string {
 func ifAddrAttrInit() {
 	// Sorted for human readability
 	ifAddrAttrs = []AttrName{
+		"address_flags",
+		"carrier",
+		"duplex",
 		"flags",
+		"gateway",
+		"kind",
+		"lladdr",
+		"master",
 		"name",
+		"neighbor_state",
+		"operstate",
+		"preferred_lifetime",
+		"rx_bytes",
+		"rx_errors",
+		"speed_mbps",
+		"tx_bytes",
+		"tx_errors",
+		"valid_lifetime",
+		"vlan_id",
 	}
 
 	ifAddrAttrMap = map[AttrName]func(ifAddr IfAddr) string{
+		"address_flags": func(ifAddr IfAddr) string {
+			return ifAddr.AddrFlags.String()
+		},
+		"carrier": func(ifAddr IfAddr) string {
+			return carrierString(ifAddr.Link)
+		},
+		"duplex": func(ifAddr IfAddr) string {
+			return ifAddr.Link.Duplex
+		},
 		"flags": func(ifAddr IfAddr) string {
 			return ifAddr.Interface.Flags.String()
 		},
+		"gateway": func(ifAddr IfAddr) string {
+			gateway := ifAddrGateway(ifAddr)
+			if gateway == nil {
+				return ""
+			}
+			return gateway.String()
+		},
+		"kind": func(ifAddr IfAddr) string {
+			return ifAddr.Link.Kind
+		},
+		"lladdr": func(ifAddr IfAddr) string {
+			return ifAddr.Neighbor.HardwareAddr.String()
+		},
+		"master": func(ifAddr IfAddr) string {
+			return ifAddr.Link.Master
+		},
 		"name": func(ifAddr IfAddr) string {
 			return ifAddr.Interface.Name
 		},
+		"neighbor_state": func(ifAddr IfAddr) string {
+			return ifAddr.Neighbor.State
+		},
+		"operstate": func(ifAddr IfAddr) string {
+			return ifAddr.Link.OperState
+		},
+		"preferred_lifetime": func(ifAddr IfAddr) string {
+			return lifetimeString(ifAddr.AddrFlags, ifAddr.PreferredLifetime)
+		},
+		"rx_bytes": func(ifAddr IfAddr) string {
+			return counterString(ifAddr.Link, ifAddr.Link.Stats.RxBytes)
+		},
+		"rx_errors": func(ifAddr IfAddr) string {
+			return counterString(ifAddr.Link, ifAddr.Link.Stats.RxErrors)
+		},
+		"speed_mbps": func(ifAddr IfAddr) string {
+			return speedString(ifAddr.Link)
+		},
+		"tx_bytes": func(ifAddr IfAddr) string {
+			return counterString(ifAddr.Link, ifAddr.Link.Stats.TxBytes)
+		},
+		"tx_errors": func(ifAddr IfAddr) string {
+			return counterString(ifAddr.Link, ifAddr.Link.Stats.TxErrors)
+		},
+		"valid_lifetime": func(ifAddr IfAddr) string {
+			return lifetimeString(ifAddr.AddrFlags, ifAddr.ValidLifetime)
+		},
+		"vlan_id": func(ifAddr IfAddr) string {
+			return vlanIDString(ifAddr.Link)
+		},
 	}
 }
+
+// ifAddrGateway returns the gateway of the default route that leaves through
+// the interface of ifAddr for the address family of ifAddr, or nil if the
+// interface has no default route.  The routes are those of the System ifAddr
+// was returned by, or else the current routes.
+func ifAddrGateway(ifAddr IfAddr) IPAddr {
+	if ifAddr.SockAddr == nil {
+		return nil
+	}
+
+	ip := ToIPAddr(ifAddr.SockAddr)
+	if ip == nil {
+		return nil
+	}
+
+	snapshot := ifAddr.system.routeSnapshot()
+	if snapshot.routesErr != nil {
+		return nil
+	}
+
+	return defaultGateway(snapshot.routes, ifAddr.Name, (*ip).Type())
+}
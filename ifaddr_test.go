@@ -518,6 +518,276 @@ func TestIfAddrMath(t *testing.T) {
 			value:     "-1",
 			wantFail:  true,
 		},
+		{
+			name: "ipv4 address hex",
+			ifAddr: sockaddr.IfAddr{
+				SockAddr: sockaddr.MustIPv4Addr("10.0.0.1/8"),
+			},
+			operation: "address",
+			value:     "+0x100",
+			expected:  "10.0.1.1/8",
+		},
+		{
+			name: "ipv4 address out of range",
+			ifAddr: sockaddr.IfAddr{
+				SockAddr: sockaddr.MustIPv4Addr("10.0.0.1/8"),
+			},
+			operation: "address",
+			value:     "+4294967296",
+			wantFail:  true,
+		},
+		{
+			name: "ipv4 address leading zero is decimal",
+			ifAddr: sockaddr.IfAddr{
+				SockAddr: sockaddr.MustIPv4Addr("10.0.0.0/24"),
+			},
+			operation: "address",
+			value:     "+010",
+			expected:  "10.0.0.10/24",
+		},
+		{
+			name: "ipv4 address underscore",
+			ifAddr: sockaddr.IfAddr{
+				SockAddr: sockaddr.MustIPv4Addr("10.0.0.0/24"),
+			},
+			operation: "address",
+			value:     "+1_0",
+			wantFail:  true,
+		},
+		{
+			name: "ipv4 address octal prefix",
+			ifAddr: sockaddr.IfAddr{
+				SockAddr: sockaddr.MustIPv4Addr("10.0.0.0/24"),
+			},
+			operation: "address",
+			value:     "+0o10",
+			wantFail:  true,
+		},
+		{
+			name: "ipv4 network out of range",
+			ifAddr: sockaddr.IfAddr{
+				SockAddr: sockaddr.MustIPv4Addr("10.0.0.0/24"),
+			},
+			operation: "network",
+			value:     "-4294967297",
+			wantFail:  true,
+		},
+		{
+			name: "ipv4 network hex",
+			ifAddr: sockaddr.IfAddr{
+				SockAddr: sockaddr.MustIPv4Addr("10.0.0.1/8"),
+			},
+			operation: "network",
+			value:     "-0x1",
+			expected:  "10.255.255.255/8",
+		},
+		{
+			name: "ipv6 address bignum",
+			ifAddr: sockaddr.IfAddr{
+				SockAddr: sockaddr.MustIPv6Addr("::1/64"),
+			},
+			operation: "address",
+			value:     "+18446744073709551616",
+			expected:  "::1:0:0:0:1/64",
+		},
+		{
+			name: "ipv6 address hex",
+			ifAddr: sockaddr.IfAddr{
+				SockAddr: sockaddr.MustIPv6Addr("2001:db8::1/64"),
+			},
+			operation: "address",
+			value:     "+0xff00",
+			expected:  "2001:db8::ff01/64",
+		},
+		{
+			name: "ipv6 network bignum wrap",
+			ifAddr: sockaddr.IfAddr{
+				SockAddr: sockaddr.MustIPv6Addr("2001:db8::1/64"),
+			},
+			operation: "network",
+			value:     "-18446744073709551617",
+			expected:  "2001:db8::ffff:ffff:ffff:ffff/64",
+		},
+		{
+			name: "ipv4 port set",
+			ifAddr: sockaddr.IfAddr{
+				SockAddr: sockaddr.MustIPv4Addr("10.0.0.1:80"),
+			},
+			operation: "port",
+			value:     "8080",
+			expected:  "10.0.0.1:8080",
+		},
+		{
+			name: "ipv4 port adjust",
+			ifAddr: sockaddr.IfAddr{
+				SockAddr: sockaddr.MustIPv4Addr("10.0.0.1:80"),
+			},
+			operation: "port",
+			value:     "+0x10",
+			expected:  "10.0.0.1:96",
+		},
+		{
+			name: "ipv6 port adjust",
+			ifAddr: sockaddr.IfAddr{
+				SockAddr: sockaddr.MustIPv6Addr("[2001:db8::1]:443"),
+			},
+			operation: "port",
+			value:     "-1",
+			expected:  "[2001:db8::1]:442",
+		},
+		{
+			name: "ipv4 port out of range",
+			ifAddr: sockaddr.IfAddr{
+				SockAddr: sockaddr.MustIPv4Addr("10.0.0.1:80"),
+			},
+			operation: "port",
+			value:     "65536",
+			wantFail:  true,
+		},
+		{
+			name: "ipv4 port underflow",
+			ifAddr: sockaddr.IfAddr{
+				SockAddr: sockaddr.MustIPv4Addr("10.0.0.1:80"),
+			},
+			operation: "port",
+			value:     "-81",
+			wantFail:  true,
+		},
+		{
+			name: "ipv4 prefix",
+			ifAddr: sockaddr.IfAddr{
+				SockAddr: sockaddr.MustIPv4Addr("10.1.2.3/8"),
+			},
+			operation: "prefix",
+			value:     "24",
+			expected:  "10.1.2.3/24",
+		},
+		{
+			name: "ipv4 prefix adjust",
+			ifAddr: sockaddr.IfAddr{
+				SockAddr: sockaddr.MustIPv4Addr("10.1.2.3/24"),
+			},
+			operation: "prefix",
+			value:     "-8",
+			expected:  "10.1.2.3/16",
+		},
+		{
+			name: "ipv6 prefix",
+			ifAddr: sockaddr.IfAddr{
+				SockAddr: sockaddr.MustIPv6Addr("2001:db8::1/128"),
+			},
+			operation: "prefix",
+			value:     "64",
+			expected:  "2001:db8::1/64",
+		},
+		{
+			name: "ipv4 prefix out of range",
+			ifAddr: sockaddr.IfAddr{
+				SockAddr: sockaddr.MustIPv4Addr("10.1.2.3/8"),
+			},
+			operation: "prefix",
+			value:     "33",
+			wantFail:  true,
+		},
+		{
+			name: "ipv4 and address",
+			ifAddr: sockaddr.IfAddr{
+				SockAddr: sockaddr.MustIPv4Addr("10.1.2.3/8"),
+			},
+			operation: "and",
+			value:     "255.255.0.255",
+			expected:  "10.1.0.3/8",
+		},
+		{
+			name: "ipv4 or integer",
+			ifAddr: sockaddr.IfAddr{
+				SockAddr: sockaddr.MustIPv4Addr("10.1.2.0/24"),
+			},
+			operation: "or",
+			value:     "0x7",
+			expected:  "10.1.2.7/24",
+		},
+		{
+			name: "ipv6 xor address",
+			ifAddr: sockaddr.IfAddr{
+				SockAddr: sockaddr.MustIPv6Addr("2001:db8::1/64"),
+			},
+			operation: "xor",
+			value:     "::ffff:0:0:0",
+			expected:  "2001:db8::ffff:0:0:1/64",
+		},
+		{
+			name: "ipv6 or address",
+			ifAddr: sockaddr.IfAddr{
+				SockAddr: sockaddr.MustIPv6Addr("fe80::/64"),
+			},
+			operation: "or",
+			value:     "::21b:21ff:fe12:3456",
+			expected:  "fe80::21b:21ff:fe12:3456/64",
+		},
+		{
+			name: "ipv4 and ipv6 operand",
+			ifAddr: sockaddr.IfAddr{
+				SockAddr: sockaddr.MustIPv4Addr("10.1.2.3/8"),
+			},
+			operation: "and",
+			value:     "::1",
+			wantFail:  true,
+		},
+		{
+			name: "ipv4 subnet",
+			ifAddr: sockaddr.IfAddr{
+				SockAddr: sockaddr.MustIPv4Addr("10.0.0.0/16"),
+			},
+			operation: "subnet",
+			value:     "24:3",
+			expected:  "10.0.3.0/24",
+		},
+		{
+			name: "ipv4 subnet last",
+			ifAddr: sockaddr.IfAddr{
+				SockAddr: sockaddr.MustIPv4Addr("10.0.0.0/16"),
+			},
+			operation: "subnet",
+			value:     "24:-1",
+			expected:  "10.0.255.0/24",
+		},
+		{
+			name: "ipv6 subnet hex index",
+			ifAddr: sockaddr.IfAddr{
+				SockAddr: sockaddr.MustIPv6Addr("2001:db8::/48"),
+			},
+			operation: "subnet",
+			value:     "64:0xff",
+			expected:  "2001:db8:0:ff::/64",
+		},
+		{
+			name: "ipv4 subnet index out of range",
+			ifAddr: sockaddr.IfAddr{
+				SockAddr: sockaddr.MustIPv4Addr("10.0.0.0/16"),
+			},
+			operation: "subnet",
+			value:     "24:256",
+			wantFail:  true,
+		},
+		{
+			name: "ipv4 subnet prefix too short",
+			ifAddr: sockaddr.IfAddr{
+				SockAddr: sockaddr.MustIPv4Addr("10.0.0.0/16"),
+			},
+			operation: "subnet",
+			value:     "8:0",
+			wantFail:  true,
+		},
+		{
+			name: "ipv4 subnet missing index",
+			ifAddr: sockaddr.IfAddr{
+				SockAddr: sockaddr.MustIPv4Addr("10.0.0.0/16"),
+			},
+			operation: "subnet",
+			value:     "24",
+			wantFail:  true,
+		},
 		{
 			name: "unix unsupported operation",
 			ifAddr: sockaddr.IfAddr{
@@ -1,9 +1,11 @@
 package sockaddr
 
 import (
+	"context"
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"math"
 	"math/big"
 	"net"
 	"regexp"
@@ -36,10 +38,25 @@ type multiIfAddrSorter struct {
 }
 
 // Sort sorts the argument slice according to the Cmp functions passed to
-// OrderedIfAddrBy.
+// OrderedIfAddrBy.  IfAddrs not returned by a System share one for the
+// duration of the sort, so that AscIfDefault reads the routing table once
+// rather than on every comparison.
 func (ms *multiIfAddrSorter) Sort(ifAddrs IfAddrs) {
+	sortSystem := NewSystem(context.Background(), nil)
+	for i := range ifAddrs {
+		if ifAddrs[i].system == nil {
+			ifAddrs[i].system = sortSystem
+		}
+	}
+
 	ms.ifAddrs = ifAddrs
 	sort.Sort(ms)
+
+	for i := range ifAddrs {
+		if ifAddrs[i].system == sortSystem {
+			ifAddrs[i].system = nil
+		}
+	}
 }
 
 // OrderedIfAddrBy sorts SockAddr by the list of sort function pointers.
@@ -102,33 +119,34 @@ func AscIfAddress(p1Ptr, p2Ptr *IfAddr) int {
 }
 
 // AscIfDefault is a sorting function to sort IfAddrs by whether or not they
-// have a default route or not.  Non-equal types are deferred in the sort.
-//
-// FIXME: This is a particularly expensive sorting operation because of the
-// non-memoized calls to NewRouteInfo().  In an ideal world the routeInfo data
-// once at the start of the sort and pass it along as a context or by wrapping
-// the IfAddr type with this information (this would also solve the inability to
-// return errors and the possibility of failing silently).  Fortunately,
-// N*log(N) where N = 3 is only ~6.2 invocations.  Not ideal, but not worth
-// optimizing today.  The common case is this gets called once or twice.
-// Patches welcome.
+// have a default route or not.  Interfaces with several default routes are
+// ordered by the metric of their default route, with IPv4 default routes
+// ahead of IPv6 default routes.  Non-equal types are deferred in the sort.
+// The routing table is read from the System the IfAddrs were returned by, or
+// else once per sort (see OrderedIfAddrBy), rather than for every comparison.
 func AscIfDefault(p1Ptr, p2Ptr *IfAddr) int {
-	ri, err := NewRouteInfo()
+	system := p1Ptr.system
+	if system == nil {
+		system = p2Ptr.system
+	}
+	defaultIfNames, err := system.routeSnapshot().defaultIfNames()
 	if err != nil {
 		return sortDeferDecision
 	}
 
-	defaultIfName, err := ri.GetDefaultInterfaceName()
-	if err != nil {
-		return sortDeferDecision
+	rank := func(ifName string) int {
+		for i, defaultIfName := range defaultIfNames {
+			if ifName == defaultIfName {
+				return i
+			}
+		}
+		return len(defaultIfNames)
 	}
 
-	switch {
-	case p1Ptr.Interface.Name == defaultIfName && p2Ptr.Interface.Name == defaultIfName:
-		return sortDeferDecision
-	case p1Ptr.Interface.Name == defaultIfName:
+	switch p1Rank, p2Rank := rank(p1Ptr.Interface.Name), rank(p2Ptr.Interface.Name); {
+	case p1Rank < p2Rank:
 		return sortReceiverBeforeArg
-	case p2Ptr.Interface.Name == defaultIfName:
+	case p1Rank > p2Rank:
 		return sortArgBeforeReceiver
 	default:
 		return sortDeferDecision
@@ -160,6 +178,19 @@ func AscIfPrivate(p1Ptr, p2Ptr *IfAddr) int {
 	return AscPrivate(&p1Ptr.SockAddr, &p2Ptr.SockAddr)
 }
 
+// AscIfSpeed is a sorting function to sort IfAddrs by the speed of their
+// interface, slowest first.  Interfaces of unknown speed sort as zero.
+func AscIfSpeed(p1Ptr, p2Ptr *IfAddr) int {
+	switch {
+	case p1Ptr.Link.SpeedMbps < p2Ptr.Link.SpeedMbps:
+		return sortReceiverBeforeArg
+	case p1Ptr.Link.SpeedMbps > p2Ptr.Link.SpeedMbps:
+		return sortArgBeforeReceiver
+	default:
+		return sortDeferDecision
+	}
+}
+
 // AscIfType is a sorting function to sort IfAddrs by their respective address
 // type.  Non-equal types are deferred in the sort.
 func AscIfType(p1Ptr, p2Ptr *IfAddr) int {
@@ -196,6 +227,11 @@ func DescIfPrivate(p1Ptr, p2Ptr *IfAddr) int {
 	return -1 * AscPrivate(&p1Ptr.SockAddr, &p2Ptr.SockAddr)
 }
 
+// DescIfSpeed is identical to AscIfSpeed but reverse ordered.
+func DescIfSpeed(p1Ptr, p2Ptr *IfAddr) int {
+	return -1 * AscIfSpeed(p1Ptr, p2Ptr)
+}
+
 // DescIfType is identical to AscIfType but reverse ordered.
 func DescIfType(p1Ptr, p2Ptr *IfAddr) int {
 	return -1 * AscType(&p1Ptr.SockAddr, &p2Ptr.SockAddr)
@@ -238,55 +274,165 @@ func IfAttrs(selectorName string, ifAddrs IfAddrs) (string, error) {
 
 // GetAllInterfaces iterates over all available network interfaces and finds all
 // available IP addresses on each interface and converts them to
-// sockaddr.IPAddrs, and returning the result as an array of IfAddr.
+// sockaddr.IPAddrs, and returning the result as an array of IfAddr.  If the
+// addresses of an interface can not be read or converted, an InterfaceErrors
+// is returned; use GetAllInterfacesPartial to skip the failing entries
+// instead.
 func GetAllInterfaces() (IfAddrs, error) {
-	ifs, err := net.Interfaces()
+	return defaultSystem().GetAllInterfaces()
+}
+
+// GetAllInterfaces is like the package-level GetAllInterfaces, but inspects the
+// host of s.
+func (s *System) GetAllInterfaces() (IfAddrs, error) {
+	ifAddrs, err := s.GetAllInterfacesPartial()
 	if err != nil {
 		return nil, err
 	}
 
+	return ifAddrs, nil
+}
+
+// GetAllInterfacesPartial is GetAllInterfaces with interfaces whose addresses
+// can not be read, and addresses that can not be converted to an IPAddr,
+// skipped.  It returns the remaining IfAddrs together with an InterfaceErrors
+// naming each skipped interface and address, or a nil error if nothing was
+// skipped.  The IfAddrs are nil if the interfaces can not be listed at all.
+func GetAllInterfacesPartial() (IfAddrs, error) {
+	return defaultSystem().GetAllInterfacesPartial()
+}
+
+// GetAllInterfacesPartial is like the package-level GetAllInterfacesPartial,
+// but inspects the host of s.
+func (s *System) GetAllInterfacesPartial() (IfAddrs, error) {
+	provider := s.systemProvider()
+	ifs, err := provider.Interfaces()
+	if err != nil {
+		return nil, err
+	}
+
+	// The state of the addresses and the link information are optional,
+	// e.g. they are unknown on platforms other than Linux.
+	var states map[addrStateKey]addrState
+	if reader, ok := provider.(addrStateReader); ok {
+		states, _ = reader.addrStates()
+	}
+	var links map[int]LinkInfo
+	if reader, ok := provider.(linkInfoReader); ok {
+		links, _ = reader.linkInfos()
+	}
+
+	var ifErrs InterfaceErrors
 	ifAddrs := make(IfAddrs, 0, len(ifs))
 	for _, intf := range ifs {
-		addrs, err := intf.Addrs()
+		addrs, err := provider.Addrs(intf)
 		if err != nil {
-			return nil, err
+			ifErrs = append(ifErrs, &InterfaceError{Interface: intf.Name, Err: err})
+			continue
 		}
 
 		for _, addr := range addrs {
 			var ipAddr IPAddr
 			ipAddr, err = NewIPAddr(addr.String())
 			if err != nil {
-				return IfAddrs{}, fmt.Errorf("unable to create an IP address from %q", addr.String())
+				ifErrs = append(ifErrs, &InterfaceError{Interface: intf.Name, Address: addr.String(), Err: err})
+				continue
 			}
 
 			ifAddr := IfAddr{
 				SockAddr:  ipAddr,
 				Interface: intf,
+				Link:      links[intf.Index],
+			}
+			if state, found := states[addrStateKey{index: intf.Index, address: ipAddr.NetIP().String()}]; found {
+				ifAddr.AddrFlags = state.flags
+				ifAddr.PreferredLifetime = state.preferredLifetime
+				ifAddr.ValidLifetime = state.validLifetime
 			}
 			ifAddrs = append(ifAddrs, ifAddr)
 		}
 	}
 
-	return ifAddrs, nil
+	if len(ifErrs) > 0 {
+		return s.bind(ifAddrs), ifErrs
+	}
+	return s.bind(ifAddrs), nil
 }
 
 // GetDefaultInterfaces returns IfAddrs of the addresses attached to the default
 // route.
 func GetDefaultInterfaces() (IfAddrs, error) {
-	ri, err := NewRouteInfo()
+	return defaultSystem().GetDefaultInterfaces()
+}
+
+// GetDefaultInterfaces is like the package-level GetDefaultInterfaces, but
+// inspects the host of s.
+func (s *System) GetDefaultInterfaces() (IfAddrs, error) {
+	snapshot := s.routeSnapshot()
+	if snapshot.defaultIfErr != nil {
+		return nil, snapshot.defaultIfErr
+	}
+	defaultIfName := snapshot.defaultIfName
+
+	ifAddrs, err := s.GetAllInterfaces()
 	if err != nil {
 		return nil, err
 	}
 
-	defaultIfName, err := ri.GetDefaultInterfaceName()
+	var defaultIfs IfAddrs
+	for _, ifAddr := range ifAddrs {
+		if ifAddr.Name == defaultIfName {
+			defaultIfs = append(defaultIfs, ifAddr)
+		}
+	}
+
+	return defaultIfs, nil
+}
+
+// GetDefaultInterfacesV4 returns IfAddrs of the IPv4 addresses attached to the
+// interface of the IPv4 default route with the lowest metric.
+func GetDefaultInterfacesV4() (IfAddrs, error) {
+	return defaultSystem().GetDefaultInterfacesV4()
+}
+
+// GetDefaultInterfacesV4 is like the package-level GetDefaultInterfacesV4, but
+// inspects the host of s.
+func (s *System) GetDefaultInterfacesV4() (IfAddrs, error) {
+	return s.getDefaultInterfacesOfType(TypeIPv4)
+}
+
+// GetDefaultInterfacesV6 returns IfAddrs of the IPv6 addresses attached to the
+// interface of the IPv6 default route with the lowest metric.
+func GetDefaultInterfacesV6() (IfAddrs, error) {
+	return defaultSystem().GetDefaultInterfacesV6()
+}
+
+// GetDefaultInterfacesV6 is like the package-level GetDefaultInterfacesV6, but
+// inspects the host of s.
+func (s *System) GetDefaultInterfacesV6() (IfAddrs, error) {
+	return s.getDefaultInterfacesOfType(TypeIPv6)
+}
+
+// getDefaultInterfacesOfType returns IfAddrs of the addresses of the given
+// type attached to the interface of the lowest metric default route for that
+// type.
+func (s *System) getDefaultInterfacesOfType(sockType SockAddrType) (IfAddrs, error) {
+	routes, err := s.GetDefaultRoutes(sockType)
 	if err != nil {
 		return nil, err
 	}
+	if len(routes) == 0 {
+		return IfAddrs{}, nil
+	}
 
-	var defaultIfs, ifAddrs IfAddrs
-	ifAddrs, err = GetAllInterfaces()
+	ifAddrs, err := s.GetAllInterfaces()
+	if err != nil {
+		return nil, err
+	}
+
+	var defaultIfs IfAddrs
 	for _, ifAddr := range ifAddrs {
-		if ifAddr.Name == defaultIfName {
+		if ifAddr.Name == routes[0].IfName && ifAddr.SockAddr.Type() == sockType {
 			defaultIfs = append(defaultIfs, ifAddr)
 		}
 	}
@@ -294,16 +440,28 @@ func GetDefaultInterfaces() (IfAddrs, error) {
 	return defaultIfs, nil
 }
 
+// unadvertisedAddrFlags are the address flags of addresses that should not be
+// advertised to other hosts: temporary addresses change regularly, and
+// deprecated, tentative or duplicate addresses are about to go away.
+const unadvertisedAddrFlags = "temporary|deprecated|tentative|dadfailed"
+
 // GetPrivateInterfaces returns an IfAddrs that are part of RFC 6890 and have a
-// default route.  If the system can't determine its IP address or find an RFC
-// 6890 IP address, an empty IfAddrs will be returned instead.  This function is
-// the `eval` equivalent of:
+// default route.  Temporary, deprecated, tentative and duplicate IPv6
+// addresses are skipped.  If the system can't determine its IP address or find
+// an RFC 6890 IP address, an empty IfAddrs will be returned instead.  This
+// function is the `eval` equivalent of:
 //
 // ```
-// $ sockaddr eval -r '{{GetAllInterfaces | include "type" "ip" | include "flags" "forwardable" | include "flags" "up" | sort "default,type,size" | include "RFC" "6890" }}'
+// $ sockaddr eval -r '{{GetAllInterfaces | include "type" "ip" | include "flags" "forwardable" | include "flags" "up" | exclude "flags" "temporary|deprecated|tentative|dadfailed" | sort "default,type,size" | include "RFC" "6890" }}'
 /// ```
 func GetPrivateInterfaces() (IfAddrs, error) {
-	privateIfs, err := GetAllInterfaces()
+	return defaultSystem().GetPrivateInterfaces()
+}
+
+// GetPrivateInterfaces is like the package-level GetPrivateInterfaces, but
+// inspects the host of s.
+func (s *System) GetPrivateInterfaces() (IfAddrs, error) {
+	privateIfs, err := s.GetAllInterfaces()
 	if err != nil {
 		return IfAddrs{}, err
 	}
@@ -326,6 +484,11 @@ func GetPrivateInterfaces() (IfAddrs, error) {
 		return IfAddrs{}, err
 	}
 
+	_, privateIfs, err = IfByFlag(unadvertisedAddrFlags, privateIfs)
+	if err != nil {
+		return IfAddrs{}, err
+	}
+
 	if len(privateIfs) == 0 {
 		return IfAddrs{}, nil
 	}
@@ -343,15 +506,22 @@ func GetPrivateInterfaces() (IfAddrs, error) {
 }
 
 // GetPublicInterfaces returns an IfAddrs that are NOT part of RFC 6890 and has a
-// default route.  If the system can't determine its IP address or find a non
-// RFC 6890 IP address, an empty IfAddrs will be returned instead.  This
+// default route.  Temporary, deprecated, tentative and duplicate IPv6
+// addresses are skipped.  If the system can't determine its IP address or find
+// a non RFC 6890 IP address, an empty IfAddrs will be returned instead.  This
 // function is the `eval` equivalent of:
 //
 // ```
-// $ sockaddr eval -r '{{GetAllInterfaces | include "type" "ip" | include "flags" "forwardable" | include "flags" "up" | sort "default,type,size" | exclude "RFC" "6890" }}'
+// $ sockaddr eval -r '{{GetAllInterfaces | include "type" "ip" | include "flags" "forwardable" | include "flags" "up" | exclude "flags" "temporary|deprecated|tentative|dadfailed" | sort "default,type,size" | exclude "RFC" "6890" }}'
 /// ```
 func GetPublicInterfaces() (IfAddrs, error) {
-	publicIfs, err := GetAllInterfaces()
+	return defaultSystem().GetPublicInterfaces()
+}
+
+// GetPublicInterfaces is like the package-level GetPublicInterfaces, but
+// inspects the host of s.
+func (s *System) GetPublicInterfaces() (IfAddrs, error) {
+	publicIfs, err := s.GetAllInterfaces()
 	if err != nil {
 		return IfAddrs{}, err
 	}
@@ -374,6 +544,11 @@ func GetPublicInterfaces() (IfAddrs, error) {
 		return IfAddrs{}, err
 	}
 
+	_, publicIfs, err = IfByFlag(unadvertisedAddrFlags, publicIfs)
+	if err != nil {
+		return IfAddrs{}, err
+	}
+
 	if len(publicIfs) == 0 {
 		return IfAddrs{}, nil
 	}
@@ -411,6 +586,59 @@ func IfByAddress(inputRe string, ifAddrs IfAddrs) (matched, remainder IfAddrs, e
 	return matchedAddrs, excludedAddrs, nil
 }
 
+// IfByKind returns a list of matched and non-matched IfAddrs whose interface
+// is of any of the given kinds (see LinkInfo).  Multiple kinds can be
+// specified and separated by the `|` symbol (e.g. "physical|bond").  Interface
+// kinds are only known on Linux, so no IfAddrs match on other platforms.
+func IfByKind(selectorParam string, ifAddrs IfAddrs) (matched, remainder IfAddrs, err error) {
+	kinds := make(map[string]struct{})
+	for _, kind := range strings.Split(strings.ToLower(selectorParam), "|") {
+		if kind == "" {
+			return IfAddrs{}, IfAddrs{}, fmt.Errorf("empty interface kind in %q", selectorParam)
+		}
+		kinds[kind] = struct{}{}
+	}
+
+	matchedIfs := make(IfAddrs, 0, len(ifAddrs))
+	excludedIfs := make(IfAddrs, 0, len(ifAddrs))
+	for _, ifAddr := range ifAddrs {
+		if _, found := kinds[ifAddr.Link.Kind]; found {
+			matchedIfs = append(matchedIfs, ifAddr)
+		} else {
+			excludedIfs = append(excludedIfs, ifAddr)
+		}
+	}
+
+	return matchedIfs, excludedIfs, nil
+}
+
+// IfByOperState returns a list of matched and non-matched IfAddrs whose
+// interface is in any of the given operational states (see LinkInfo).
+// Multiple states can be specified and separated by the `|` symbol (e.g.
+// "up|unknown").  Operational states are only known on Linux, so no IfAddrs
+// match on other platforms.
+func IfByOperState(selectorParam string, ifAddrs IfAddrs) (matched, remainder IfAddrs, err error) {
+	states := make(map[string]struct{})
+	for _, state := range strings.Split(strings.ToLower(selectorParam), "|") {
+		if state == "" {
+			return IfAddrs{}, IfAddrs{}, fmt.Errorf("empty operational state in %q", selectorParam)
+		}
+		states[state] = struct{}{}
+	}
+
+	matchedIfs := make(IfAddrs, 0, len(ifAddrs))
+	excludedIfs := make(IfAddrs, 0, len(ifAddrs))
+	for _, ifAddr := range ifAddrs {
+		if _, found := states[ifAddr.Link.OperState]; found {
+			matchedIfs = append(matchedIfs, ifAddr)
+		} else {
+			excludedIfs = append(excludedIfs, ifAddr)
+		}
+	}
+
+	return matchedIfs, excludedIfs, nil
+}
+
 // IfByName returns a list of matched and non-matched IfAddrs, or an error if
 // the regexp fails to compile.
 func IfByName(inputRe string, ifAddrs IfAddrs) (matched, remainder IfAddrs, err error) {
@@ -461,25 +689,35 @@ func IfByPort(inputRe string, ifAddrs IfAddrs) (matchedIfs, excludedIfs IfAddrs,
 }
 
 // IfByRFC returns a list of matched and non-matched IfAddrs that contain the
-// relevant RFC-specified traits.
+// relevant RFC-specified traits.  RFCs are looked up in the RFC registry (see
+// RegisterRFC).  The name of an address class (e.g. "private") may be used in
+// place of an RFC number (see IfByClass).  Multiple RFC numbers and class names
+// can be specified and separated by the `|` symbol (e.g. "1918|cgnat").
 func IfByRFC(selectorParam string, ifAddrs IfAddrs) (matched, remainder IfAddrs, err error) {
-	inputRFC, err := strconv.ParseUint(selectorParam, 10, 64)
-	if err != nil {
-		return IfAddrs{}, IfAddrs{}, fmt.Errorf("unable to parse RFC number %q: %v", selectorParam, err)
+	var networks SockAddrs
+	for _, rfcOrClass := range strings.Split(selectorParam, "|") {
+		inputRFC, err := strconv.ParseUint(rfcOrClass, 10, 64)
+		if err != nil {
+			classNets, found := classNetworks()[strings.ToLower(rfcOrClass)]
+			if !found {
+				return IfAddrs{}, IfAddrs{}, fmt.Errorf("unable to parse RFC number %q: %v", rfcOrClass, err)
+			}
+			networks = append(networks, classNets...)
+			continue
+		}
+
+		entry, ok := LookupRFC(uint(inputRFC))
+		if !ok {
+			return nil, nil, fmt.Errorf("unsupported RFC %d", inputRFC)
+		}
+		networks = append(networks, entry.Networks()...)
 	}
 
 	matchedIfAddrs := make(IfAddrs, 0, len(ifAddrs))
 	remainingIfAddrs := make(IfAddrs, 0, len(ifAddrs))
-
-	rfcNetMap := KnownRFCs()
-	rfcNets, ok := rfcNetMap[uint(inputRFC)]
-	if !ok {
-		return nil, nil, fmt.Errorf("unsupported RFC %d", inputRFC)
-	}
-
 	for _, ifAddr := range ifAddrs {
 		var contained bool
-		for _, rfcNet := range rfcNets {
+		for _, rfcNet := range networks {
 			if rfcNet.Contains(ifAddr.SockAddr) {
 				matchedIfAddrs = append(matchedIfAddrs, ifAddr)
 				contained = true
@@ -546,6 +784,92 @@ func IfByMaskSize(selectorParam string, ifAddrs IfAddrs) (matchedIfs, excludedIf
 	return matchedIfs, excludedIfs, nil
 }
 
+// IfByMulticastScope returns a list of matched and non-matched IfAddrs whose
+// multicast scope matches one of the "|" delimited scopes.  For instance:
+//
+// include "multicast_scope" "link|site"
+//
+// will include any link-local or site-local multicast groups.  Addresses that
+// are not multicast groups only match the scope "none".
+func IfByMulticastScope(selectorParam string, ifAddrs IfAddrs) (matched, remainder IfAddrs, err error) {
+	scopeStrs := strings.Split(selectorParam, "|")
+	scopes := make(map[MulticastScope]struct{}, len(scopeStrs))
+	for _, scopeStr := range scopeStrs {
+		scope, err := ParseMulticastScope(scopeStr)
+		if err != nil {
+			return IfAddrs{}, IfAddrs{}, fmt.Errorf("invalid multicast scope argument (%q): %v", selectorParam, err)
+		}
+		scopes[scope] = struct{}{}
+	}
+
+	matchedIfs := make(IfAddrs, 0, len(ifAddrs))
+	excludedIfs := make(IfAddrs, 0, len(ifAddrs))
+	for _, ifAddr := range ifAddrs {
+		if _, found := scopes[MulticastScopeOf(ifAddr.SockAddr)]; found {
+			matchedIfs = append(matchedIfs, ifAddr)
+		} else {
+			excludedIfs = append(excludedIfs, ifAddr)
+		}
+	}
+
+	return matchedIfs, excludedIfs, nil
+}
+
+// IfByClass returns a list of matched and non-matched IfAddrs that are part of
+// any of the named address classes (see KnownClasses).  Multiple classes can
+// be specified and separated by the `|` symbol (e.g. "private|cgnat").
+func IfByClass(selectorParam string, ifAddrs IfAddrs) (matched, remainder IfAddrs, err error) {
+	classNames := strings.Split(selectorParam, "|")
+	for _, className := range classNames {
+		if _, found := classNetworks()[strings.ToLower(className)]; !found {
+			return IfAddrs{}, IfAddrs{}, fmt.Errorf("unknown address class %q", className)
+		}
+	}
+
+	matchedIfs := make(IfAddrs, 0, len(ifAddrs))
+	excludedIfs := make(IfAddrs, 0, len(ifAddrs))
+	for _, ifAddr := range ifAddrs {
+		var contained bool
+		for _, className := range classNames {
+			if IsClass(className, ifAddr.SockAddr) {
+				contained = true
+				break
+			}
+		}
+
+		if contained {
+			matchedIfs = append(matchedIfs, ifAddr)
+		} else {
+			excludedIfs = append(excludedIfs, ifAddr)
+		}
+	}
+
+	return matchedIfs, excludedIfs, nil
+}
+
+// IfByTag returns a list of matched and non-matched IfAddrs that are tagged
+// with any of the labels (see LoadTagsFile).  Multiple labels can be specified
+// and separated by the `|` symbol.
+func IfByTag(selectorParam string, ifAddrs IfAddrs) (matched, remainder IfAddrs, err error) {
+	labels := make(map[string]struct{})
+	for _, label := range strings.Split(selectorParam, "|") {
+		labels[label] = struct{}{}
+	}
+
+	matchedIfs := make(IfAddrs, 0, len(ifAddrs))
+	excludedIfs := make(IfAddrs, 0, len(ifAddrs))
+	for _, ifAddr := range ifAddrs {
+		label, tagged := TagOf(ifAddr.SockAddr)
+		if _, found := labels[label]; tagged && found {
+			matchedIfs = append(matchedIfs, ifAddr)
+		} else {
+			excludedIfs = append(excludedIfs, ifAddr)
+		}
+	}
+
+	return matchedIfs, excludedIfs, nil
+}
+
 // IfByType returns a list of matching and non-matching IfAddr that match the
 // specified type.  For instance:
 //
@@ -601,11 +925,27 @@ func IfByType(inputTypes string, ifAddrs IfAddrs) (matched, remainder IfAddrs, e
 // will include any IfAddrs that have both the "up" and "broadcast" flags set.
 // Any addresses on those interfaces that don't match will be omitted from the
 // results.
+//
+// The address flags ("temporary", "deprecated", "tentative", "dadfailed",
+// "permanent", "dynamic" and "noprefixroute", see AddrFlags) match an IfAddr
+// that has any of the given address flags set, e.g.:
+//
+// exclude "flag" "temporary|deprecated|tentative"
+//
+// omits the IPv6 addresses that should not be advertised to other hosts.
+// Address flags are only known on Linux.
+//
+// A list that mixes address flags with the other flags matches an IfAddr only
+// if both parts do, so "up|temporary" matches the temporary addresses of the
+// interfaces that are up.
 func IfByFlag(inputFlags string, ifAddrs IfAddrs) (matched, remainder IfAddrs, err error) {
 	matchedAddrs := make(IfAddrs, 0, len(ifAddrs))
 	excludedAddrs := make(IfAddrs, 0, len(ifAddrs))
 
-	var wantForwardable,
+	var wantBogon,
+		wantDestination,
+		wantForwardable,
+		wantGlobal,
 		wantGlobalUnicast,
 		wantInterfaceLocalMulticast,
 		wantLinkLocalMulticast,
@@ -614,18 +954,34 @@ func IfByFlag(inputFlags string, ifAddrs IfAddrs) (matched, remainder IfAddrs, e
 		wantMulticast,
 		wantUnspecified bool
 	var ifFlags net.Flags
+	var addrFlags AddrFlags
 	var checkFlags, checkAttrs bool
 	for _, flagName := range strings.Split(strings.ToLower(inputFlags), "|") {
 		switch flagName {
+		case "bogon":
+			checkAttrs = true
+			wantBogon = true
 		case "broadcast":
 			checkFlags = true
 			ifFlags = ifFlags | net.FlagBroadcast
+		case "dadfailed":
+			addrFlags = addrFlags | AddrFlagDADFailed
+		case "deprecated":
+			addrFlags = addrFlags | AddrFlagDeprecated
+		case "destination":
+			checkAttrs = true
+			wantDestination = true
 		case "down":
 			checkFlags = true
 			ifFlags = (ifFlags &^ net.FlagUp)
+		case "dynamic":
+			addrFlags = addrFlags | AddrFlagDynamic
 		case "forwardable":
 			checkAttrs = true
 			wantForwardable = true
+		case "global":
+			checkAttrs = true
+			wantGlobal = true
 		case "global unicast":
 			checkAttrs = true
 			wantGlobalUnicast = true
@@ -648,9 +1004,17 @@ func IfByFlag(inputFlags string, ifAddrs IfAddrs) (matched, remainder IfAddrs, e
 			checkFlags = true
 			ifFlags = ifFlags | net.FlagMulticast
 			wantMulticast = true
+		case "noprefixroute":
+			addrFlags = addrFlags | AddrFlagNoPrefixRoute
+		case "permanent":
+			addrFlags = addrFlags | AddrFlagPermanent
 		case "point-to-point":
 			checkFlags = true
 			ifFlags = ifFlags | net.FlagPointToPoint
+		case "temporary":
+			addrFlags = addrFlags | AddrFlagTemporary
+		case "tentative":
+			addrFlags = addrFlags | AddrFlagTentative
 		case "unspecified":
 			checkAttrs = true
 			wantUnspecified = true
@@ -685,11 +1049,24 @@ func IfByFlag(inputFlags string, ifAddrs IfAddrs) (matched, remainder IfAddrs, e
 					matched = true
 				case wantUnspecified && netIP.IsUnspecified():
 					matched = true
-				case wantForwardable && !IsRFC(ForwardingBlacklist, ifAddr.SockAddr):
+				case wantForwardable && IsForwardable(ifAddr.SockAddr):
+					matched = true
+				case wantGlobal && IsGlobal(ifAddr.SockAddr):
+					matched = true
+				case wantDestination && IsDestination(ifAddr.SockAddr):
+					matched = true
+				case wantBogon && IsBogon(ifAddr.SockAddr):
 					matched = true
 				}
 			}
 		}
+		// Address flags narrow down the matches of the other flags.
+		if addrFlags != 0 {
+			if !checkFlags && !checkAttrs {
+				matched = true
+			}
+			matched = matched && ifAddr.AddrFlags&addrFlags != 0
+		}
 		if matched {
 			matchedAddrs = append(matchedAddrs, ifAddr)
 		} else {
@@ -699,6 +1076,32 @@ func IfByFlag(inputFlags string, ifAddrs IfAddrs) (matched, remainder IfAddrs, e
 	return matchedAddrs, excludedAddrs, nil
 }
 
+// IfByNeighborState returns a list of matched and non-matched IfAddrs whose
+// neighbor state matches one of the states in selectorParam.  Multiple states
+// are separated by "|".  Interface addresses, which have no neighbor state, are
+// never matched.
+func IfByNeighborState(selectorParam string, ifAddrs IfAddrs) (matched, remainder IfAddrs, err error) {
+	states := make(map[string]struct{})
+	for _, state := range strings.Split(strings.ToLower(selectorParam), "|") {
+		if state == "" {
+			return IfAddrs{}, IfAddrs{}, fmt.Errorf("empty neighbor state in %q", selectorParam)
+		}
+		states[state] = struct{}{}
+	}
+
+	matchedIfs := make(IfAddrs, 0, len(ifAddrs))
+	excludedIfs := make(IfAddrs, 0, len(ifAddrs))
+	for _, ifAddr := range ifAddrs {
+		if _, found := states[ifAddr.Neighbor.State]; found {
+			matchedIfs = append(matchedIfs, ifAddr)
+		} else {
+			excludedIfs = append(excludedIfs, ifAddr)
+		}
+	}
+
+	return matchedIfs, excludedIfs, nil
+}
+
 // IfByNetwork returns an IfAddrs that are equal to or included within the
 // network passed in by selector.
 func IfByNetwork(selectorParam string, inputIfAddrs IfAddrs) (IfAddrs, IfAddrs, error) {
@@ -737,18 +1140,19 @@ func IfAddrMath(operation, value string, inputIfAddr IfAddr) (IfAddr, error) {
 			return IfAddr{}, fmt.Errorf("sign (+/-) is required for operation %q", operation)
 		}
 
+		i, err := parseMathInt(value)
+		if err != nil {
+			return IfAddr{}, fmt.Errorf("unable to convert %q to int for operation %q: %v", value, operation, err)
+		}
+		if err := checkMathRange(i, inputIfAddr.SockAddr.Type()); err != nil {
+			return IfAddr{}, fmt.Errorf("unable to convert %q to int for operation %q: %v", value, operation, err)
+		}
+
 		switch sockType := inputIfAddr.SockAddr.Type(); sockType {
 		case TypeIPv4:
-			// 33 == Accept any uint32 value
-			// TODO(seanc@): Add the ability to parse hex
-			i, err := strconv.ParseInt(value, 10, 33)
-			if err != nil {
-				return IfAddr{}, fmt.Errorf("unable to convert %q to int for operation %q: %v", value, operation, err)
-			}
-
 			ipv4 := *ToIPv4Addr(inputIfAddr.SockAddr)
 			ipv4Uint32 := uint32(ipv4.Address)
-			ipv4Uint32 += uint32(i)
+			ipv4Uint32 += truncateUint32(i)
 			return IfAddr{
 				SockAddr: IPv4Addr{
 					Address: IPv4Address(ipv4Uint32),
@@ -757,19 +1161,11 @@ func IfAddrMath(operation, value string, inputIfAddr IfAddr) (IfAddr, error) {
 				Interface: inputIfAddr.Interface,
 			}, nil
 		case TypeIPv6:
-			// 64 == Accept any int32 value
-			// TODO(seanc@): Add the ability to parse hex.  Also parse a bignum int.
-			i, err := strconv.ParseInt(value, 10, 64)
-			if err != nil {
-				return IfAddr{}, fmt.Errorf("unable to convert %q to int for operation %q: %v", value, operation, err)
-			}
-
 			ipv6 := *ToIPv6Addr(inputIfAddr.SockAddr)
 			ipv6BigIntA := new(big.Int)
 			ipv6BigIntA.Set(ipv6.Address)
-			ipv6BigIntB := big.NewInt(i)
 
-			ipv6Addr := ipv6BigIntA.Add(ipv6BigIntA, ipv6BigIntB)
+			ipv6Addr := ipv6BigIntA.Add(ipv6BigIntA, i)
 			ipv6Addr.And(ipv6Addr, ipv6HostMask)
 
 			return IfAddr{
@@ -792,28 +1188,23 @@ func IfAddrMath(operation, value string, inputIfAddr IfAddr) (IfAddr, error) {
 			return IfAddr{}, fmt.Errorf("sign (+/-) is required for operation %q", operation)
 		}
 
+		i, err := parseMathInt(value)
+		if err != nil {
+			return IfAddr{}, fmt.Errorf("unable to convert %q to int for operation %q: %v", value, operation, err)
+		}
+		if err := checkMathRange(i, inputIfAddr.SockAddr.Type()); err != nil {
+			return IfAddr{}, fmt.Errorf("unable to convert %q to int for operation %q: %v", value, operation, err)
+		}
+
 		switch sockType := inputIfAddr.SockAddr.Type(); sockType {
 		case TypeIPv4:
-			// 33 == Accept any uint32 value
-			// TODO(seanc@): Add the ability to parse hex
-			i, err := strconv.ParseInt(value, 10, 33)
-			if err != nil {
-				return IfAddr{}, fmt.Errorf("unable to convert %q to int for operation %q: %v", value, operation, err)
-			}
-
 			ipv4 := *ToIPv4Addr(inputIfAddr.SockAddr)
 			ipv4Uint32 := uint32(ipv4.NetworkAddress())
 
-			// Wrap along network mask boundaries.  EZ-mode wrapping made possible by
-			// use of int64 vs a uint.
-			var wrappedMask int64
-			if i >= 0 {
-				wrappedMask = i
-			} else {
-				wrappedMask = 1 + i + int64(^uint32(ipv4.Mask))
-			}
-
-			ipv4Uint32 = ipv4Uint32 + (uint32(wrappedMask) &^ uint32(ipv4.Mask))
+			// Wrap along network mask boundaries.  Masking the two's complement
+			// representation of a negative value counts back from the
+			// broadcast address.
+			ipv4Uint32 = ipv4Uint32 + (truncateUint32(i) &^ uint32(ipv4.Mask))
 
 			return IfAddr{
 				SockAddr: IPv4Addr{
@@ -823,39 +1214,18 @@ func IfAddrMath(operation, value string, inputIfAddr IfAddr) (IfAddr, error) {
 				Interface: inputIfAddr.Interface,
 			}, nil
 		case TypeIPv6:
-			// 64 == Accept any int32 value
-			// TODO(seanc@): Add the ability to parse hex.  Also parse a bignum int.
-			i, err := strconv.ParseInt(value, 10, 64)
-			if err != nil {
-				return IfAddr{}, fmt.Errorf("unable to convert %q to int for operation %q: %v", value, operation, err)
-			}
-
 			ipv6 := *ToIPv6Addr(inputIfAddr.SockAddr)
 			ipv6BigInt := new(big.Int)
 			ipv6BigInt.Set(ipv6.NetworkAddress())
 
-			mask := new(big.Int)
-			mask.Set(ipv6.Mask)
-			if i > 0 {
-				wrappedMask := new(big.Int)
-				wrappedMask.SetInt64(i)
-
-				wrappedMask.AndNot(wrappedMask, mask)
-				ipv6BigInt.Add(ipv6BigInt, wrappedMask)
-			} else {
-				// Mask off any bits that exceed the network size.  Subtract the
-				// wrappedMask from the last usable - 1
-				wrappedMask := new(big.Int)
-				wrappedMask.SetInt64(-1 * i)
-				wrappedMask.Sub(wrappedMask, big.NewInt(1))
-
-				wrappedMask.AndNot(wrappedMask, mask)
+			// Mask off any bits that exceed the network size.  See the IPv4
+			// case for how negative values wrap.
+			hostMask := new(big.Int)
+			hostMask.Xor(ipv6HostMask, ipv6.Mask)
 
-				lastUsable := new(big.Int)
-				lastUsable.Set(ipv6.LastUsable().(IPv6Addr).Address)
-
-				ipv6BigInt = lastUsable.Sub(lastUsable, wrappedMask)
-			}
+			wrappedMask := new(big.Int)
+			wrappedMask.And(i, hostMask)
+			ipv6BigInt.Add(ipv6BigInt, wrappedMask)
 
 			return IfAddr{
 				SockAddr: IPv6Addr{
@@ -941,11 +1311,230 @@ func IfAddrMath(operation, value string, inputIfAddr IfAddr) (IfAddr, error) {
 		default:
 			return IfAddr{}, fmt.Errorf("unsupported type for operation %q: %T", operation, sockType)
 		}
+	case "port":
+		// "port" sets the port when the value is unsigned and adjusts the port
+		// when the value is signed.  The result must be a valid port number.
+		i, err := parseMathInt(value)
+		if err != nil {
+			return IfAddr{}, fmt.Errorf("unable to convert %q to int for operation %q: %v", value, operation, err)
+		}
+
+		ip := ToIPAddr(inputIfAddr.SockAddr)
+		if ip == nil {
+			return IfAddr{}, fmt.Errorf("unsupported type for operation %q: %T", operation, inputIfAddr.SockAddr.Type())
+		}
+
+		if signRe.MatchString(value) {
+			i.Add(i, big.NewInt(int64((*ip).IPPort())))
+		}
+
+		if i.Sign() < 0 || i.Cmp(big.NewInt(math.MaxUint16)) > 0 {
+			return IfAddr{}, fmt.Errorf("port %s out of range for operation %q", i, operation)
+		}
+
+		switch v := (*ip).(type) {
+		case IPv4Addr:
+			v.Port = IPPort(i.Uint64())
+			return IfAddr{SockAddr: v, Interface: inputIfAddr.Interface}, nil
+		case IPv6Addr:
+			v.Port = IPPort(i.Uint64())
+			return IfAddr{SockAddr: v, Interface: inputIfAddr.Interface}, nil
+		default:
+			return IfAddr{}, fmt.Errorf("unsupported type for operation %q: %T", operation, v)
+		}
+	case "prefix":
+		// "prefix" replaces the prefix length without masking the address
+		// (e.g. 10.1.2.3/8 "prefix" "24" returns 10.1.2.3/24).  Signed values
+		// adjust the current prefix length.
+		i, err := parseMathInt(value)
+		if err != nil {
+			return IfAddr{}, fmt.Errorf("unable to convert %q to int for operation %q: %v", value, operation, err)
+		}
+
+		ip := ToIPAddr(inputIfAddr.SockAddr)
+		if ip == nil {
+			return IfAddr{}, fmt.Errorf("unsupported type for operation %q: %T", operation, inputIfAddr.SockAddr.Type())
+		}
+
+		if signRe.MatchString(value) {
+			i.Add(i, big.NewInt(int64((*ip).Maskbits())))
+		}
+
+		bits := ipAddrBits(*ip)
+		if i.Sign() < 0 || i.Cmp(big.NewInt(int64(bits))) > 0 {
+			return IfAddr{}, fmt.Errorf("parameter for operation %q on %s addresses must be between 0 and %d", operation, (*ip).Type(), bits)
+		}
+
+		switch v := (*ip).(type) {
+		case IPv4Addr:
+			v.Mask = IPv4Mask(binary.BigEndian.Uint32(net.CIDRMask(int(i.Int64()), bits)))
+			return IfAddr{SockAddr: v, Interface: inputIfAddr.Interface}, nil
+		case IPv6Addr:
+			v.Mask = IPv6Mask(new(big.Int).SetBytes(net.CIDRMask(int(i.Int64()), bits)))
+			return IfAddr{SockAddr: v, Interface: inputIfAddr.Interface}, nil
+		default:
+			return IfAddr{}, fmt.Errorf("unsupported type for operation %q: %T", operation, v)
+		}
+	case "and", "or", "xor":
+		// "and", "or", and "xor" apply a bitwise operation to the address.
+		// The operand is either an address of the same family (e.g.
+		// "0.0.0.255" or "::ffff") or an integer.  The mask and port are
+		// preserved.
+		ip := ToIPAddr(inputIfAddr.SockAddr)
+		if ip == nil {
+			return IfAddr{}, fmt.Errorf("unsupported type for operation %q: %T", operation, inputIfAddr.SockAddr.Type())
+		}
+
+		operand, err := parseMathOperand(value, (*ip).Type())
+		if err != nil {
+			return IfAddr{}, fmt.Errorf("unable to convert %q to an operand for operation %q: %v", value, operation, err)
+		}
+
+		addr, _ := ipAddrRange((*ip).Host())
+		switch strings.ToLower(operation) {
+		case "and":
+			addr.And(addr, operand)
+		case "or":
+			addr.Or(addr, operand)
+		case "xor":
+			addr.Xor(addr, operand)
+		}
+
+		switch v := (*ip).(type) {
+		case IPv4Addr:
+			v.Address = IPv4Address(truncateUint32(addr))
+			return IfAddr{SockAddr: v, Interface: inputIfAddr.Interface}, nil
+		case IPv6Addr:
+			v.Address = IPv6Address(addr.And(addr, ipv6HostMask))
+			return IfAddr{SockAddr: v, Interface: inputIfAddr.Interface}, nil
+		default:
+			return IfAddr{}, fmt.Errorf("unsupported type for operation %q: %T", operation, v)
+		}
+	case "subnet":
+		// "subnet" selects the Nth child network of a given prefix length.
+		// The value is the prefix length and index separated by a colon
+		// (e.g. 10.0.0.0/16 "subnet" "24:3" returns 10.0.3.0/24).  Negative
+		// indexes count back from the last child network.
+		ip := ToIPAddr(inputIfAddr.SockAddr)
+		if ip == nil {
+			return IfAddr{}, fmt.Errorf("unsupported type for operation %q: %T", operation, inputIfAddr.SockAddr.Type())
+		}
+
+		parts := strings.SplitN(value, ":", 2)
+		if len(parts) != 2 {
+			return IfAddr{}, fmt.Errorf("value for operation %q must be in the form <prefix length>:<index>", operation)
+		}
+
+		prefixLen, err := strconv.ParseUint(strings.TrimSpace(parts[0]), 10, 8)
+		if err != nil {
+			return IfAddr{}, fmt.Errorf("unable to convert %q to a prefix length for operation %q: %v", parts[0], operation, err)
+		}
+
+		bits := ipAddrBits(*ip)
+		maskBits := (*ip).Maskbits()
+		if int(prefixLen) < maskBits || int(prefixLen) > bits {
+			return IfAddr{}, fmt.Errorf("prefix length for operation %q on %s must be between %d and %d", operation, *ip, maskBits, bits)
+		}
+
+		index, err := parseMathInt(parts[1])
+		if err != nil {
+			return IfAddr{}, fmt.Errorf("unable to convert %q to int for operation %q: %v", parts[1], operation, err)
+		}
+
+		numSubnets := new(big.Int).Lsh(big.NewInt(1), uint(int(prefixLen)-maskBits))
+		if index.Sign() < 0 {
+			index.Add(index, numSubnets)
+		}
+		if index.Sign() < 0 || index.Cmp(numSubnets) >= 0 {
+			return IfAddr{}, fmt.Errorf("index %s out of range for operation %q: %s has %s /%d subnets", parts[1], operation, *ip, numSubnets, prefixLen)
+		}
+
+		network, _ := ipAddrRange(*ip)
+		network.Add(network, index.Lsh(index, uint(bits-int(prefixLen))))
+
+		return IfAddr{
+			SockAddr:  newIPAddrFromBigInt((*ip).Type(), network, int(prefixLen)),
+			Interface: inputIfAddr.Interface,
+		}, nil
 	default:
 		return IfAddr{}, fmt.Errorf("unsupported math operation: %q", operation)
 	}
 }
 
+// parseMathInt parses an integer operand for IfAddrMath.  Operands may be
+// signed and arbitrarily large.  They are decimal unless prefixed with 0x for
+// hex values.
+func parseMathInt(value string) (*big.Int, error) {
+	digits := strings.TrimSpace(value)
+	var sign string
+	if len(digits) > 0 && (digits[0] == '+' || digits[0] == '-') {
+		sign, digits = digits[:1], digits[1:]
+	}
+
+	base := 10
+	if len(digits) > 2 && (digits[:2] == "0x" || digits[:2] == "0X") {
+		base, digits = 16, digits[2:]
+	}
+
+	i, ok := new(big.Int).SetString(sign+digits, base)
+	if !ok {
+		return nil, fmt.Errorf("invalid integer %q", value)
+	}
+
+	return i, nil
+}
+
+// checkMathRange returns an error if i does not fit in the address width of
+// sockType, i.e. if i is not within -2^bits and 2^bits-1.  Other types are
+// not checked.
+func checkMathRange(i *big.Int, sockType SockAddrType) error {
+	var bits uint
+	switch sockType {
+	case TypeIPv4:
+		bits = IPv4len * 8
+	case TypeIPv6:
+		bits = IPv6len * 8
+	default:
+		return nil
+	}
+
+	limit := new(big.Int).Lsh(big.NewInt(1), bits)
+	if i.Cmp(limit) >= 0 || i.Cmp(limit.Neg(limit)) < 0 {
+		return fmt.Errorf("%s out of range for %s addresses", i, sockType)
+	}
+	return nil
+}
+
+// parseMathOperand parses an address-valued operand for IfAddrMath.  The
+// operand is either an integer (see parseMathInt) or an address of the given
+// type.
+func parseMathOperand(value string, sockType SockAddrType) (*big.Int, error) {
+	if i, err := parseMathInt(value); err == nil {
+		if err := checkMathRange(i, sockType); err != nil {
+			return nil, err
+		}
+		return i, nil
+	}
+
+	ip, err := NewIPAddr(strings.TrimSpace(value))
+	if err != nil {
+		return nil, err
+	}
+
+	if ip.Type() != sockType {
+		return nil, fmt.Errorf("operand %q is not an %s address", value, sockType)
+	}
+
+	addr, _ := ipAddrRange(ip.Host())
+	return addr, nil
+}
+
+// truncateUint32 returns the low 32 bits of the two's complement
+// representation of i.
+func truncateUint32(i *big.Int) uint32 {
+	return uint32(new(big.Int).And(i, big.NewInt(math.MaxUint32)).Uint64())
+}
+
 // IfAddrsMath will apply an IfAddrMath operation each IfAddr struct.  Any
 // failure will result in zero results.
 func IfAddrsMath(operation, value string, inputIfAddrs IfAddrs) (IfAddrs, error) {
@@ -968,18 +1557,30 @@ func IncludeIfs(selectorName, selectorParam string, inputIfAddrs IfAddrs) (IfAdd
 	switch strings.ToLower(selectorName) {
 	case "address":
 		includedIfs, _, err = IfByAddress(selectorParam, inputIfAddrs)
+	case "class":
+		includedIfs, _, err = IfByClass(selectorParam, inputIfAddrs)
 	case "flag", "flags":
 		includedIfs, _, err = IfByFlag(selectorParam, inputIfAddrs)
+	case "kind":
+		includedIfs, _, err = IfByKind(selectorParam, inputIfAddrs)
+	case "multicast_scope":
+		includedIfs, _, err = IfByMulticastScope(selectorParam, inputIfAddrs)
 	case "name":
 		includedIfs, _, err = IfByName(selectorParam, inputIfAddrs)
 	case "network":
 		includedIfs, _, err = IfByNetwork(selectorParam, inputIfAddrs)
+	case "neighbor_state":
+		includedIfs, _, err = IfByNeighborState(selectorParam, inputIfAddrs)
+	case "operstate":
+		includedIfs, _, err = IfByOperState(selectorParam, inputIfAddrs)
 	case "port":
 		includedIfs, _, err = IfByPort(selectorParam, inputIfAddrs)
 	case "rfc", "rfcs":
 		includedIfs, _, err = IfByRFCs(selectorParam, inputIfAddrs)
 	case "size":
 		includedIfs, _, err = IfByMaskSize(selectorParam, inputIfAddrs)
+	case "tag":
+		includedIfs, _, err = IfByTag(selectorParam, inputIfAddrs)
 	case "type":
 		includedIfs, _, err = IfByType(selectorParam, inputIfAddrs)
 	default:
@@ -1001,18 +1602,30 @@ func ExcludeIfs(selectorName, selectorParam string, inputIfAddrs IfAddrs) (IfAdd
 	switch strings.ToLower(selectorName) {
 	case "address":
 		_, excludedIfs, err = IfByAddress(selectorParam, inputIfAddrs)
+	case "class":
+		_, excludedIfs, err = IfByClass(selectorParam, inputIfAddrs)
 	case "flag", "flags":
 		_, excludedIfs, err = IfByFlag(selectorParam, inputIfAddrs)
+	case "kind":
+		_, excludedIfs, err = IfByKind(selectorParam, inputIfAddrs)
+	case "multicast_scope":
+		_, excludedIfs, err = IfByMulticastScope(selectorParam, inputIfAddrs)
 	case "name":
 		_, excludedIfs, err = IfByName(selectorParam, inputIfAddrs)
 	case "network":
 		_, excludedIfs, err = IfByNetwork(selectorParam, inputIfAddrs)
+	case "neighbor_state":
+		_, excludedIfs, err = IfByNeighborState(selectorParam, inputIfAddrs)
+	case "operstate":
+		_, excludedIfs, err = IfByOperState(selectorParam, inputIfAddrs)
 	case "port":
 		_, excludedIfs, err = IfByPort(selectorParam, inputIfAddrs)
 	case "rfc", "rfcs":
 		_, excludedIfs, err = IfByRFCs(selectorParam, inputIfAddrs)
 	case "size":
 		_, excludedIfs, err = IfByMaskSize(selectorParam, inputIfAddrs)
+	case "tag":
+		_, excludedIfs, err = IfByTag(selectorParam, inputIfAddrs)
 	case "type":
 		_, excludedIfs, err = IfByType(selectorParam, inputIfAddrs)
 	default:
@@ -1070,6 +1683,15 @@ func SortIfBy(selectorParam string, inputIfAddrs IfAddrs) (IfAddrs, error) {
 			sortFuncs[i] = AscIfPrivate
 		case "-private":
 			sortFuncs[i] = DescIfPrivate
+		case "+rfc6724", "rfc6724":
+			// The "rfc6724" selector returns an array of IfAddrs
+			// ordered by their RFC 6724 scope and then by their
+			// precedence in the policy table (e.g. global IPv6
+			// addresses before IPv4 addresses before link-local
+			// addresses).
+			sortFuncs[i] = AscIfPolicyPrecedence
+		case "-rfc6724":
+			sortFuncs[i] = DescIfPolicyPrecedence
 		case "+size", "size":
 			// The "size" selector returns an array of IfAddrs
 			// ordered by the size of the network mask, smaller mask
@@ -1078,6 +1700,14 @@ func SortIfBy(selectorParam string, inputIfAddrs IfAddrs) (IfAddrs, error) {
 			sortFuncs[i] = AscIfNetworkSize
 		case "-size":
 			sortFuncs[i] = DescIfNetworkSize
+		case "+speed", "speed":
+			// The "speed" selector returns an array of IfAddrs
+			// ordered by the speed of their interface, slowest
+			// first.  Use "-speed" to order the fastest interface
+			// first.
+			sortFuncs[i] = AscIfSpeed
+		case "-speed":
+			sortFuncs[i] = DescIfSpeed
 		case "+type", "type":
 			// The "type" selector returns an array of IfAddrs
 			// ordered by the type of the IfAddr.  The sort order is
@@ -1195,19 +1825,18 @@ func parseDefaultIfNameFromRoute(routeOut string) (string, error) {
 }
 
 // parseDefaultIfNameFromIPCmd parses the default interface from ip(8) for
-// Linux.
+// Linux.  The default route with the lowest metric is used.
 func parseDefaultIfNameFromIPCmd(routeOut string) (string, error) {
-	parsedLines := parseIfNameFromIPCmd(routeOut)
-	for _, parsedLine := range parsedLines {
-		if parsedLine[0] == "default" &&
-			parsedLine[1] == "via" &&
-			parsedLine[3] == "dev" {
-			ifName := strings.TrimSpace(parsedLine[4])
-			return ifName, nil
-		}
+	routes, err := parseIPRouteCmd(routeOut, TypeIPv4)
+	if err != nil {
+		return "", errors.New("No default interface found")
 	}
 
-	return "", errors.New("No default interface found")
+	defaults := defaultRoutes(routes, TypeIPv4)
+	if len(defaults) == 0 {
+		return "", errors.New("No default interface found")
+	}
+	return defaults[0].IfName, nil
 }
 
 // parseDefaultIfNameFromIPCmdAndroid parses the default interface from ip(8) for
@@ -6,6 +6,7 @@ import (
 	"reflect"
 	"runtime"
 	"testing"
+	"time"
 
 	sockaddr "github.com/hashicorp/go-sockaddr"
 )
@@ -365,6 +366,39 @@ func TestFilterIfByFlags(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:     "temporary",
+			selector: "temporary",
+			ifAddrs: sockaddr.IfAddrs{
+				sockaddr.IfAddr{
+					Interface: net.Interface{},
+					SockAddr:  sockaddr.MustIPv6Addr("2001:db8::cafe/64"),
+					AddrFlags: sockaddr.AddrFlagTemporary | sockaddr.AddrFlagDynamic,
+				},
+			},
+		},
+		{
+			name:     "temporary|deprecated|tentative",
+			selector: "temporary|deprecated|tentative",
+			ifAddrs: sockaddr.IfAddrs{
+				sockaddr.IfAddr{
+					Interface: net.Interface{},
+					SockAddr:  sockaddr.MustIPv6Addr("2001:db8::beef/64"),
+					AddrFlags: sockaddr.AddrFlagDeprecated | sockaddr.AddrFlagDynamic,
+				},
+			},
+		},
+		{
+			name:     "dadfailed",
+			selector: "dadfailed",
+			ifAddrs: sockaddr.IfAddrs{
+				sockaddr.IfAddr{
+					Interface: net.Interface{},
+					SockAddr:  sockaddr.MustIPv6Addr("2001:db8::1/64"),
+					AddrFlags: sockaddr.AddrFlagDADFailed | sockaddr.AddrFlagTentative | sockaddr.AddrFlagPermanent,
+				},
+			},
+		},
 		{
 			name:     "invalid",
 			selector: "foo",
@@ -404,6 +438,225 @@ func TestFilterIfByFlags(t *testing.T) {
 	}
 }
 
+func TestIfByKind(t *testing.T) {
+	ifAddrs := sockaddr.IfAddrs{
+		{
+			SockAddr:  sockaddr.MustIPv4Addr("10.0.0.5/24"),
+			Interface: net.Interface{Name: "bond0"},
+			Link:      sockaddr.LinkInfo{Kind: sockaddr.KindBond},
+		},
+		{
+			SockAddr:  sockaddr.MustIPv4Addr("172.17.0.1/16"),
+			Interface: net.Interface{Name: "docker0"},
+			Link:      sockaddr.LinkInfo{Kind: sockaddr.KindBridge},
+		},
+		{
+			SockAddr:  sockaddr.MustIPv6Addr("fe80::1/64"),
+			Interface: net.Interface{Name: "veth1a2b3c"},
+			Link:      sockaddr.LinkInfo{Kind: sockaddr.KindVeth, Master: "docker0"},
+		},
+		{
+			SockAddr:  sockaddr.MustIPv4Addr("192.168.1.5/24"),
+			Interface: net.Interface{Name: "eno1"},
+			Link:      sockaddr.LinkInfo{Kind: sockaddr.KindPhysical},
+		},
+		{
+			SockAddr:  sockaddr.MustIPv4Addr("192.0.2.1/24"),
+			Interface: net.Interface{Name: "en0"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		selector string
+		matched  string
+		excluded string
+		fail     bool
+	}{
+		{
+			name:     "physical|bond",
+			selector: "physical|bond",
+			matched:  "bond0 eno1",
+			excluded: "docker0 veth1a2b3c en0",
+		},
+		{
+			name:     "case insensitive",
+			selector: "VETH",
+			matched:  "veth1a2b3c",
+			excluded: "bond0 docker0 eno1 en0",
+		},
+		{
+			name:     "empty kind",
+			selector: "bridge|",
+			fail:     true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			matched, excluded, err := sockaddr.IfByKind(test.selector, ifAddrs)
+			if test.fail {
+				if err == nil {
+					t.Fatalf("expected %q to fail", test.selector)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if got, _ := sockaddr.JoinIfAddrs("name", " ", matched); got != test.matched {
+				t.Errorf("want matched %q, got %q", test.matched, got)
+			}
+			if got, _ := sockaddr.JoinIfAddrs("name", " ", excluded); got != test.excluded {
+				t.Errorf("want excluded %q, got %q", test.excluded, got)
+			}
+		})
+	}
+}
+
+func TestIfByOperState(t *testing.T) {
+	ifAddrs := sockaddr.IfAddrs{
+		{
+			SockAddr:  sockaddr.MustIPv4Addr("10.0.0.5/24"),
+			Interface: net.Interface{Name: "eth0", Flags: net.FlagUp},
+			Link:      sockaddr.LinkInfo{OperState: "up", Carrier: true},
+		},
+		{
+			// Administratively up, but without a carrier.
+			SockAddr:  sockaddr.MustIPv4Addr("192.168.1.5/24"),
+			Interface: net.Interface{Name: "eth1", Flags: net.FlagUp},
+			Link:      sockaddr.LinkInfo{OperState: "lowerlayerdown"},
+		},
+		{
+			SockAddr:  sockaddr.MustIPv4Addr("127.0.0.1/8"),
+			Interface: net.Interface{Name: "lo", Flags: net.FlagUp | net.FlagLoopback},
+			Link:      sockaddr.LinkInfo{OperState: "unknown"},
+		},
+		{
+			SockAddr:  sockaddr.MustIPv4Addr("192.0.2.1/24"),
+			Interface: net.Interface{Name: "en0", Flags: net.FlagUp},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		selector string
+		matched  string
+		excluded string
+		fail     bool
+	}{
+		{
+			name:     "up",
+			selector: "up",
+			matched:  "eth0",
+			excluded: "eth1 lo en0",
+		},
+		{
+			name:     "up|UNKNOWN",
+			selector: "up|UNKNOWN",
+			matched:  "eth0 lo",
+			excluded: "eth1 en0",
+		},
+		{
+			name:     "empty state",
+			selector: "|down",
+			fail:     true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			matched, excluded, err := sockaddr.IfByOperState(test.selector, ifAddrs)
+			if test.fail {
+				if err == nil {
+					t.Fatalf("expected %q to fail", test.selector)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if got, _ := sockaddr.JoinIfAddrs("name", " ", matched); got != test.matched {
+				t.Errorf("want matched %q, got %q", test.matched, got)
+			}
+			if got, _ := sockaddr.JoinIfAddrs("name", " ", excluded); got != test.excluded {
+				t.Errorf("want excluded %q, got %q", test.excluded, got)
+			}
+		})
+	}
+}
+
+func TestIfByNeighborState(t *testing.T) {
+	ifAddrs := sockaddr.IfAddrs{
+		{
+			SockAddr: sockaddr.MustIPv4Addr("10.0.0.1"),
+			Neighbor: sockaddr.NeighborInfo{State: sockaddr.NeighborStateReachable},
+		},
+		{
+			SockAddr: sockaddr.MustIPv4Addr("10.0.0.7"),
+			Neighbor: sockaddr.NeighborInfo{State: sockaddr.NeighborStateStale},
+		},
+		{
+			SockAddr: sockaddr.MustIPv4Addr("10.0.0.9"),
+			Neighbor: sockaddr.NeighborInfo{State: sockaddr.NeighborStateFailed},
+		},
+		{
+			// An interface address has no neighbor state.
+			SockAddr: sockaddr.MustIPv4Addr("10.0.0.5/24"),
+		},
+	}
+
+	tests := []struct {
+		name     string
+		selector string
+		matched  string
+		excluded string
+		fail     bool
+	}{
+		{
+			name:     "reachable|STALE",
+			selector: "reachable|STALE",
+			matched:  "10.0.0.1 10.0.0.7",
+			excluded: "10.0.0.9 10.0.0.5",
+		},
+		{
+			name:     "failed",
+			selector: "failed",
+			matched:  "10.0.0.9",
+			excluded: "10.0.0.1 10.0.0.7 10.0.0.5",
+		},
+		{
+			name:     "empty state",
+			selector: "reachable||stale",
+			fail:     true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			matched, excluded, err := sockaddr.IfByNeighborState(test.selector, ifAddrs)
+			if test.fail {
+				if err == nil {
+					t.Fatalf("expected %q to fail", test.selector)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if got, _ := sockaddr.JoinIfAddrs("address", " ", matched); got != test.matched {
+				t.Errorf("want matched %q, got %q", test.matched, got)
+			}
+			if got, _ := sockaddr.JoinIfAddrs("address", " ", excluded); got != test.excluded {
+				t.Errorf("want excluded %q, got %q", test.excluded, got)
+			}
+		})
+	}
+}
+
 func TestIfByNetwork(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -699,7 +952,7 @@ func TestGetDefaultInterface(t *testing.T) {
 }
 
 func TestIfAddrAttrs(t *testing.T) {
-	const expectedNumAttrs = 2
+	const expectedNumAttrs = 19
 	attrs := sockaddr.IfAddrAttrs()
 	if len(attrs) != expectedNumAttrs {
 		t.Fatalf("wrong number of attrs")
@@ -721,6 +974,186 @@ func TestIfAddrAttrs(t *testing.T) {
 			attr:     "name",
 			expected: "abc0",
 		},
+		{
+			name: "gateway without a default route",
+			ifAddr: sockaddr.IfAddr{
+				SockAddr: sockaddr.MustIPv4Addr("198.51.100.7/24"),
+				Interface: net.Interface{
+					Name: "abc0",
+				},
+			},
+			attr:     "gateway",
+			expected: "",
+		},
+		{
+			name: "address_flags",
+			ifAddr: sockaddr.IfAddr{
+				SockAddr:  sockaddr.MustIPv6Addr("2001:db8::cafe/64"),
+				AddrFlags: sockaddr.AddrFlagTemporary | sockaddr.AddrFlagDynamic,
+			},
+			attr:     "address_flags",
+			expected: "temporary|dynamic",
+		},
+		{
+			name: "preferred_lifetime",
+			ifAddr: sockaddr.IfAddr{
+				SockAddr:          sockaddr.MustIPv6Addr("2001:db8::cafe/64"),
+				AddrFlags:         sockaddr.AddrFlagDeprecated | sockaddr.AddrFlagDynamic,
+				PreferredLifetime: 0,
+				ValidLifetime:     90 * time.Second,
+			},
+			attr:     "preferred_lifetime",
+			expected: "0",
+		},
+		{
+			name: "valid_lifetime forever",
+			ifAddr: sockaddr.IfAddr{
+				SockAddr:          sockaddr.MustIPv6Addr("2001:db8::cafe/64"),
+				AddrFlags:         sockaddr.AddrFlagPermanent,
+				PreferredLifetime: sockaddr.LifetimeForever,
+				ValidLifetime:     sockaddr.LifetimeForever,
+			},
+			attr:     "valid_lifetime",
+			expected: "forever",
+		},
+		{
+			name: "kind",
+			ifAddr: sockaddr.IfAddr{
+				SockAddr: sockaddr.MustIPv4Addr("10.0.0.5/24"),
+				Link:     sockaddr.LinkInfo{Kind: sockaddr.KindVLAN, Master: "br0", VLANID: 42},
+			},
+			attr:     "kind",
+			expected: "vlan",
+		},
+		{
+			name: "master",
+			ifAddr: sockaddr.IfAddr{
+				SockAddr: sockaddr.MustIPv4Addr("10.0.0.5/24"),
+				Link:     sockaddr.LinkInfo{Kind: sockaddr.KindVLAN, Master: "br0", VLANID: 42},
+			},
+			attr:     "master",
+			expected: "br0",
+		},
+		{
+			name: "vlan_id",
+			ifAddr: sockaddr.IfAddr{
+				SockAddr: sockaddr.MustIPv4Addr("10.0.0.5/24"),
+				Link:     sockaddr.LinkInfo{Kind: sockaddr.KindVLAN, Master: "br0", VLANID: 42},
+			},
+			attr:     "vlan_id",
+			expected: "42",
+		},
+		{
+			name: "vlan_id of a physical interface",
+			ifAddr: sockaddr.IfAddr{
+				SockAddr: sockaddr.MustIPv4Addr("10.0.0.5/24"),
+				Link:     sockaddr.LinkInfo{Kind: sockaddr.KindPhysical},
+			},
+			attr:     "vlan_id",
+			expected: "",
+		},
+		{
+			name: "operstate",
+			ifAddr: sockaddr.IfAddr{
+				SockAddr: sockaddr.MustIPv4Addr("10.0.0.5/24"),
+				Link:     sockaddr.LinkInfo{OperState: "lowerlayerdown"},
+			},
+			attr:     "operstate",
+			expected: "lowerlayerdown",
+		},
+		{
+			name: "carrier",
+			ifAddr: sockaddr.IfAddr{
+				SockAddr: sockaddr.MustIPv4Addr("10.0.0.5/24"),
+				Link:     sockaddr.LinkInfo{OperState: "down"},
+			},
+			attr:     "carrier",
+			expected: "false",
+		},
+		{
+			name: "carrier unknown",
+			ifAddr: sockaddr.IfAddr{
+				SockAddr: sockaddr.MustIPv4Addr("10.0.0.5/24"),
+			},
+			attr:     "carrier",
+			expected: "",
+		},
+		{
+			name: "speed_mbps",
+			ifAddr: sockaddr.IfAddr{
+				SockAddr: sockaddr.MustIPv4Addr("10.0.0.5/24"),
+				Link:     sockaddr.LinkInfo{OperState: "up", Carrier: true, SpeedMbps: 25000, Duplex: "full"},
+			},
+			attr:     "speed_mbps",
+			expected: "25000",
+		},
+		{
+			name: "duplex",
+			ifAddr: sockaddr.IfAddr{
+				SockAddr: sockaddr.MustIPv4Addr("10.0.0.5/24"),
+				Link:     sockaddr.LinkInfo{OperState: "up", Carrier: true, SpeedMbps: 25000, Duplex: "full"},
+			},
+			attr:     "duplex",
+			expected: "full",
+		},
+		{
+			name: "rx_bytes",
+			ifAddr: sockaddr.IfAddr{
+				SockAddr: sockaddr.MustIPv4Addr("10.0.0.5/24"),
+				Link:     sockaddr.LinkInfo{OperState: "up", Stats: sockaddr.LinkStats{RxBytes: 1453269, TxErrors: 2}},
+			},
+			attr:     "rx_bytes",
+			expected: "1453269",
+		},
+		{
+			name: "tx_errors",
+			ifAddr: sockaddr.IfAddr{
+				SockAddr: sockaddr.MustIPv4Addr("10.0.0.5/24"),
+				Link:     sockaddr.LinkInfo{OperState: "up", Stats: sockaddr.LinkStats{RxBytes: 1453269, TxErrors: 2}},
+			},
+			attr:     "tx_errors",
+			expected: "2",
+		},
+		{
+			name: "lladdr",
+			ifAddr: sockaddr.IfAddr{
+				SockAddr: sockaddr.MustIPv4Addr("10.0.0.1"),
+				Neighbor: sockaddr.NeighborInfo{
+					HardwareAddr: net.HardwareAddr{0x02, 0x42, 0x0a, 0, 0, 0x01},
+					State:        sockaddr.NeighborStateStale,
+				},
+			},
+			attr:     "lladdr",
+			expected: "02:42:0a:00:00:01",
+		},
+		{
+			name: "neighbor_state",
+			ifAddr: sockaddr.IfAddr{
+				SockAddr: sockaddr.MustIPv4Addr("10.0.0.1"),
+				Neighbor: sockaddr.NeighborInfo{
+					HardwareAddr: net.HardwareAddr{0x02, 0x42, 0x0a, 0, 0, 0x01},
+					State:        sockaddr.NeighborStateStale,
+				},
+			},
+			attr:     "neighbor_state",
+			expected: "stale",
+		},
+		{
+			name: "lladdr of an interface address",
+			ifAddr: sockaddr.IfAddr{
+				SockAddr: sockaddr.MustIPv4Addr("10.0.0.5/24"),
+			},
+			attr:     "lladdr",
+			expected: "",
+		},
+		{
+			name: "valid_lifetime unknown",
+			ifAddr: sockaddr.IfAddr{
+				SockAddr: sockaddr.MustIPv6Addr("2001:db8::cafe/64"),
+			},
+			attr:     "valid_lifetime",
+			expected: "",
+		},
 	}
 
 	for i, test := range tests {
@@ -805,6 +1238,35 @@ func TestGetDefaultInterfaces(t *testing.T) {
 	}
 }
 
+func TestGetDefaultInterfacesByType(t *testing.T) {
+	tests := []struct {
+		name     string
+		fn       func() (sockaddr.IfAddrs, error)
+		sockType sockaddr.SockAddrType
+	}{
+		{name: "IPv4", fn: sockaddr.GetDefaultInterfacesV4, sockType: sockaddr.TypeIPv4},
+		{name: "IPv6", fn: sockaddr.GetDefaultInterfacesV6, sockType: sockaddr.TypeIPv6},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ifAddrs, err := test.fn()
+			if err != nil {
+				t.Skipf("unable to gather default interfaces: %v", err)
+			}
+
+			for _, ifAddr := range ifAddrs {
+				if ifAddr.SockAddr.Type() != test.sockType {
+					t.Errorf("expected only %s addresses, got %v", test.sockType, ifAddr)
+				}
+				if ifAddr.Name != ifAddrs[0].Name {
+					t.Errorf("expected a single interface, got %s and %s", ifAddrs[0].Name, ifAddr.Name)
+				}
+			}
+		})
+	}
+}
+
 func TestGetPrivateInterfaces(t *testing.T) {
 	reportOnPrivate := func(args ...interface{}) {
 		if havePrivateIP() {
@@ -1335,7 +1797,7 @@ func TestNewIPAddr(t *testing.T) {
 }
 
 func TestIPAttrs(t *testing.T) {
-	const expectedIPAttrs = 11
+	const expectedIPAttrs = 15
 	ipAttrs := sockaddr.IPAttrs()
 	if len(ipAttrs) != expectedIPAttrs {
 		t.Fatalf("wrong number of args")
@@ -1926,6 +2388,60 @@ func TestSortIfBy(t *testing.T) {
 				sockaddr.IfAddr{SockAddr: sockaddr.MustIPv4Addr("192.168.1.1/27")},
 			},
 		},
+		{
+			name:    "sort speed",
+			sortStr: "speed",
+			in: sockaddr.IfAddrs{
+				sockaddr.IfAddr{
+					SockAddr: sockaddr.MustIPv4Addr("10.0.0.5/24"),
+					Link:     sockaddr.LinkInfo{SpeedMbps: 25000},
+				},
+				sockaddr.IfAddr{
+					SockAddr: sockaddr.MustIPv4Addr("192.168.1.5/24"),
+					Link:     sockaddr.LinkInfo{SpeedMbps: 1000},
+				},
+			},
+			out: sockaddr.IfAddrs{
+				sockaddr.IfAddr{
+					SockAddr: sockaddr.MustIPv4Addr("192.168.1.5/24"),
+					Link:     sockaddr.LinkInfo{SpeedMbps: 1000},
+				},
+				sockaddr.IfAddr{
+					SockAddr: sockaddr.MustIPv4Addr("10.0.0.5/24"),
+					Link:     sockaddr.LinkInfo{SpeedMbps: 25000},
+				},
+			},
+		},
+		{
+			name:    "sort -speed",
+			sortStr: "-speed",
+			in: sockaddr.IfAddrs{
+				sockaddr.IfAddr{
+					SockAddr: sockaddr.MustIPv4Addr("192.168.1.5/24"),
+					Link:     sockaddr.LinkInfo{SpeedMbps: 1000},
+				},
+				sockaddr.IfAddr{
+					SockAddr: sockaddr.MustIPv4Addr("172.16.0.5/24"),
+				},
+				sockaddr.IfAddr{
+					SockAddr: sockaddr.MustIPv4Addr("10.0.0.5/24"),
+					Link:     sockaddr.LinkInfo{SpeedMbps: 25000},
+				},
+			},
+			out: sockaddr.IfAddrs{
+				sockaddr.IfAddr{
+					SockAddr: sockaddr.MustIPv4Addr("10.0.0.5/24"),
+					Link:     sockaddr.LinkInfo{SpeedMbps: 25000},
+				},
+				sockaddr.IfAddr{
+					SockAddr: sockaddr.MustIPv4Addr("192.168.1.5/24"),
+					Link:     sockaddr.LinkInfo{SpeedMbps: 1000},
+				},
+				sockaddr.IfAddr{
+					SockAddr: sockaddr.MustIPv4Addr("172.16.0.5/24"),
+				},
+			},
+		},
 		{
 			name:    "sort type",
 			sortStr: "type",
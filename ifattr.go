@@ -3,18 +3,42 @@ package sockaddr
 import (
 	"fmt"
 	"net"
+	"time"
 )
 
 // IfAddr is a union of a SockAddr and a net.Interface.
 type IfAddr struct {
 	SockAddr
 	net.Interface
+
+	// AddrFlags are the kernel's flags of the address, e.g. whether it is
+	// a temporary or deprecated IPv6 address.  Only set on Linux.
+	AddrFlags AddrFlags
+
+	// PreferredLifetime and ValidLifetime are the remaining lifetimes of
+	// the address, or LifetimeForever if the address does not expire.
+	// Only set if AddrFlags is set.
+	PreferredLifetime time.Duration
+	ValidLifetime     time.Duration
+
+	// Link is the kind of the interface and its relation to other
+	// interfaces.  Only set on Linux.
+	Link LinkInfo
+
+	// Neighbor is the link-layer address and state of a neighbor returned
+	// by GetNeighbors.
+	Neighbor NeighborInfo
+
+	// system is the System the IfAddr was returned by, or nil for the
+	// package-level functions.  Its route information is used by
+	// AscIfDefault and the "gateway" attribute.
+	system *System
 }
 
 // Attr returns the named attribute as a string
 func (ifAddr IfAddr) Attr(attrName AttrName) (string, error) {
 	val := IfAddrAttr(ifAddr, attrName)
-	if val != "" {
+	if _, found := ifAddrAttrMap[attrName]; found || val != "" {
 		return val, nil
 	}
 
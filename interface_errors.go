@@ -0,0 +1,58 @@
+package sockaddr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// InterfaceError is a failure to read the addresses of an interface, or to
+// create an IPAddr from one of its addresses.
+type InterfaceError struct {
+	// Interface is the name of the interface.
+	Interface string
+
+	// Address is the address that could not be converted to an IPAddr, or
+	// empty if the addresses of the interface could not be read.
+	Address string
+
+	// Err is the underlying error.
+	Err error
+}
+
+// Error returns the interface, address and underlying error.
+func (e *InterfaceError) Error() string {
+	if e.Address == "" {
+		return fmt.Sprintf("unable to read the addresses of %s: %v", e.Interface, e.Err)
+	}
+	return fmt.Sprintf("unable to create an IP address from %q on %s: %v", e.Address, e.Interface, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *InterfaceError) Unwrap() error {
+	return e.Err
+}
+
+// InterfaceErrors are the interfaces and addresses skipped by
+// GetAllInterfacesPartial.  Use errors.As to retrieve them from the error it
+// returns.
+type InterfaceErrors []*InterfaceError
+
+// Error returns the errors of each skipped interface and address separated by
+// "; ".
+func (e InterfaceErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return fmt.Sprintf("%d interface error(s): %s", len(e), strings.Join(msgs, "; "))
+}
+
+// Unwrap returns the errors of each skipped interface and address, so that
+// errors.Is and errors.As examine each of them.
+func (e InterfaceErrors) Unwrap() []error {
+	errs := make([]error, 0, len(e))
+	for _, err := range e {
+		errs = append(errs, err)
+	}
+	return errs
+}
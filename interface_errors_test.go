@@ -0,0 +1,139 @@
+package sockaddr_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+
+	sockaddr "github.com/hashicorp/go-sockaddr"
+)
+
+// bogusAddr is a net.Addr that is not an IP address.
+type bogusAddr string
+
+func (a bogusAddr) Network() string { return "bogus" }
+func (a bogusAddr) String() string  { return string(a) }
+
+// brokenSystem is a FakeSystem whose tun0 interface can not be read and whose
+// eth1 interface has an address that is not an IP address.
+type brokenSystem struct {
+	fake *sockaddr.FakeSystem
+}
+
+var errTunnel = errors.New("tunnel is gone")
+
+func (s brokenSystem) Interfaces() ([]net.Interface, error) {
+	return s.fake.Interfaces()
+}
+
+func (s brokenSystem) Addrs(ifi net.Interface) ([]net.Addr, error) {
+	switch ifi.Name {
+	case "tun0":
+		return nil, errTunnel
+	case "eth1":
+		addrs, err := s.fake.Addrs(ifi)
+		return append(addrs, bogusAddr("link#7")), err
+	default:
+		return s.fake.Addrs(ifi)
+	}
+}
+
+func (s brokenSystem) RouteInfo(ctx context.Context) (sockaddr.RouteInterface, error) {
+	return s.fake.RouteInfo(ctx)
+}
+
+func newBrokenSystem() brokenSystem {
+	return brokenSystem{fake: sockaddr.NewFakeSystem().
+		AddInterface(net.Interface{Name: "eth0", Flags: net.FlagUp}, sockaddr.MustIPv4Addr("10.0.0.5/24")).
+		AddInterface(net.Interface{Name: "tun0", Flags: net.FlagUp}, sockaddr.MustIPv4Addr("172.16.0.5/30")).
+		AddInterface(net.Interface{Name: "eth1", Flags: net.FlagUp}, sockaddr.MustIPv4Addr("192.168.1.5/24")).
+		AddRoute(sockaddr.Route{
+			Destination: sockaddr.MustIPv4Addr("0.0.0.0/0"),
+			Gateway:     sockaddr.MustIPv4Addr("10.0.0.1"),
+			IfName:      "eth0",
+		})}
+}
+
+func TestGetAllInterfacesPartial(t *testing.T) {
+	sockaddr.SetSystemProvider(newBrokenSystem())
+	defer sockaddr.SetSystemProvider(nil)
+
+	ifAddrs, err := sockaddr.GetAllInterfacesPartial()
+	if got, _ := sockaddr.JoinIfAddrs("address", " ", ifAddrs); got != "10.0.0.5 192.168.1.5" {
+		t.Errorf("want the addresses of eth0 and eth1, got %q", got)
+	}
+
+	var ifErrs sockaddr.InterfaceErrors
+	if !errors.As(err, &ifErrs) {
+		t.Fatalf("want InterfaceErrors, got %v", err)
+	}
+	if len(ifErrs) != 2 {
+		t.Fatalf("want 2 interface errors, got %d: %v", len(ifErrs), ifErrs)
+	}
+	if ifErrs[0].Interface != "tun0" || ifErrs[0].Address != "" || ifErrs[0].Err != errTunnel {
+		t.Errorf("want the addresses of tun0 to fail, got %+v", ifErrs[0])
+	}
+	if ifErrs[1].Interface != "eth1" || ifErrs[1].Address != "link#7" {
+		t.Errorf("want the link#7 address of eth1 to fail, got %+v", ifErrs[1])
+	}
+	if !errors.Is(err, errTunnel) {
+		t.Errorf("expected the error to wrap the tun0 error")
+	}
+	for _, name := range []string{"tun0", "eth1", "link#7", "tunnel is gone"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("expected %q to name %q", err.Error(), name)
+		}
+	}
+
+	sockaddr.SetSystemProvider(nil)
+	if _, err := sockaddr.GetAllInterfacesPartial(); err != nil {
+		t.Errorf("expected the host to have no interface errors, got %v", err)
+	}
+}
+
+func TestGetAllInterfacesInterfaceErrors(t *testing.T) {
+	sockaddr.SetSystemProvider(newBrokenSystem())
+	defer sockaddr.SetSystemProvider(nil)
+
+	var ifErrs sockaddr.InterfaceErrors
+	if _, err := sockaddr.GetAllInterfaces(); !errors.As(err, &ifErrs) {
+		t.Errorf("expected GetAllInterfaces to fail with InterfaceErrors, got %v", err)
+	}
+	if _, err := sockaddr.GetDefaultInterfaces(); err == nil {
+		t.Errorf("expected GetDefaultInterfaces to fail")
+	}
+}
+
+func TestWriteSnapshotInterfaceErrors(t *testing.T) {
+	sockaddr.SetSystemProvider(newBrokenSystem())
+	defer sockaddr.SetSystemProvider(nil)
+
+	var buf bytes.Buffer
+	if err := sockaddr.WriteSnapshot(&buf); err != nil {
+		t.Fatalf("unable to write snapshot: %v", err)
+	}
+	if !strings.Contains(buf.String(), `"addresses_error": "tunnel is gone"`) {
+		t.Errorf("expected the tun0 error to be recorded:\n%s", buf.String())
+	}
+
+	snapshot, err := sockaddr.LoadFakeSystem(&buf)
+	if err != nil {
+		t.Fatalf("unable to load snapshot: %v", err)
+	}
+	sockaddr.SetSystemProvider(snapshot)
+
+	ifAddrs, err := sockaddr.GetAllInterfaces()
+	if err != nil {
+		t.Fatalf("unable to get interfaces: %v", err)
+	}
+	if got, _ := sockaddr.JoinIfAddrs("address", " ", ifAddrs); got != "10.0.0.5 192.168.1.5" {
+		t.Errorf("want the addresses of eth0 and eth1, got %q", got)
+	}
+	ifs, err := snapshot.Interfaces()
+	if err != nil || len(ifs) != 3 || ifs[1].Name != "tun0" {
+		t.Errorf("expected tun0 to be recorded, got %v (%v)", ifs, err)
+	}
+}
@@ -107,6 +107,10 @@ func ipAddrInit() {
 		"first_usable",
 		"last_usable",
 		"octets",
+		"multicast_scope",
+		"multicast_mac",
+		"class",
+		"tag",
 	}
 
 	ipAddrAttrMap = map[AttrName]func(ip IPAddr) string{
@@ -116,6 +120,9 @@ func ipAddrInit() {
 		"binary": func(ip IPAddr) string {
 			return ip.AddressBinString()
 		},
+		"class": func(ip IPAddr) string {
+			return strings.Join(ClassesOf(ip), "|")
+		},
 		"first_usable": func(ip IPAddr) string {
 			return ip.FirstUsable().String()
 		},
@@ -131,6 +138,16 @@ func ipAddrInit() {
 		"mask_bits": func(ip IPAddr) string {
 			return fmt.Sprintf("%d", ip.Maskbits())
 		},
+		"multicast_mac": func(ip IPAddr) string {
+			mac, err := MulticastMAC(ip)
+			if err != nil {
+				return ""
+			}
+			return mac.String()
+		},
+		"multicast_scope": func(ip IPAddr) string {
+			return MulticastScopeOf(ip).String()
+		},
 		"netmask": func(ip IPAddr) string {
 			switch v := ip.(type) {
 			case IPv4Addr:
@@ -165,5 +182,9 @@ func ipAddrInit() {
 		"port": func(ip IPAddr) string {
 			return fmt.Sprintf("%d", ip.IPPort())
 		},
+		"tag": func(ip IPAddr) string {
+			label, _ := TagOf(ip)
+			return label
+		},
 	}
 }
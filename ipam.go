@@ -0,0 +1,419 @@
+package sockaddr
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// AllocStrategy selects which free block a SubnetAllocator carves a new
+// subnet out of.
+type AllocStrategy int
+
+const (
+	// AllocFirstFit allocates the lowest addressed subnet that is free.
+	AllocFirstFit AllocStrategy = iota
+
+	// AllocBestFit allocates the subnet from the smallest free block that
+	// is large enough to hold it, which keeps large blocks intact for as
+	// long as possible.
+	AllocBestFit
+)
+
+// SubnetAllocator hands out subnets of a parent network.  Networks that are
+// allocated or reserved are tracked as used until they are released.  All
+// methods are safe for concurrent use and, given the same sequence of calls,
+// always return the same subnets.
+type SubnetAllocator struct {
+	lock     sync.Mutex
+	parent   IPAddr
+	strategy AllocStrategy
+	used     []ipRange
+}
+
+// subnetAllocatorJSON is the serialized form of a SubnetAllocator.
+type subnetAllocatorJSON struct {
+	Parent   string   `json:"parent"`
+	Strategy string   `json:"strategy"`
+	Used     []string `json:"used"`
+}
+
+// NewSubnetAllocator creates a SubnetAllocator for the parent network.  Every
+// network in used is reserved and must be contained within parent.
+func NewSubnetAllocator(parent IPAddr, strategy AllocStrategy, used ...IPAddr) (*SubnetAllocator, error) {
+	if parent == nil {
+		return nil, fmt.Errorf("parent network required")
+	}
+
+	switch strategy {
+	case AllocFirstFit, AllocBestFit:
+	default:
+		return nil, fmt.Errorf("unsupported allocation strategy %s", strategy)
+	}
+
+	a := &SubnetAllocator{
+		parent:   parent.Network(),
+		strategy: strategy,
+	}
+	for _, network := range used {
+		if err := a.Reserve(network); err != nil {
+			return nil, err
+		}
+	}
+
+	return a, nil
+}
+
+// ParseAllocStrategy returns the AllocStrategy matching its string form
+// ("first-fit" or "best-fit").
+func ParseAllocStrategy(s string) (AllocStrategy, error) {
+	switch strings.ToLower(s) {
+	case "first-fit", "firstfit":
+		return AllocFirstFit, nil
+	case "best-fit", "bestfit":
+		return AllocBestFit, nil
+	default:
+		return AllocFirstFit, fmt.Errorf("unsupported allocation strategy %q", s)
+	}
+}
+
+// String returns the string representation of the AllocStrategy.
+func (s AllocStrategy) String() string {
+	switch s {
+	case AllocFirstFit:
+		return "first-fit"
+	case AllocBestFit:
+		return "best-fit"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(s))
+	}
+}
+
+// Parent returns the network subnets are allocated from.
+func (a *SubnetAllocator) Parent() IPAddr {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	return a.parent
+}
+
+// Strategy returns the AllocStrategy used by Allocate.
+func (a *SubnetAllocator) Strategy() AllocStrategy {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	return a.strategy
+}
+
+// Allocate reserves and returns the next free subnet with the given prefix
+// length using the allocator's strategy.
+func (a *SubnetAllocator) Allocate(prefixLen int) (IPAddr, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	return a.allocate(prefixLen, a.strategy)
+}
+
+// AllocateWith reserves and returns the next free subnet with the given
+// prefix length using the specified strategy.  An error is returned if
+// there is no room left in the parent network.
+func (a *SubnetAllocator) AllocateWith(prefixLen int, strategy AllocStrategy) (IPAddr, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	return a.allocate(prefixLen, strategy)
+}
+
+// allocate reserves and returns the next free subnet with the given prefix
+// length using strategy.  The caller must hold the lock.
+func (a *SubnetAllocator) allocate(prefixLen int, strategy AllocStrategy) (IPAddr, error) {
+	bits := ipAddrBits(a.parent)
+	if prefixLen < a.parent.Maskbits() || prefixLen > bits {
+		return nil, fmt.Errorf("prefix length %d must be between %d and %d for %s", prefixLen, a.parent.Maskbits(), bits, a.parent)
+	}
+
+	size := new(big.Int).Lsh(big.NewInt(1), uint(bits-prefixLen))
+
+	var start *big.Int
+	switch strategy {
+	case AllocFirstFit:
+		for _, gap := range a.gaps() {
+			// Round up to the next boundary of the requested size.
+			candidate := new(big.Int).Add(gap.first, size)
+			candidate.Sub(candidate, big.NewInt(1))
+			candidate.Div(candidate, size)
+			candidate.Mul(candidate, size)
+
+			end := new(big.Int).Add(candidate, size)
+			end.Sub(end, big.NewInt(1))
+			if end.Cmp(gap.last) <= 0 {
+				start = candidate
+				break
+			}
+		}
+	case AllocBestFit:
+		bestLen := -1
+		for _, block := range a.freeBlocks() {
+			blockLen := rangePrefixLen(block, bits)
+			if blockLen > prefixLen || blockLen <= bestLen {
+				continue
+			}
+			bestLen = blockLen
+			start = block.first
+		}
+	default:
+		return nil, fmt.Errorf("unsupported allocation strategy %s", strategy)
+	}
+
+	if start == nil {
+		return nil, fmt.Errorf("no free /%d left in %s", prefixLen, a.parent)
+	}
+
+	network := newIPAddrFromBigInt(a.parent.Type(), start, prefixLen)
+	a.insert(network)
+	return network, nil
+}
+
+// Reserve marks network as used so that it is never allocated.  network must
+// be contained within the parent network and may not overlap a network that
+// is already used.
+func (a *SubnetAllocator) Reserve(network IPAddr) error {
+	if network == nil {
+		return fmt.Errorf("network required")
+	}
+	network = network.Network()
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if !a.parent.Contains(network) {
+		return fmt.Errorf("%s is not contained within %s", network, a.parent)
+	}
+
+	first, last := ipAddrRange(network)
+	for _, u := range a.used {
+		if cmpRanges(first, last, u.first, u.last) != OverlapNone {
+			return fmt.Errorf("%s overlaps %s", network, newIPAddrFromBigInt(a.parent.Type(), u.first, rangePrefixLen(u, ipAddrBits(a.parent))))
+		}
+	}
+
+	a.insert(network)
+	return nil
+}
+
+// Release returns a previously allocated or reserved network to the free
+// pool.  network must exactly match a used network.
+func (a *SubnetAllocator) Release(network IPAddr) error {
+	if network == nil {
+		return fmt.Errorf("network required")
+	}
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	first, last := ipAddrRange(network.Network())
+	for i, u := range a.used {
+		if cmpRanges(first, last, u.first, u.last) == OverlapEqual {
+			a.used = append(a.used[:i], a.used[i+1:]...)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%s is not allocated from %s", network.Network(), a.parent)
+}
+
+// Used returns the allocated and reserved networks in address order.
+func (a *SubnetAllocator) Used() IPAddrs {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	return a.toIPAddrs(a.used)
+}
+
+// Free returns the largest free CIDR blocks remaining in the parent network
+// in address order.
+func (a *SubnetAllocator) Free() IPAddrs {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	return a.toIPAddrs(a.freeBlocks())
+}
+
+// MarshalJSON encodes the parent network, strategy, and used networks of the
+// allocator.
+func (a *SubnetAllocator) MarshalJSON() ([]byte, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	used := a.toIPAddrs(a.used)
+	out := subnetAllocatorJSON{
+		Parent:   a.parent.String(),
+		Strategy: a.strategy.String(),
+		Used:     make([]string, 0, len(used)),
+	}
+	for _, network := range used {
+		out.Used = append(out.Used, network.String())
+	}
+
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON restores the state of an allocator previously encoded with
+// MarshalJSON.
+func (a *SubnetAllocator) UnmarshalJSON(in []byte) error {
+	var state subnetAllocatorJSON
+	if err := json.Unmarshal(in, &state); err != nil {
+		return err
+	}
+
+	parent, err := NewIPAddr(state.Parent)
+	if err != nil {
+		return fmt.Errorf("unable to parse parent network %q: %v", state.Parent, err)
+	}
+
+	strategy, err := ParseAllocStrategy(state.Strategy)
+	if err != nil {
+		return err
+	}
+
+	used := make([]IPAddr, 0, len(state.Used))
+	for _, s := range state.Used {
+		network, err := NewIPAddr(s)
+		if err != nil {
+			return fmt.Errorf("unable to parse used network %q: %v", s, err)
+		}
+		used = append(used, network)
+	}
+
+	restored, err := NewSubnetAllocator(parent, strategy, used...)
+	if err != nil {
+		return err
+	}
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.parent = restored.parent
+	a.strategy = restored.strategy
+	a.used = restored.used
+
+	return nil
+}
+
+// insert adds network to the list of used ranges, keeping the list sorted by
+// address.  The caller must hold the lock.
+func (a *SubnetAllocator) insert(network IPAddr) {
+	first, last := ipAddrRange(network)
+	i := sort.Search(len(a.used), func(i int) bool {
+		return a.used[i].first.Cmp(first) > 0
+	})
+
+	a.used = append(a.used, ipRange{})
+	copy(a.used[i+1:], a.used[i:])
+	a.used[i] = ipRange{first: first, last: last}
+}
+
+// gaps returns the ranges of the parent network not covered by a used range,
+// in address order.  The caller must hold the lock.
+func (a *SubnetAllocator) gaps() []ipRange {
+	parentFirst, parentLast := ipAddrRange(a.parent)
+
+	gaps := make([]ipRange, 0, len(a.used)+1)
+	next := parentFirst
+	for _, u := range a.used {
+		if next.Cmp(u.first) < 0 {
+			gaps = append(gaps, ipRange{first: next, last: new(big.Int).Sub(u.first, big.NewInt(1))})
+		}
+		next = new(big.Int).Add(u.last, big.NewInt(1))
+	}
+	if next.Cmp(parentLast) <= 0 {
+		gaps = append(gaps, ipRange{first: next, last: parentLast})
+	}
+
+	return gaps
+}
+
+// freeBlocks splits every gap into the largest aligned CIDR blocks that fit
+// within it.  The caller must hold the lock.
+func (a *SubnetAllocator) freeBlocks() []ipRange {
+	bits := ipAddrBits(a.parent)
+
+	var blocks []ipRange
+	for _, gap := range a.gaps() {
+		start := new(big.Int).Set(gap.first)
+		for start.Cmp(gap.last) <= 0 {
+			// Grow the block while its start stays aligned and its end
+			// stays within the gap.
+			hostBits := 0
+			for hostBits < bits && start.Bit(hostBits) == 0 {
+				end := new(big.Int).Lsh(big.NewInt(1), uint(hostBits+1))
+				end.Add(end, start)
+				end.Sub(end, big.NewInt(1))
+				if end.Cmp(gap.last) > 0 {
+					break
+				}
+				hostBits++
+			}
+
+			size := new(big.Int).Lsh(big.NewInt(1), uint(hostBits))
+			last := new(big.Int).Add(start, size)
+			last.Sub(last, big.NewInt(1))
+			blocks = append(blocks, ipRange{first: new(big.Int).Set(start), last: last})
+			start.Add(start, size)
+		}
+	}
+
+	return blocks
+}
+
+// toIPAddrs converts CIDR aligned ranges into IPAddrs of the parent's type.
+func (a *SubnetAllocator) toIPAddrs(ranges []ipRange) IPAddrs {
+	bits := ipAddrBits(a.parent)
+	ipAddrs := make(IPAddrs, 0, len(ranges))
+	for _, r := range ranges {
+		ipAddrs = append(ipAddrs, newIPAddrFromBigInt(a.parent.Type(), r.first, rangePrefixLen(r, bits)))
+	}
+	return ipAddrs
+}
+
+// rangePrefixLen returns the prefix length of a CIDR aligned range.
+func rangePrefixLen(r ipRange, bits int) int {
+	size := new(big.Int).Sub(r.last, r.first)
+	return bits - size.BitLen()
+}
+
+// ipAddrBits returns the number of bits in an address of ip's family.
+func ipAddrBits(ip IPAddr) int {
+	if ip.Type() == TypeIPv4 {
+		return IPv4len * 8
+	}
+	return IPv6len * 8
+}
+
+// newIPAddrFromBigInt creates an IPv4Addr or IPv6Addr from an address stored
+// in a big.Int and a prefix length.
+func newIPAddrFromBigInt(sockType SockAddrType, addr *big.Int, prefixLen int) IPAddr {
+	switch sockType {
+	case TypeIPv4:
+		mask := IPv4HostMask
+		if prefixLen < IPv4len*8 {
+			mask = IPv4Mask(^uint32(0) << uint(IPv4len*8-prefixLen))
+		}
+		return IPv4Addr{
+			Address: IPv4Address(addr.Uint64()),
+			Mask:    mask,
+		}
+	case TypeIPv6:
+		mask := new(big.Int).Lsh(big.NewInt(1), uint(prefixLen))
+		mask.Sub(mask, big.NewInt(1))
+		mask.Lsh(mask, uint(IPv6len*8-prefixLen))
+		return IPv6Addr{
+			Address: IPv6Address(new(big.Int).Set(addr)),
+			Mask:    IPv6Mask(mask),
+		}
+	default:
+		panic(fmt.Sprintf("unsupported type %s", sockType))
+	}
+}
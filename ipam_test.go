@@ -0,0 +1,225 @@
+package sockaddr_test
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+
+	sockaddr "github.com/hashicorp/go-sockaddr"
+)
+
+func TestSubnetAllocator_Allocate(t *testing.T) {
+	tests := []struct {
+		name     string
+		parent   string
+		used     []string
+		strategy sockaddr.AllocStrategy
+		allocs   []int
+		want     []string
+		fail     bool
+	}{
+		{
+			name:     "first-fit empty",
+			parent:   "10.0.0.0/16",
+			strategy: sockaddr.AllocFirstFit,
+			allocs:   []int{24, 24, 23},
+			want:     []string{"10.0.0.0/24", "10.0.1.0/24", "10.0.2.0/23"},
+		},
+		{
+			name:     "first-fit aligns past used networks",
+			parent:   "10.0.0.0/16",
+			used:     []string{"10.0.0.0/26", "10.0.1.0/24"},
+			strategy: sockaddr.AllocFirstFit,
+			allocs:   []int{24, 26},
+			want:     []string{"10.0.2.0/24", "10.0.0.64/26"},
+		},
+		{
+			name:     "best-fit fills the smallest hole",
+			parent:   "10.0.0.0/16",
+			used:     []string{"10.0.0.0/25", "10.0.2.0/23", "10.0.4.0/24"},
+			strategy: sockaddr.AllocBestFit,
+			allocs:   []int{25, 24},
+			want:     []string{"10.0.0.128/25", "10.0.1.0/24"},
+		},
+		{
+			name:     "first-fit takes lowest hole",
+			parent:   "10.0.0.0/16",
+			used:     []string{"10.0.0.0/25", "10.0.2.0/23", "10.0.4.0/24"},
+			strategy: sockaddr.AllocFirstFit,
+			allocs:   []int{26},
+			want:     []string{"10.0.0.128/26"},
+		},
+		{
+			name:     "ipv6",
+			parent:   "2001:db8::/48",
+			used:     []string{"2001:db8::/64"},
+			strategy: sockaddr.AllocBestFit,
+			allocs:   []int{64, 56},
+			want:     []string{"2001:db8:0:1::/64", "2001:db8:0:100::/56"},
+		},
+		{
+			name:     "exhausted",
+			parent:   "10.0.0.0/30",
+			used:     []string{"10.0.0.0/31"},
+			strategy: sockaddr.AllocFirstFit,
+			allocs:   []int{31, 31},
+			want:     []string{"10.0.0.2/31"},
+			fail:     true,
+		},
+		{
+			name:     "prefix too short",
+			parent:   "10.0.0.0/16",
+			strategy: sockaddr.AllocFirstFit,
+			allocs:   []int{8},
+			fail:     true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			used := make([]sockaddr.IPAddr, 0, len(test.used))
+			for _, u := range test.used {
+				used = append(used, sockaddr.MustIPAddr(u))
+			}
+
+			a, err := sockaddr.NewSubnetAllocator(sockaddr.MustIPAddr(test.parent), test.strategy, used...)
+			if err != nil {
+				t.Fatalf("unable to create allocator: %v", err)
+			}
+
+			for i, prefixLen := range test.allocs {
+				network, err := a.Allocate(prefixLen)
+				if i < len(test.want) {
+					if err != nil {
+						t.Fatalf("allocation %d failed: %v", i, err)
+					}
+					if network.String() != test.want[i] {
+						t.Fatalf("allocation %d: want %s, got %s", i, test.want[i], network)
+					}
+					continue
+				}
+
+				if err == nil {
+					t.Fatalf("allocation %d: expected failure, got %s", i, network)
+				}
+			}
+
+			if test.fail && len(test.allocs) == len(test.want) {
+				t.Fatalf("expected a failed allocation")
+			}
+		})
+	}
+}
+
+func TestSubnetAllocator_ReserveRelease(t *testing.T) {
+	a, err := sockaddr.NewSubnetAllocator(sockaddr.MustIPAddr("192.168.0.0/22"), sockaddr.AllocFirstFit)
+	if err != nil {
+		t.Fatalf("unable to create allocator: %v", err)
+	}
+
+	if err := a.Reserve(sockaddr.MustIPAddr("192.168.1.0/24")); err != nil {
+		t.Fatalf("unable to reserve: %v", err)
+	}
+
+	if err := a.Reserve(sockaddr.MustIPAddr("192.168.1.128/25")); err == nil {
+		t.Fatalf("expected overlapping reservation to fail")
+	}
+
+	if err := a.Reserve(sockaddr.MustIPAddr("192.168.8.0/24")); err == nil {
+		t.Fatalf("expected reservation outside of parent to fail")
+	}
+
+	if err := a.Reserve(sockaddr.MustIPAddr("2001:db8::/64")); err == nil {
+		t.Fatalf("expected reservation of a different family to fail")
+	}
+
+	if err := a.Release(sockaddr.MustIPAddr("192.168.2.0/24")); err == nil {
+		t.Fatalf("expected release of a free network to fail")
+	}
+
+	free := a.Free()
+	if len(free) != 2 || free[0].String() != "192.168.0.0/24" || free[1].String() != "192.168.2.0/23" {
+		t.Fatalf("unexpected free blocks: %v", free)
+	}
+
+	if err := a.Release(sockaddr.MustIPAddr("192.168.1.10/24")); err != nil {
+		t.Fatalf("unable to release: %v", err)
+	}
+
+	if used := a.Used(); len(used) != 0 {
+		t.Fatalf("expected no used networks, got %v", used)
+	}
+
+	if free := a.Free(); len(free) != 1 || free[0].String() != "192.168.0.0/22" {
+		t.Fatalf("unexpected free blocks: %v", free)
+	}
+}
+
+func TestSubnetAllocator_JSON(t *testing.T) {
+	a, err := sockaddr.NewSubnetAllocator(sockaddr.MustIPAddr("10.0.0.0/16"), sockaddr.AllocBestFit,
+		sockaddr.MustIPAddr("10.0.4.0/24"), sockaddr.MustIPAddr("10.0.0.0/24"))
+	if err != nil {
+		t.Fatalf("unable to create allocator: %v", err)
+	}
+
+	out, err := json.Marshal(a)
+	if err != nil {
+		t.Fatalf("unable to marshal: %v", err)
+	}
+
+	const want = `{"parent":"10.0.0.0/16","strategy":"best-fit","used":["10.0.0.0/24","10.0.4.0/24"]}`
+	if string(out) != want {
+		t.Fatalf("want %s, got %s", want, out)
+	}
+
+	var restored sockaddr.SubnetAllocator
+	if err := json.Unmarshal(out, &restored); err != nil {
+		t.Fatalf("unable to unmarshal: %v", err)
+	}
+
+	expected, _ := a.Allocate(24)
+	got, err := restored.Allocate(24)
+	if err != nil {
+		t.Fatalf("unable to allocate: %v", err)
+	}
+	if got.String() != expected.String() {
+		t.Fatalf("want %s, got %s", expected, got)
+	}
+
+	if err := json.Unmarshal([]byte(`{"parent":"10.0.0.0/16","strategy":"best-fit","used":["10.1.0.0/24"]}`), &restored); err == nil {
+		t.Fatalf("expected a used network outside of the parent to fail")
+	}
+}
+
+func TestSubnetAllocator_Concurrent(t *testing.T) {
+	a, err := sockaddr.NewSubnetAllocator(sockaddr.MustIPv4Addr("10.0.0.0/16"), sockaddr.AllocFirstFit)
+	if err != nil {
+		t.Fatalf("unable to create allocator: %v", err)
+	}
+
+	// Run with -race: allocating, encoding and restoring the allocator
+	// concurrently must not race on its parent network.
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			if _, err := a.AllocateWith(24, sockaddr.AllocBestFit); err != nil {
+				t.Errorf("unable to allocate: %v", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if _, err := json.Marshal(a); err != nil {
+				t.Errorf("unable to encode: %v", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if err := json.Unmarshal([]byte(`{"parent": "10.0.0.0/16", "strategy": "first-fit"}`), a); err != nil {
+				t.Errorf("unable to decode: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+}
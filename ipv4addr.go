@@ -354,6 +354,12 @@ func (ipv4 IPv4Addr) IPPort() IPPort {
 	return ipv4.Port
 }
 
+// IsAdminScopedMulticast returns true if the IPv4Addr is within the
+// administratively scoped multicast range 239.0.0.0/8 (RFC 2365).
+func (ipv4 IPv4Addr) IsAdminScopedMulticast() bool {
+	return ipv4MulticastAdminScoped.ContainsAddress(ipv4.Address)
+}
+
 // LastUsable returns the last address before the broadcast address in a
 // given network.
 func (ipv4 IPv4Addr) LastUsable() IPAddr {
@@ -400,6 +406,27 @@ func (ipv4 IPv4Addr) Maskbits() int {
 	return maskOnes
 }
 
+// MulticastScope returns the scope of an IPv4 multicast group: link for
+// 224.0.0.0/24, site for the IPv4 Local Scope 239.255.0.0/16, organization
+// for 239.192.0.0/14, admin for the rest of 239.0.0.0/8, and global for all
+// other groups.  MulticastScopeNone is returned for non-multicast addresses.
+func (ipv4 IPv4Addr) MulticastScope() MulticastScope {
+	switch {
+	case !ipv4MulticastAllAddresses.ContainsAddress(ipv4.Address):
+		return MulticastScopeNone
+	case ipv4MulticastLinkLocal.ContainsAddress(ipv4.Address):
+		return MulticastScopeLinkLocal
+	case ipv4MulticastLocalScope.ContainsAddress(ipv4.Address):
+		return MulticastScopeSiteLocal
+	case ipv4MulticastOrgLocal.ContainsAddress(ipv4.Address):
+		return MulticastScopeOrganizationLocal
+	case ipv4MulticastAdminScoped.ContainsAddress(ipv4.Address):
+		return MulticastScopeAdminLocal
+	default:
+		return MulticastScopeGlobal
+	}
+}
+
 // MustIPv4Addr is a helper method that must return an IPv4Addr or panic on
 // invalid input.
 func MustIPv4Addr(addr string) IPv4Addr {
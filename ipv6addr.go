@@ -437,6 +437,29 @@ func (ipv6 IPv6Addr) Maskbits() int {
 	return maskOnes
 }
 
+// MulticastFlags returns the flag bits of an IPv6 multicast address.  The
+// result is zero for non-multicast addresses.
+func (ipv6 IPv6Addr) MulticastFlags() MulticastFlags {
+	if !IsMulticast(ipv6) {
+		return 0
+	}
+
+	netIP := *ipv6.NetIP()
+	return MulticastFlags(netIP[1]>>4) & (MulticastFlagTransient | MulticastFlagPrefix | MulticastFlagRendezvous)
+}
+
+// MulticastScope returns the scope of an IPv6 multicast address as encoded in
+// its scope field.  MulticastScopeNone is returned for non-multicast
+// addresses.
+func (ipv6 IPv6Addr) MulticastScope() MulticastScope {
+	if !IsMulticast(ipv6) {
+		return MulticastScopeNone
+	}
+
+	netIP := *ipv6.NetIP()
+	return ipv6MulticastScopes[netIP[1]&0x0f]
+}
+
 // MustIPv6Addr is a helper method that must return an IPv6Addr or panic on
 // invalid input.
 func MustIPv6Addr(addr string) IPv6Addr {
@@ -502,6 +525,17 @@ func (ipv6 IPv6Addr) Octets() []int {
 	return x
 }
 
+// SolicitedNodeMulticast returns the solicited-node multicast group of a
+// unicast IPv6Addr: ff02::1:ff00:0/104 followed by the low 24 bits of the
+// address (RFC 4291, §2.7.1).
+func (ipv6 IPv6Addr) SolicitedNodeMulticast() (IPv6Addr, error) {
+	if IsMulticast(ipv6) {
+		return IPv6Addr{}, fmt.Errorf("%s is not a unicast address", ipv6)
+	}
+
+	return ipv6SolicitedNode(ipv6.Address), nil
+}
+
 // String returns a string representation of the IPv6Addr
 func (ipv6 IPv6Addr) String() string {
 	if ipv6.Port != 0 {
@@ -542,14 +576,29 @@ func ipv6AddrInit() {
 	ipv6AddrAttrs = []AttrName{
 		"size", // Same position as in IPv6 for output consistency
 		"uint128",
+		"multicast_flags",
+		"solicited_node",
 	}
 
 	ipv6AddrAttrMap = map[AttrName]func(ipv6 IPv6Addr) string{
+		"multicast_flags": func(ipv6 IPv6Addr) string {
+			if !IsMulticast(ipv6) {
+				return ""
+			}
+			return ipv6.MulticastFlags().String()
+		},
 		"size": func(ipv6 IPv6Addr) string {
 			netSize := big.NewInt(1)
 			netSize = netSize.Lsh(netSize, uint(IPv6len*8-ipv6.Maskbits()))
 			return netSize.Text(10)
 		},
+		"solicited_node": func(ipv6 IPv6Addr) string {
+			group, err := ipv6.SolicitedNodeMulticast()
+			if err != nil {
+				return ""
+			}
+			return group.String()
+		},
 		"uint128": func(ipv6 IPv6Addr) string {
 			b := big.Int(*ipv6.Address)
 			return b.Text(10)
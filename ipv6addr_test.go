@@ -717,7 +717,7 @@ func TestIPv6Addr_CmpRFC(t *testing.T) {
 }
 
 func TestIPv6Attrs(t *testing.T) {
-	const expectedNumAttrs = 2
+	const expectedNumAttrs = 4
 	attrs := sockaddr.IPv6Attrs()
 	if len(attrs) != expectedNumAttrs {
 		t.Fatalf("wrong number of IPv6Attrs: %d vs %d", len(attrs), expectedNumAttrs)
@@ -0,0 +1,232 @@
+package sockaddr
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Interface kinds reported by the "kind" attribute.  Virtual interfaces of
+// other kinds report the kernel's name of their link type, e.g. "vxlan" or
+// "macvlan".
+const (
+	KindPhysical  = "physical"
+	KindLoopback  = "loopback"
+	KindBridge    = "bridge"
+	KindVeth      = "veth"
+	KindVLAN      = "vlan"
+	KindBond      = "bond"
+	KindTun       = "tun"
+	KindTap       = "tap"
+	KindWireGuard = "wireguard"
+	KindDummy     = "dummy"
+)
+
+// LinkInfo is the kernel's information about the link of an interface that is
+// not part of net.Interface.  It is only known on Linux; elsewhere the
+// LinkInfo of an IfAddr is empty.
+type LinkInfo struct {
+	// Kind is the kind of the interface, e.g. KindPhysical or KindBridge.
+	Kind string
+
+	// Master is the name of the bridge or bond the interface is enslaved
+	// to, if any.
+	Master string
+
+	// VLANID is the VLAN ID of a KindVLAN interface.
+	VLANID int
+
+	// OperState is the operational state of the interface as reported by
+	// the kernel (RFC 2863), e.g. "up", "down" or "lowerlayerdown".  Unlike
+	// the "up" flag, which only reflects the administrative state, an
+	// interface without a carrier is not operationally up.
+	OperState string
+
+	// Carrier is true if the interface has a carrier, e.g. a cable is
+	// plugged in.
+	Carrier bool
+
+	// SpeedMbps is the speed of the interface in Mbit/s, or zero if it is
+	// unknown (e.g. for virtual interfaces).
+	SpeedMbps int
+
+	// Duplex is the duplex mode of the interface: "full", "half" or
+	// "unknown".
+	Duplex string
+
+	// Stats are the traffic counters of the interface.
+	Stats LinkStats
+}
+
+// linkInfoReader is implemented by SystemProviders that know the LinkInfo of
+// their interfaces.  GetAllInterfaces uses it to set the Link of each IfAddr.
+type linkInfoReader interface {
+	linkInfos() (map[int]LinkInfo, error)
+}
+
+// vlanIDString returns the VLAN ID of a VLAN interface, or an empty string for
+// other interfaces.
+func vlanIDString(link LinkInfo) string {
+	if link.Kind != KindVLAN {
+		return ""
+	}
+	return strconv.Itoa(link.VLANID)
+}
+
+// Link attributes from the Linux kernel's <linux/if_link.h>.
+const (
+	rtmNewLink     = 16
+	ifInfoMsgLen   = 16
+	iflaIfName     = 3
+	iflaMaster     = 10
+	iflaLinkInfo   = 18
+	iflaInfoKind   = 1
+	iflaInfoData   = 2
+	iflaVLANID     = 1
+	iflaTunType    = 3
+	iffLoopback    = 0x8
+	iffTun         = 0x1
+	iffTap         = 0x2
+	arphrdLoopback = 772
+)
+
+// netlinkLink is a link of a netlink link dump before the index of its master
+// is resolved to a name.
+type netlinkLink struct {
+	name        string
+	masterIndex int
+	link        LinkInfo
+}
+
+// parseNetlinkLinks parses the RTM_NEWLINK messages of a netlink link dump in
+// host byte order and returns the LinkInfo of each interface by index.
+func parseNetlinkLinks(b []byte) (map[int]LinkInfo, error) {
+	links := make(map[int]netlinkLink)
+	for len(b) > 0 {
+		if len(b) < nlmsgHdrLen {
+			return nil, fmt.Errorf("truncated netlink message header")
+		}
+		msgLen := int(binary.NativeEndian.Uint32(b[0:4]))
+		msgType := binary.NativeEndian.Uint16(b[4:6])
+		if msgLen < nlmsgHdrLen || msgLen > len(b) {
+			return nil, fmt.Errorf("invalid netlink message length %d", msgLen)
+		}
+		data := b[nlmsgHdrLen:msgLen]
+		b = b[min(netlinkAlign(msgLen), len(b)):]
+
+		switch msgType {
+		case nlmsgDone:
+			continue
+		case nlmsgError:
+			return nil, fmt.Errorf("netlink link dump failed")
+		case rtmNewLink:
+		default:
+			continue
+		}
+
+		index, link, err := parseNetlinkLink(data)
+		if err != nil {
+			return nil, err
+		}
+		links[index] = link
+	}
+
+	infos := make(map[int]LinkInfo, len(links))
+	for index, link := range links {
+		if master, found := links[link.masterIndex]; found && link.masterIndex != 0 {
+			link.link.Master = master.name
+		}
+		infos[index] = link.link
+	}
+	return infos, nil
+}
+
+// parseNetlinkLink parses the body of a single RTM_NEWLINK message.
+func parseNetlinkLink(data []byte) (int, netlinkLink, error) {
+	if len(data) < ifInfoMsgLen {
+		return 0, netlinkLink{}, fmt.Errorf("truncated link message")
+	}
+
+	linkType := binary.NativeEndian.Uint16(data[2:4])
+	index := int(int32(binary.NativeEndian.Uint32(data[4:8])))
+	flags := binary.NativeEndian.Uint32(data[8:12])
+
+	var link netlinkLink
+	var kind string
+	var infoData []byte
+	err := visitNetlinkAttrs(data[ifInfoMsgLen:], func(attrType uint16, value []byte) error {
+		switch attrType {
+		case iflaIfName:
+			link.name = strings.TrimRight(string(value), "\x00")
+		case iflaMaster:
+			if len(value) != 4 {
+				return fmt.Errorf("invalid length %d in link attribute %d", len(value), attrType)
+			}
+			link.masterIndex = int(binary.NativeEndian.Uint32(value))
+		case iflaLinkInfo:
+			return visitNetlinkAttrs(value, func(infoType uint16, value []byte) error {
+				switch infoType {
+				case iflaInfoKind:
+					kind = strings.TrimRight(string(value), "\x00")
+				case iflaInfoData:
+					infoData = value
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, netlinkLink{}, err
+	}
+
+	switch {
+	case flags&iffLoopback != 0 || linkType == arphrdLoopback:
+		link.link.Kind = KindLoopback
+	case kind == "":
+		link.link.Kind = KindPhysical
+	case kind == "vlan":
+		link.link.Kind = KindVLAN
+		err = visitNetlinkAttrs(infoData, func(dataType uint16, value []byte) error {
+			if dataType == iflaVLANID && len(value) >= 2 {
+				link.link.VLANID = int(binary.NativeEndian.Uint16(value))
+			}
+			return nil
+		})
+	case kind == "tun":
+		link.link.Kind = KindTun
+		err = visitNetlinkAttrs(infoData, func(dataType uint16, value []byte) error {
+			if dataType == iflaTunType && len(value) >= 1 && value[0] == iffTap {
+				link.link.Kind = KindTap
+			}
+			return nil
+		})
+	default:
+		link.link.Kind = kind
+	}
+	if err != nil {
+		return 0, netlinkLink{}, err
+	}
+
+	return index, link, nil
+}
+
+// visitNetlinkAttrs calls fn with the type and value of each netlink attribute
+// in b.  The nested flag of the attribute type is cleared.
+func visitNetlinkAttrs(b []byte, fn func(attrType uint16, value []byte) error) error {
+	for len(b) >= rtaHdrLen {
+		attrLen := int(binary.NativeEndian.Uint16(b[0:2]))
+		attrType := binary.NativeEndian.Uint16(b[2:4]) &^ 0x8000
+		if attrLen < rtaHdrLen || attrLen > len(b) {
+			return fmt.Errorf("invalid link attribute length %d", attrLen)
+		}
+		value := b[rtaHdrLen:attrLen]
+		b = b[min(netlinkAlign(attrLen), len(b)):]
+
+		if err := fn(attrType, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package sockaddr
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// linkInfos reads the LinkInfo of the interfaces of the host.  The kind and
+// master of each interface are read from netlink, or from /sys/class/net if
+// netlink is unavailable (e.g. in a sandbox), the operational state, speed and
+// duplex from /sys/class/net, and the traffic counters from /proc/net/dev.
+func (hostSystem) linkInfos() (map[int]LinkInfo, error) {
+	ifs, err := net.Interfaces()
+	if err != nil {
+		return nil, err
+	}
+
+	var links map[int]LinkInfo
+	if b, err := syscall.NetlinkRIB(syscall.RTM_GETLINK, syscall.AF_UNSPEC); err == nil {
+		links, _ = parseNetlinkLinks(b)
+	}
+	fromSysfs := links == nil
+	if fromSysfs {
+		links = make(map[int]LinkInfo, len(ifs))
+	}
+
+	var stats map[string]LinkStats
+	if f, err := os.Open("/proc/net/dev"); err == nil {
+		stats, _ = parseProcNetDev(f)
+		f.Close()
+	}
+
+	for _, ifi := range ifs {
+		dir := filepath.Join("/sys/class/net", ifi.Name)
+		link := links[ifi.Index]
+		if fromSysfs {
+			readSysfsLinkKind(dir, ifi.Flags, &link)
+		}
+		// The state is left unknown if it can not be read, but the
+		// kind and counters are recorded regardless.
+		readSysfsLinkState(dir, &link)
+		link.Stats = stats[ifi.Name]
+		links[ifi.Index] = link
+	}
+
+	return links, nil
+}
@@ -0,0 +1,11 @@
+// +build !linux
+
+package sockaddr
+
+import "errors"
+
+// linkInfos is not supported on this platform, so the LinkInfo of interfaces
+// is left unset.
+func (hostSystem) linkInfos() (map[int]LinkInfo, error) {
+	return nil, errors.New("link information is not supported on this platform")
+}
@@ -0,0 +1,197 @@
+package sockaddr
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// LinkStats are the traffic counters of an interface since it was created.
+type LinkStats struct {
+	RxBytes  uint64
+	TxBytes  uint64
+	RxErrors uint64
+	TxErrors uint64
+}
+
+// linkStateKnown returns true if the operational state and counters of the
+// link were read, i.e. on Linux.
+func linkStateKnown(link LinkInfo) bool {
+	return link.OperState != ""
+}
+
+// carrierString returns "true" or "false" for the carrier of a link, or an
+// empty string if the state of the link is unknown.
+func carrierString(link LinkInfo) string {
+	if !linkStateKnown(link) {
+		return ""
+	}
+	return strconv.FormatBool(link.Carrier)
+}
+
+// speedString returns the speed of a link in Mbit/s, or an empty string if it
+// is unknown.
+func speedString(link LinkInfo) string {
+	if link.SpeedMbps <= 0 {
+		return ""
+	}
+	return strconv.Itoa(link.SpeedMbps)
+}
+
+// counterString returns a traffic counter of a link, or an empty string if the
+// state of the link is unknown.
+func counterString(link LinkInfo, counter uint64) string {
+	if !linkStateKnown(link) {
+		return ""
+	}
+	return strconv.FormatUint(counter, 10)
+}
+
+// readSysfsLinkState sets the operational state, carrier, speed and duplex of
+// link from the sysfs directory of an interface (e.g. /sys/class/net/eth0).
+// The carrier, speed and duplex can not be read while an interface is down,
+// in which case they are left unset.
+func readSysfsLinkState(dir string, link *LinkInfo) error {
+	read := func(name string) (string, error) {
+		b, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(b)), nil
+	}
+
+	operState, err := read("operstate")
+	if err != nil {
+		return fmt.Errorf("unable to read the operational state: %v", err)
+	}
+	link.OperState = operState
+
+	if carrier, err := read("carrier"); err == nil {
+		link.Carrier = carrier == "1"
+	}
+	if speed, err := read("speed"); err == nil {
+		if mbps, err := strconv.Atoi(speed); err == nil && mbps > 0 {
+			link.SpeedMbps = mbps
+		}
+	}
+	if duplex, err := read("duplex"); err == nil {
+		link.Duplex = duplex
+	}
+
+	return nil
+}
+
+// sysfsDevTypeKinds are the kinds of the interfaces by the DEVTYPE of their
+// uevent file in sysfs.  Wireless interfaces are physical.
+var sysfsDevTypeKinds = map[string]string{
+	"bond":   KindBond,
+	"bridge": KindBridge,
+	"vlan":   KindVLAN,
+	"wlan":   KindPhysical,
+	"wwan":   KindPhysical,
+}
+
+// readSysfsLinkKind sets the kind and master of link from the sysfs directory
+// of an interface (e.g. /sys/class/net/eth0) for when netlink is unavailable.
+// The kind is derived from the "tun_flags" file of tun and tap devices, the
+// "bridge" and "bonding" directories, the DEVTYPE of the "uevent" file, and
+// the "device" link of physical interfaces, and the master from the "master"
+// link.  The kind of other virtual interfaces (e.g. veth) and the VLAN ID of
+// VLAN interfaces can not be read from sysfs and are left unset.
+func readSysfsLinkKind(dir string, flags net.Flags, link *LinkInfo) {
+	exists := func(name string) bool {
+		_, err := os.Lstat(filepath.Join(dir, name))
+		return err == nil
+	}
+
+	var devType string
+	if b, err := os.ReadFile(filepath.Join(dir, "uevent")); err == nil {
+		for _, line := range strings.Split(string(b), "\n") {
+			if value, found := strings.CutPrefix(line, "DEVTYPE="); found {
+				devType = strings.TrimSpace(value)
+			}
+		}
+	}
+
+	switch {
+	case flags&net.FlagLoopback != 0:
+		link.Kind = KindLoopback
+	case exists("tun_flags"):
+		link.Kind = KindTun
+		if b, err := os.ReadFile(filepath.Join(dir, "tun_flags")); err == nil {
+			tunFlags, err := strconv.ParseUint(strings.TrimSpace(string(b)), 0, 16)
+			if err == nil && tunFlags&iffTap != 0 {
+				link.Kind = KindTap
+			}
+		}
+	case exists("bridge"):
+		link.Kind = KindBridge
+	case exists("bonding"):
+		link.Kind = KindBond
+	case devType != "":
+		if kind, found := sysfsDevTypeKinds[devType]; found {
+			link.Kind = kind
+		} else {
+			link.Kind = devType
+		}
+	case exists("device"):
+		link.Kind = KindPhysical
+	}
+
+	if master, err := os.Readlink(filepath.Join(dir, "master")); err == nil {
+		link.Master = filepath.Base(master)
+	}
+}
+
+// parseProcNetDev parses the traffic counters of /proc/net/dev by interface
+// name.
+func parseProcNetDev(r io.Reader) (map[string]LinkStats, error) {
+	stats := make(map[string]LinkStats)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		// The two header lines contain "|" and have no counters.
+		line := scanner.Text()
+		if strings.Contains(line, "|") {
+			continue
+		}
+
+		colon := strings.LastIndex(line, ":")
+		if colon < 0 {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			return nil, fmt.Errorf("unable to parse /proc/net/dev line %q", line)
+		}
+
+		name := strings.TrimSpace(line[:colon])
+		fields := strings.Fields(line[colon+1:])
+		if len(fields) < 16 {
+			return nil, fmt.Errorf("unable to parse /proc/net/dev line %q", line)
+		}
+
+		var counters [4]uint64
+		for i, field := range []int{0, 8, 2, 10} {
+			counter, err := strconv.ParseUint(fields[field], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("unable to parse counter %q of %s: %v", fields[field], name, err)
+			}
+			counters[i] = counter
+		}
+		stats[name] = LinkStats{
+			RxBytes:  counters[0],
+			TxBytes:  counters[1],
+			RxErrors: counters[2],
+			TxErrors: counters[3],
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,133 @@
+package sockaddr
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func Test_readSysfsLinkState(t *testing.T) {
+	tests := []struct {
+		name string
+		want LinkInfo
+		fail bool
+	}{
+		{
+			name: "eth0",
+			want: LinkInfo{OperState: "up", Carrier: true, SpeedMbps: 25000, Duplex: "full"},
+		},
+		{
+			// The carrier, speed and duplex of a down interface can
+			// not be read.
+			name: "ifb1",
+			want: LinkInfo{OperState: "down"},
+		},
+		{
+			name: "missing0",
+			fail: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var link LinkInfo
+			err := readSysfsLinkState(filepath.Join("testdata", "sys_class_net", test.name), &link)
+			if test.fail {
+				if err == nil {
+					t.Fatalf("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+			if link != test.want {
+				t.Errorf("want %+v, got %+v", test.want, link)
+			}
+		})
+	}
+}
+
+func Test_readSysfsLinkKind(t *testing.T) {
+	tests := []struct {
+		name  string
+		flags net.Flags
+		want  LinkInfo
+	}{
+		{name: "lo", flags: net.FlagUp | net.FlagLoopback, want: LinkInfo{Kind: KindLoopback}},
+		{name: "eth0", flags: net.FlagUp, want: LinkInfo{Kind: KindPhysical, Master: "br0"}},
+		{name: "br0", flags: net.FlagUp, want: LinkInfo{Kind: KindBridge}},
+		{name: "bond0", flags: net.FlagUp, want: LinkInfo{Kind: KindBond}},
+		{name: "vlan120", flags: net.FlagUp, want: LinkInfo{Kind: KindVLAN, Master: "bond0"}},
+		{name: "tap0", want: LinkInfo{Kind: KindTap}},
+		{name: "tun0", flags: net.FlagUp, want: LinkInfo{Kind: KindTun}},
+		{name: "wlan0", flags: net.FlagUp, want: LinkInfo{Kind: KindPhysical}},
+		{name: "wg0", flags: net.FlagUp, want: LinkInfo{Kind: KindWireGuard}},
+		{
+			// The kind of a veth or an ifb interface is only known
+			// to netlink.
+			name:  "veth0",
+			flags: net.FlagUp,
+		},
+		{name: "ifb1"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var link LinkInfo
+			readSysfsLinkKind(filepath.Join("testdata", "sys_class_net", test.name), test.flags, &link)
+			if link != test.want {
+				t.Errorf("want %+v, got %+v", test.want, link)
+			}
+		})
+	}
+}
+
+func Test_parseProcNetDev(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "proc_net_dev"))
+	if err != nil {
+		t.Fatalf("unable to open fixture: %v", err)
+	}
+	defer f.Close()
+
+	stats, err := parseProcNetDev(f)
+	if err != nil {
+		t.Fatalf("unable to parse fixture: %v", err)
+	}
+
+	want := map[string]LinkStats{
+		"lo":   {RxBytes: 98393833, TxBytes: 98393833},
+		"ifb0": {RxBytes: 140},
+		"ifb1": {},
+		"eth0": {RxBytes: 1453269, TxBytes: 32330},
+	}
+	if len(stats) != len(want) {
+		t.Errorf("want %d interfaces, got %d", len(want), len(stats))
+	}
+	for name, linkStats := range want {
+		if stats[name] != linkStats {
+			t.Errorf("%s: want %+v, got %+v", name, linkStats, stats[name])
+		}
+	}
+
+	// Large counters are not separated from the interface name.
+	stats, err = parseProcNetDev(strings.NewReader("  eth1:98765432101    7 2 0 0 0 0 0 55 8 0 0 0 0 0 0\n"))
+	if err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+	if want := (LinkStats{RxBytes: 98765432101, TxBytes: 55, RxErrors: 2}); stats["eth1"] != want {
+		t.Errorf("want %+v, got %+v", want, stats["eth1"])
+	}
+
+	for _, input := range []string{
+		"eth0 1 2 3",
+		"eth0: 1 2 3",
+		"eth0: x 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0",
+	} {
+		if _, err := parseProcNetDev(strings.NewReader(input)); err == nil {
+			t.Errorf("expected %q to fail", input)
+		}
+	}
+}
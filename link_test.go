@@ -0,0 +1,120 @@
+package sockaddr
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_parseNetlinkLinks(t *testing.T) {
+	if binary.NativeEndian.Uint16([]byte{1, 0}) != 1 {
+		t.Skip("fixture was captured on a little-endian host")
+	}
+
+	b, err := os.ReadFile(filepath.Join("testdata", "netlink_links"))
+	if err != nil {
+		t.Fatalf("unable to read fixture: %v", err)
+	}
+
+	links, err := parseNetlinkLinks(b)
+	if err != nil {
+		t.Fatalf("unable to parse fixture: %v", err)
+	}
+
+	want := map[int]LinkInfo{
+		1: {Kind: KindLoopback},
+		2: {Kind: "ifb"},
+		3: {Kind: "ifb"},
+		4: {Kind: KindPhysical},
+		5: {Kind: KindBridge},
+		6: {Kind: KindVeth},
+		7: {Kind: KindVeth, Master: "br-sa"},
+		8: {Kind: KindTap},
+		9: {Kind: KindTun},
+	}
+	if len(links) != len(want) {
+		t.Errorf("want %d links, got %d: %v", len(want), len(links), links)
+	}
+	for index, link := range want {
+		if links[index] != link {
+			t.Errorf("%d: want %+v, got %+v", index, link, links[index])
+		}
+	}
+
+	if _, err := parseNetlinkLinks(b[:len(b)-1]); err == nil {
+		t.Errorf("expected a truncated dump to fail")
+	}
+}
+
+func Test_parseNetlinkLinkVLAN(t *testing.T) {
+	attr := func(attrType uint16, value []byte) []byte {
+		b := make([]byte, netlinkAlign(rtaHdrLen+len(value)))
+		binary.NativeEndian.PutUint16(b[0:2], uint16(rtaHdrLen+len(value)))
+		binary.NativeEndian.PutUint16(b[2:4], attrType)
+		copy(b[rtaHdrLen:], value)
+		return b
+	}
+	u32 := func(v uint32) []byte {
+		b := make([]byte, 4)
+		binary.NativeEndian.PutUint32(b, v)
+		return b
+	}
+	u16 := func(v uint16) []byte {
+		b := make([]byte, 2)
+		binary.NativeEndian.PutUint16(b, v)
+		return b
+	}
+	concat := func(bs ...[]byte) []byte {
+		var out []byte
+		for _, b := range bs {
+			out = append(out, b...)
+		}
+		return out
+	}
+	message := func(index int, attrs []byte) []byte {
+		body := make([]byte, ifInfoMsgLen)
+		binary.NativeEndian.PutUint16(body[2:4], 1)
+		binary.NativeEndian.PutUint32(body[4:8], uint32(index))
+		body = append(body, attrs...)
+
+		hdr := make([]byte, nlmsgHdrLen)
+		binary.NativeEndian.PutUint32(hdr[0:4], uint32(nlmsgHdrLen+len(body)))
+		binary.NativeEndian.PutUint16(hdr[4:6], rtmNewLink)
+		return append(hdr, body...)
+	}
+
+	dump := concat(
+		message(2, concat(
+			attr(iflaIfName, []byte("bond0\x00")),
+			attr(iflaLinkInfo|0x8000, attr(iflaInfoKind, []byte("bond\x00"))),
+		)),
+		message(3, concat(
+			attr(iflaIfName, []byte("eth0\x00")),
+			attr(iflaMaster, u32(2)),
+		)),
+		message(4, concat(
+			attr(iflaIfName, []byte("bond0.42\x00")),
+			attr(iflaLinkInfo|0x8000, concat(
+				attr(iflaInfoKind, []byte("vlan\x00")),
+				attr(iflaInfoData|0x8000, attr(iflaVLANID, u16(42))),
+			)),
+		)),
+	)
+
+	links, err := parseNetlinkLinks(dump)
+	if err != nil {
+		t.Fatalf("unable to parse dump: %v", err)
+	}
+
+	want := map[int]LinkInfo{
+		2: {Kind: KindBond},
+		3: {Kind: KindPhysical, Master: "bond0"},
+		4: {Kind: KindVLAN, VLANID: 42},
+	}
+	for index, link := range want {
+		if links[index] != link {
+			t.Errorf("%d: want %+v, got %+v", index, link, links[index])
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package sockaddr
+
+import (
+	"fmt"
+	"math/big"
+	"net"
+	"strings"
+)
+
+// MulticastScope is the scope of a multicast group.  IPv6 scopes are taken
+// from the group address (RFC 4291, §2.7 and RFC 7346).  IPv4 scopes follow
+// the administratively scoped ranges from RFC 2365.
+type MulticastScope int
+
+const (
+	// MulticastScopeNone is returned for addresses that are not multicast
+	// groups.
+	MulticastScopeNone MulticastScope = iota
+	MulticastScopeInterfaceLocal
+	MulticastScopeLinkLocal
+	MulticastScopeRealmLocal
+	MulticastScopeAdminLocal
+	MulticastScopeSiteLocal
+	MulticastScopeOrganizationLocal
+	MulticastScopeGlobal
+	MulticastScopeReserved
+	MulticastScopeUnassigned
+)
+
+// MulticastFlags are the flag bits of an IPv6 multicast address (RFC 4291,
+// §2.7, RFC 3306, and RFC 3956).
+type MulticastFlags uint8
+
+const (
+	// MulticastFlagTransient is set for dynamically assigned groups.  Groups
+	// without it are well-known, permanently assigned groups.
+	MulticastFlagTransient MulticastFlags = 1 << iota
+
+	// MulticastFlagPrefix is set for unicast-prefix-based groups.
+	MulticastFlagPrefix
+
+	// MulticastFlagRendezvous is set for groups embedding the address of a
+	// rendezvous point.
+	MulticastFlagRendezvous
+)
+
+var (
+	// ipv4MulticastMACPrefix is the OUI used to map IPv4 groups to MAC
+	// addresses (RFC 1112, §6.4).
+	ipv4MulticastMACPrefix = []byte{0x01, 0x00, 0x5e}
+
+	// ipv6MulticastMACPrefix is the prefix used to map IPv6 groups to MAC
+	// addresses (RFC 2464, §7).
+	ipv6MulticastMACPrefix = []byte{0x33, 0x33}
+
+	// ipv6SolicitedNodePrefix is ff02::1:ff00:0/104 (RFC 4291, §2.7.1).
+	ipv6SolicitedNodePrefix = newIPv6Prefix("ff0200000000000000000001ff000000", 104)
+
+	ipv4MulticastAllAddresses = IPv4Addr{Address: 0xe0000000, Mask: 0xf0000000} // 224.0.0.0/4
+	ipv4MulticastLinkLocal    = IPv4Addr{Address: 0xe0000000, Mask: 0xffffff00} // 224.0.0.0/24
+	ipv4MulticastAdminScoped  = IPv4Addr{Address: 0xef000000, Mask: 0xff000000} // 239.0.0.0/8
+	ipv4MulticastOrgLocal     = IPv4Addr{Address: 0xefc00000, Mask: 0xfffc0000} // 239.192.0.0/14
+	ipv4MulticastLocalScope   = IPv4Addr{Address: 0xefff0000, Mask: 0xffff0000} // 239.255.0.0/16
+)
+
+// MulticastMAC returns the Ethernet group address that an IPv4 or IPv6
+// multicast group maps to: 01:00:5e followed by the low 23 bits of an IPv4
+// group, or 33:33 followed by the low 32 bits of an IPv6 group.
+func MulticastMAC(ip IPAddr) (net.HardwareAddr, error) {
+	if ip == nil || !IsMulticast(ip) {
+		return nil, fmt.Errorf("%v is not a multicast address", ip)
+	}
+
+	netIP := *ip.NetIP()
+	switch ip.Type() {
+	case TypeIPv4:
+		mac := append(net.HardwareAddr(nil), ipv4MulticastMACPrefix...)
+		return append(mac, netIP[1]&0x7f, netIP[2], netIP[3]), nil
+	case TypeIPv6:
+		mac := append(net.HardwareAddr(nil), ipv6MulticastMACPrefix...)
+		return append(mac, netIP[12:16]...), nil
+	default:
+		return nil, fmt.Errorf("unsupported type %s", ip.Type())
+	}
+}
+
+// IsMulticast returns true if the SockAddr is an IPv4 or IPv6 multicast
+// address.
+func IsMulticast(sa SockAddr) bool {
+	switch v := sa.(type) {
+	case IPv4Addr:
+		return ipv4MulticastAllAddresses.ContainsAddress(v.Address)
+	case IPv6Addr:
+		// ff00::/8
+		return (*v.NetIP())[0] == 0xff
+	default:
+		return false
+	}
+}
+
+// ParseMulticastScope returns the MulticastScope matching a scope name as
+// returned by MulticastScope.String().  The "-local" suffix is optional.
+func ParseMulticastScope(s string) (MulticastScope, error) {
+	name := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(s)), "-local")
+	for scope := MulticastScopeNone; scope <= MulticastScopeUnassigned; scope++ {
+		if scope.String() == name {
+			return scope, nil
+		}
+	}
+
+	return MulticastScopeNone, fmt.Errorf("unknown multicast scope %q", s)
+}
+
+// MulticastScopeOf returns the scope of an IPv4 or IPv6 multicast group, or
+// MulticastScopeNone if sa is not a multicast address.
+func MulticastScopeOf(sa SockAddr) MulticastScope {
+	switch v := sa.(type) {
+	case IPv4Addr:
+		return v.MulticastScope()
+	case IPv6Addr:
+		return v.MulticastScope()
+	default:
+		return MulticastScopeNone
+	}
+}
+
+// String returns the short name of the scope (e.g. "link", "admin", or
+// "global").
+func (s MulticastScope) String() string {
+	switch s {
+	case MulticastScopeNone:
+		return "none"
+	case MulticastScopeInterfaceLocal:
+		return "interface"
+	case MulticastScopeLinkLocal:
+		return "link"
+	case MulticastScopeRealmLocal:
+		return "realm"
+	case MulticastScopeAdminLocal:
+		return "admin"
+	case MulticastScopeSiteLocal:
+		return "site"
+	case MulticastScopeOrganizationLocal:
+		return "organization"
+	case MulticastScopeGlobal:
+		return "global"
+	case MulticastScopeReserved:
+		return "reserved"
+	default:
+		return "unassigned"
+	}
+}
+
+// String returns the names of the set flags joined by "|".  Groups without the
+// transient flag are reported as "permanent".
+func (f MulticastFlags) String() string {
+	var names []string
+	if f&MulticastFlagTransient != 0 {
+		names = append(names, "transient")
+	} else {
+		names = append(names, "permanent")
+	}
+	if f&MulticastFlagPrefix != 0 {
+		names = append(names, "prefix")
+	}
+	if f&MulticastFlagRendezvous != 0 {
+		names = append(names, "rendezvous")
+	}
+	return strings.Join(names, "|")
+}
+
+// ipv6MulticastScopes maps the 4-bit scope field of an IPv6 multicast address
+// to a MulticastScope.
+var ipv6MulticastScopes = [16]MulticastScope{
+	0x0: MulticastScopeReserved,
+	0x1: MulticastScopeInterfaceLocal,
+	0x2: MulticastScopeLinkLocal,
+	0x3: MulticastScopeRealmLocal,
+	0x4: MulticastScopeAdminLocal,
+	0x5: MulticastScopeSiteLocal,
+	0x6: MulticastScopeUnassigned,
+	0x7: MulticastScopeUnassigned,
+	0x8: MulticastScopeOrganizationLocal,
+	0x9: MulticastScopeUnassigned,
+	0xa: MulticastScopeUnassigned,
+	0xb: MulticastScopeUnassigned,
+	0xc: MulticastScopeUnassigned,
+	0xd: MulticastScopeUnassigned,
+	0xe: MulticastScopeGlobal,
+	0xf: MulticastScopeReserved,
+}
+
+// newIPv6Prefix returns the IPv6Addr for a hex encoded address and prefix
+// length.  Package-level variables use it because NewIPv6Addr depends on
+// state that is only set up in init().
+func newIPv6Prefix(hexAddr string, prefixLen int) IPv6Addr {
+	addr, ok := new(big.Int).SetString(hexAddr, 16)
+	if !ok {
+		panic(fmt.Sprintf("invalid IPv6 address %q", hexAddr))
+	}
+
+	mask := new(big.Int).Lsh(big.NewInt(1), uint(prefixLen))
+	mask.Sub(mask, big.NewInt(1))
+	mask.Lsh(mask, uint(IPv6len*8-prefixLen))
+
+	return IPv6Addr{
+		Address: IPv6Address(addr),
+		Mask:    IPv6Mask(mask),
+	}
+}
+
+// ipv6SolicitedNode returns the solicited-node multicast group for address.
+func ipv6SolicitedNode(address IPv6Address) IPv6Addr {
+	low24 := new(big.Int).And(address, big.NewInt(0xffffff))
+	group := new(big.Int).Or(ipv6SolicitedNodePrefix.Address, low24)
+	return IPv6Addr{
+		Address: IPv6Address(group),
+		Mask:    ipv6HostMask,
+	}
+}
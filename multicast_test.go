@@ -0,0 +1,143 @@
+package sockaddr_test
+
+import (
+	"testing"
+
+	sockaddr "github.com/hashicorp/go-sockaddr"
+)
+
+func TestMulticastMAC(t *testing.T) {
+	tests := []struct {
+		addr string
+		want string
+		fail bool
+	}{
+		{addr: "224.0.0.251", want: "01:00:5e:00:00:fb"},
+		{addr: "239.255.255.250", want: "01:00:5e:7f:ff:fa"},
+		{addr: "ff02::1", want: "33:33:00:00:00:01"},
+		{addr: "ff02::1:ff12:3456", want: "33:33:ff:12:34:56"},
+		{addr: "192.168.1.1", fail: true},
+		{addr: "2001:db8::1", fail: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.addr, func(t *testing.T) {
+			mac, err := sockaddr.MulticastMAC(sockaddr.MustIPAddr(test.addr))
+			if test.fail {
+				if err == nil {
+					t.Fatalf("expected failure, got %s", mac)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unable to map %s: %v", test.addr, err)
+			}
+			if mac.String() != test.want {
+				t.Fatalf("want %s, got %s", test.want, mac)
+			}
+		})
+	}
+}
+
+func TestMulticastScope(t *testing.T) {
+	tests := []struct {
+		addr  string
+		scope string
+		flags string
+		admin bool
+	}{
+		{addr: "192.168.1.1", scope: "none"},
+		{addr: "224.0.0.1", scope: "link"},
+		{addr: "224.0.1.1", scope: "global"},
+		{addr: "239.1.2.3", scope: "admin", admin: true},
+		{addr: "239.192.0.1", scope: "organization", admin: true},
+		{addr: "239.255.255.250", scope: "site", admin: true},
+		{addr: "2001:db8::1", scope: "none"},
+		{addr: "ff01::1", scope: "interface", flags: "permanent"},
+		{addr: "ff02::1", scope: "link", flags: "permanent"},
+		{addr: "ff05::1:3", scope: "site", flags: "permanent"},
+		{addr: "ff18::1", scope: "organization", flags: "transient"},
+		{addr: "ff3e:30:2001:db8::1", scope: "global", flags: "transient|prefix"},
+		{addr: "ff7e:140:2001:db8::1", scope: "global", flags: "transient|prefix|rendezvous"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.addr, func(t *testing.T) {
+			ip := sockaddr.MustIPAddr(test.addr)
+			if scope := sockaddr.MulticastScopeOf(ip); scope.String() != test.scope {
+				t.Fatalf("want scope %s, got %s", test.scope, scope)
+			}
+
+			switch v := ip.(type) {
+			case sockaddr.IPv4Addr:
+				if v.IsAdminScopedMulticast() != test.admin {
+					t.Fatalf("want admin scoped %t", test.admin)
+				}
+			case sockaddr.IPv6Addr:
+				if flags, _ := sockaddr.Attr(v, "multicast_flags"); flags != test.flags {
+					t.Fatalf("want flags %q, got %q", test.flags, flags)
+				}
+			}
+		})
+	}
+}
+
+func TestIPv6Addr_SolicitedNodeMulticast(t *testing.T) {
+	tests := []struct {
+		addr string
+		want string
+		fail bool
+	}{
+		{addr: "2001:db8::1:2:3", want: "ff02::1:ff02:3"},
+		{addr: "fe80::21b:21ff:fe12:3456/64", want: "ff02::1:ff12:3456"},
+		{addr: "::", want: "ff02::1:ff00:0"},
+		{addr: "ff02::1", fail: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.addr, func(t *testing.T) {
+			group, err := sockaddr.MustIPv6Addr(test.addr).SolicitedNodeMulticast()
+			if test.fail {
+				if err == nil {
+					t.Fatalf("expected failure, got %s", group)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unable to compute solicited-node address: %v", err)
+			}
+			if group.String() != test.want {
+				t.Fatalf("want %s, got %s", test.want, group)
+			}
+		})
+	}
+}
+
+func TestIfByMulticastScope(t *testing.T) {
+	ifAddrs := sockaddr.IfAddrs{
+		{SockAddr: sockaddr.MustIPAddr("224.0.0.251")},
+		{SockAddr: sockaddr.MustIPAddr("ff02::fb")},
+		{SockAddr: sockaddr.MustIPAddr("ff05::1:3")},
+		{SockAddr: sockaddr.MustIPAddr("10.0.0.1")},
+	}
+
+	matched, remainder, err := sockaddr.IfByMulticastScope("link", ifAddrs)
+	if err != nil {
+		t.Fatalf("unable to filter: %v", err)
+	}
+	if len(matched) != 2 || len(remainder) != 2 {
+		t.Fatalf("want 2 matched and 2 remaining, got %v and %v", matched, remainder)
+	}
+
+	included, err := sockaddr.IncludeIfs("multicast_scope", "site-local|none", ifAddrs)
+	if err != nil {
+		t.Fatalf("unable to include: %v", err)
+	}
+	if len(included) != 2 || included[0].SockAddr.String() != "ff05::1:3" || included[1].SockAddr.String() != "10.0.0.1" {
+		t.Fatalf("unexpected result: %v", included)
+	}
+
+	if _, _, err := sockaddr.IfByMulticastScope("galactic", ifAddrs); err == nil {
+		t.Fatalf("expected an invalid scope to fail")
+	}
+}
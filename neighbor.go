@@ -0,0 +1,277 @@
+package sockaddr
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// Neighbor states reported by the "neighbor_state" attribute.  They are the
+// states of the kernel's neighbor unreachability detection (RFC 4861).
+const (
+	NeighborStateIncomplete = "incomplete"
+	NeighborStateReachable  = "reachable"
+	NeighborStateStale      = "stale"
+	NeighborStateDelay      = "delay"
+	NeighborStateProbe      = "probe"
+	NeighborStateFailed     = "failed"
+	NeighborStatePermanent  = "permanent"
+	NeighborStateNone       = "none"
+)
+
+// NeighborInfo is the neighbor table entry of an IfAddr returned by
+// GetNeighbors.  The NeighborInfo of interface addresses is empty.
+type NeighborInfo struct {
+	// HardwareAddr is the link-layer address of the neighbor.  It is empty
+	// while the neighbor is being resolved or if resolving it failed.
+	HardwareAddr net.HardwareAddr
+
+	// State is the state of the entry, e.g. NeighborStateReachable.
+	State string
+}
+
+// neighborEntry is an entry of the neighbor table of a SystemProvider.  Its
+// interface is identified by index, or by name if the index is zero.
+type neighborEntry struct {
+	index  int
+	ifName string
+	ip     IPAddr
+	info   NeighborInfo
+}
+
+// neighborReader is implemented by SystemProviders that can read the neighbor
+// table.  GetNeighbors uses it to list the neighbors of each interface.
+type neighborReader interface {
+	neighbors() ([]neighborEntry, error)
+}
+
+// GetNeighbors returns the entries of the neighbor table (the ARP cache for
+// IPv4 and the NDP neighbor cache for IPv6) as IfAddrs.  The SockAddr of each
+// IfAddr is the IP address of the neighbor, its Interface is the interface the
+// neighbor was seen on, and its Neighbor holds the link-layer address and
+// state of the entry.  The neighbor table can only be read on Linux.
+//
+// $ sockaddr eval -r '{{GetNeighbors | include "neighbor_state" "reachable" | include "name" "eth0" | join "address" " "}}'
+func GetNeighbors() (IfAddrs, error) {
+	return defaultSystem().GetNeighbors()
+}
+
+// GetNeighbors is like the package-level GetNeighbors, but inspects the host of
+// s.
+func (s *System) GetNeighbors() (IfAddrs, error) {
+	provider := s.systemProvider()
+	reader, ok := provider.(neighborReader)
+	if !ok {
+		return IfAddrs{}, fmt.Errorf("unable to read the neighbor table: not supported by %T", provider)
+	}
+
+	entries, err := reader.neighbors()
+	if err != nil {
+		return IfAddrs{}, fmt.Errorf("unable to read the neighbor table: %v", err)
+	}
+
+	ifs, err := provider.Interfaces()
+	if err != nil {
+		return IfAddrs{}, err
+	}
+
+	ifAddrs := make(IfAddrs, 0, len(entries))
+	for _, intf := range ifs {
+		for _, entry := range entries {
+			if entry.index != intf.Index && (entry.index != 0 || entry.ifName != intf.Name) {
+				continue
+			}
+
+			ifAddrs = append(ifAddrs, IfAddr{
+				SockAddr:  entry.ip,
+				Interface: intf,
+				Neighbor:  entry.info,
+			})
+		}
+	}
+
+	return s.bind(ifAddrs), nil
+}
+
+// Neighbor states and attributes from the Linux kernel's
+// <linux/neighbour.h>.
+const (
+	rtmNewNeigh = 28
+	ndMsgLen    = 12
+	ndaDst      = 1
+	ndaLLAddr   = 2
+	nudNoARP    = 0x40
+)
+
+// nudStateNames are the names of the kernel's NUD_* states in the order they
+// are looked up.
+var nudStateNames = []struct {
+	state uint16
+	name  string
+}{
+	{0x01, NeighborStateIncomplete},
+	{0x02, NeighborStateReachable},
+	{0x04, NeighborStateStale},
+	{0x08, NeighborStateDelay},
+	{0x10, NeighborStateProbe},
+	{0x20, NeighborStateFailed},
+	{0x80, NeighborStatePermanent},
+}
+
+// nudStateName returns the name of a NUD_* state reported by the kernel.
+func nudStateName(state uint16) string {
+	for _, n := range nudStateNames {
+		if state&n.state != 0 {
+			return n.name
+		}
+	}
+	return NeighborStateNone
+}
+
+// parseNetlinkNeighbors parses the RTM_NEWNEIGH messages of a netlink neighbor
+// dump in host byte order.  Like `ip neigh`, entries in the NUD_NOARP state,
+// which the kernel creates for multicast and broadcast addresses, are skipped.
+func parseNetlinkNeighbors(b []byte) ([]neighborEntry, error) {
+	var entries []neighborEntry
+	for len(b) > 0 {
+		if len(b) < nlmsgHdrLen {
+			return nil, fmt.Errorf("truncated netlink message header")
+		}
+		msgLen := int(binary.NativeEndian.Uint32(b[0:4]))
+		msgType := binary.NativeEndian.Uint16(b[4:6])
+		if msgLen < nlmsgHdrLen || msgLen > len(b) {
+			return nil, fmt.Errorf("invalid netlink message length %d", msgLen)
+		}
+		data := b[nlmsgHdrLen:msgLen]
+		b = b[min(netlinkAlign(msgLen), len(b)):]
+
+		switch msgType {
+		case nlmsgDone:
+			continue
+		case nlmsgError:
+			return nil, fmt.Errorf("netlink neighbor dump failed")
+		case rtmNewNeigh:
+		default:
+			continue
+		}
+
+		entry, ok, err := parseNetlinkNeighbor(data)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries, nil
+}
+
+// parseNetlinkNeighbor parses the body of a single RTM_NEWNEIGH message.
+// Returns false if the neighbor is not an IPv4 or IPv6 neighbor or is in the
+// NUD_NOARP state.
+func parseNetlinkNeighbor(data []byte) (neighborEntry, bool, error) {
+	if len(data) < ndMsgLen {
+		return neighborEntry{}, false, fmt.Errorf("truncated neighbor message")
+	}
+
+	var addrLen int
+	switch data[0] {
+	case afInet:
+		addrLen = IPv4len
+	case afInet6:
+		addrLen = IPv6len
+	default:
+		return neighborEntry{}, false, nil
+	}
+
+	state := binary.NativeEndian.Uint16(data[8:10])
+	if state&nudNoARP != 0 {
+		return neighborEntry{}, false, nil
+	}
+
+	entry := neighborEntry{
+		index: int(int32(binary.NativeEndian.Uint32(data[4:8]))),
+		info:  NeighborInfo{State: nudStateName(state)},
+	}
+	err := visitNetlinkAttrs(data[ndMsgLen:], func(attrType uint16, value []byte) error {
+		switch attrType {
+		case ndaDst:
+			if len(value) != addrLen {
+				return fmt.Errorf("invalid address length %d in neighbor attribute %d", len(value), attrType)
+			}
+			entry.ip = netlinkIPAddr(value, addrLen*8)
+		case ndaLLAddr:
+			entry.info.HardwareAddr = append(net.HardwareAddr(nil), value...)
+		}
+		return nil
+	})
+	if err != nil {
+		return neighborEntry{}, false, err
+	}
+	if entry.ip == nil {
+		return neighborEntry{}, false, nil
+	}
+
+	return entry, true, nil
+}
+
+// ARP flags from the Linux kernel's <linux/if_arp.h>.
+const (
+	atfComplete  = 0x02
+	atfPermanent = 0x04
+)
+
+// parseProcNetARP parses the IPv4 neighbors of /proc/net/arp.  The file only
+// records whether an entry is complete, so complete entries are reported as
+// NeighborStateReachable even if the kernel considers them stale, and
+// incomplete entries as NeighborStateIncomplete even if resolving them failed.
+func parseProcNetARP(r io.Reader) ([]neighborEntry, error) {
+	var entries []neighborEntry
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || fields[0] == "IP" {
+			continue
+		}
+		if len(fields) != 6 {
+			return nil, fmt.Errorf("unable to parse /proc/net/arp line %q", scanner.Text())
+		}
+
+		ip, err := NewIPv4Addr(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse address %q: %v", fields[0], err)
+		}
+		flags, err := strconv.ParseUint(fields[2], 0, 32)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse ARP flags %q: %v", fields[2], err)
+		}
+
+		entry := neighborEntry{ifName: fields[5], ip: ip}
+		switch {
+		case flags&atfPermanent != 0:
+			entry.info.State = NeighborStatePermanent
+		case flags&atfComplete != 0:
+			entry.info.State = NeighborStateReachable
+		default:
+			entry.info.State = NeighborStateIncomplete
+		}
+		if flags&atfComplete != 0 {
+			hwAddr, err := net.ParseMAC(fields[3])
+			if err != nil {
+				return nil, fmt.Errorf("unable to parse hardware address %q: %v", fields[3], err)
+			}
+			entry.info.HardwareAddr = hwAddr
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,24 @@
+package sockaddr
+
+import (
+	"os"
+	"syscall"
+)
+
+// neighbors reads the neighbor table of the host from netlink, falling back to
+// the IPv4 neighbors of /proc/net/arp if netlink is unavailable.
+func (hostSystem) neighbors() ([]neighborEntry, error) {
+	if b, err := syscall.NetlinkRIB(syscall.RTM_GETNEIGH, syscall.AF_UNSPEC); err == nil {
+		if entries, err := parseNetlinkNeighbors(b); err == nil {
+			return entries, nil
+		}
+	}
+
+	f, err := os.Open("/proc/net/arp")
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return parseProcNetARP(f)
+}
@@ -0,0 +1,10 @@
+// +build !linux
+
+package sockaddr
+
+import "errors"
+
+// neighbors is not supported on this platform.
+func (hostSystem) neighbors() ([]neighborEntry, error) {
+	return nil, errors.New("the neighbor table is not supported on this platform")
+}
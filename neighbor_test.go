@@ -0,0 +1,155 @@
+package sockaddr
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// neighborSummary returns the index or interface name, address, link-layer
+// address and state of a neighbor table entry.
+func neighborSummary(entry neighborEntry) string {
+	ifName := entry.ifName
+	if entry.index != 0 {
+		ifName = fmt.Sprintf("#%d", entry.index)
+	}
+	return strings.Join([]string{ifName, entry.ip.String(), entry.info.HardwareAddr.String(), entry.info.State}, " ")
+}
+
+func Test_parseNetlinkNeighbors(t *testing.T) {
+	b, err := os.ReadFile(filepath.Join("testdata", "netlink_neighbors"))
+	if err != nil {
+		t.Fatalf("unable to read fixture: %v", err)
+	}
+
+	entries, err := parseNetlinkNeighbors(b)
+	if err != nil {
+		t.Fatalf("unable to parse fixture: %v", err)
+	}
+
+	// The multicast and broadcast entries in the NUD_NOARP state are
+	// skipped.
+	want := []string{
+		"#10 198.51.100.11 02:00:5e:00:53:0b reachable",
+		"#10 198.51.100.10 02:00:5e:00:53:0a permanent",
+		"#4 192.0.2.1 02:fc:00:00:00:05 stale",
+		"#10 198.51.100.13  failed",
+		"#10 198.51.100.12 02:00:5e:00:53:0c stale",
+		"#10 2001:db8:5::20 02:00:5e:00:53:14 reachable",
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("want %d entries, got %d", len(want), len(entries))
+	}
+	for i, entry := range entries {
+		if got := neighborSummary(entry); got != want[i] {
+			t.Errorf("[%d] want %q, got %q", i, want[i], got)
+		}
+	}
+
+	if _, err := parseNetlinkNeighbors(b[:len(b)-1]); err == nil {
+		t.Errorf("expected a truncated dump to fail")
+	}
+}
+
+func Test_parseProcNetARP(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "proc_net_arp"))
+	if err != nil {
+		t.Fatalf("unable to open fixture: %v", err)
+	}
+	defer f.Close()
+
+	entries, err := parseProcNetARP(f)
+	if err != nil {
+		t.Fatalf("unable to parse fixture: %v", err)
+	}
+
+	// /proc/net/arp does not distinguish stale from reachable entries, or
+	// failed from incomplete entries.
+	want := []string{
+		"br-nb 198.51.100.11 02:00:5e:00:53:0b reachable",
+		"br-nb 198.51.100.10 02:00:5e:00:53:0a permanent",
+		"eth0 192.0.2.1 02:fc:00:00:00:05 reachable",
+		"br-nb 198.51.100.13  incomplete",
+		"br-nb 198.51.100.12 02:00:5e:00:53:0c reachable",
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("want %d entries, got %d", len(want), len(entries))
+	}
+	for i, entry := range entries {
+		if got := neighborSummary(entry); got != want[i] {
+			t.Errorf("[%d] want %q, got %q", i, want[i], got)
+		}
+	}
+
+	for _, input := range []string{
+		"198.51.100.11 0x1 0x2 02:00:5e:00:53:0b *",
+		"198.51.100.256 0x1 0x2 02:00:5e:00:53:0b * br-nb",
+		"198.51.100.11 0x1 0x2 02:00:5e:00:53 * br-nb",
+	} {
+		if _, err := parseProcNetARP(strings.NewReader(input)); err == nil {
+			t.Errorf("expected %q to fail", input)
+		}
+	}
+}
+
+// minimalSystem is a SystemProvider that only implements SystemProvider, e.g.
+// it can not read the neighbor table.
+type minimalSystem struct {
+	fake *FakeSystem
+}
+
+func (s minimalSystem) Interfaces() ([]net.Interface, error) {
+	return s.fake.Interfaces()
+}
+
+func (s minimalSystem) Addrs(ifi net.Interface) ([]net.Addr, error) {
+	return s.fake.Addrs(ifi)
+}
+
+func (s minimalSystem) RouteInfo(ctx context.Context) (RouteInterface, error) {
+	return s.fake.RouteInfo(ctx)
+}
+
+func TestGetNeighbors(t *testing.T) {
+	fake := NewFakeSystem().
+		AddInterface(net.Interface{Name: "eth0", Flags: net.FlagUp}, MustIPv4Addr("10.0.0.5/24")).
+		AddInterface(net.Interface{Name: "eth1", Flags: net.FlagUp}, MustIPv4Addr("192.168.1.5/24")).
+		AddNeighbor("eth1", MustIPv4Addr("192.168.1.1"), NeighborInfo{
+			HardwareAddr: net.HardwareAddr{0x02, 0, 0x5e, 0, 0x53, 0x01},
+			State:        NeighborStateReachable,
+		}).
+		AddNeighbor("eth0", MustIPv4Addr("10.0.0.9"), NeighborInfo{State: NeighborStateFailed}).
+		AddNeighbor("eth9", MustIPv4Addr("172.16.0.1"), NeighborInfo{State: NeighborStateStale})
+	SetSystemProvider(fake)
+	defer SetSystemProvider(nil)
+
+	ifAddrs, err := GetNeighbors()
+	if err != nil {
+		t.Fatalf("unable to get neighbors: %v", err)
+	}
+
+	// Neighbors are ordered by interface, and neighbors of unknown
+	// interfaces are skipped.
+	want := []string{
+		"eth0 10.0.0.9  failed",
+		"eth1 192.168.1.1 02:00:5e:00:53:01 reachable",
+	}
+	if len(ifAddrs) != len(want) {
+		t.Fatalf("want %d neighbors, got %d", len(want), len(ifAddrs))
+	}
+	for i, ifAddr := range ifAddrs {
+		got := strings.Join([]string{ifAddr.Name, ifAddr.SockAddr.String(), IfAddrAttr(ifAddr, "lladdr"), IfAddrAttr(ifAddr, "neighbor_state")}, " ")
+		if got != want[i] {
+			t.Errorf("[%d] want %q, got %q", i, want[i], got)
+		}
+	}
+
+	SetSystemProvider(minimalSystem{fake: fake})
+	if _, err := GetNeighbors(); err == nil {
+		t.Errorf("expected a SystemProvider without a neighbor table to fail")
+	}
+}
@@ -0,0 +1,260 @@
+package sockaddr
+
+import (
+	"math/big"
+	"sort"
+)
+
+// OverlapType describes how two SockAddrs overlap one another.
+type OverlapType int
+
+const (
+	// OverlapNone indicates the two SockAddrs share no addresses.
+	OverlapNone OverlapType = iota
+
+	// OverlapEqual indicates both SockAddrs cover the same range of
+	// addresses.
+	OverlapEqual
+
+	// OverlapContains indicates the first SockAddr covers all of the
+	// addresses of the second SockAddr and then some.
+	OverlapContains
+
+	// OverlapContainedBy indicates the first SockAddr is covered by the
+	// second SockAddr.
+	OverlapContainedBy
+
+	// OverlapPartial indicates the two SockAddrs share some, but not all,
+	// of their addresses and neither contains the other.  CIDR blocks can
+	// only nest, so this is only reported for addresses whose masks are not
+	// contiguous.
+	OverlapPartial
+)
+
+// Overlap is a pair of conflicting SockAddrs returned by FindOverlaps.  Type
+// describes the relationship of A to B.
+type Overlap struct {
+	A    SockAddr
+	B    SockAddr
+	Type OverlapType
+}
+
+// ipRange is the inclusive range of addresses covered by an IPAddr.  idx is
+// the position of the IPAddr in the input passed to FindOverlaps.
+type ipRange struct {
+	first *big.Int
+	last  *big.Int
+	idx   int
+}
+
+// overlapPair is an Overlap expressed as positions in the input to
+// FindOverlaps.
+type overlapPair struct {
+	a, b int
+	ot   OverlapType
+}
+
+// String returns a string representation of the OverlapType (e.g. "equal",
+// "contains", "contained-by", "partial", or "none").
+func (ot OverlapType) String() string {
+	switch ot {
+	case OverlapNone:
+		return "none"
+	case OverlapEqual:
+		return "equal"
+	case OverlapContains:
+		return "contains"
+	case OverlapContainedBy:
+		return "contained-by"
+	case OverlapPartial:
+		return "partial"
+	default:
+		return "unknown"
+	}
+}
+
+// invert returns the OverlapType as seen from the other SockAddr in the pair.
+func (ot OverlapType) invert() OverlapType {
+	switch ot {
+	case OverlapContains:
+		return OverlapContainedBy
+	case OverlapContainedBy:
+		return OverlapContains
+	default:
+		return ot
+	}
+}
+
+// Overlaps returns true if the SockAddrs share at least one address.  SockAddrs
+// of different types never overlap.
+func Overlaps(a, b SockAddr) bool {
+	return OverlapBetween(a, b) != OverlapNone
+}
+
+// OverlapBetween returns the relationship of a to b.  IP addresses are
+// compared by the network they belong to, UNIX sockets are compared by their
+// path.
+func OverlapBetween(a, b SockAddr) OverlapType {
+	if a == nil || b == nil || a.Type() != b.Type() {
+		return OverlapNone
+	}
+
+	switch a.Type() {
+	case TypeIPv4, TypeIPv6:
+		aFirst, aLast := ipAddrRange(*ToIPAddr(a))
+		bFirst, bLast := ipAddrRange(*ToIPAddr(b))
+		return cmpRanges(aFirst, aLast, bFirst, bLast)
+	case TypeUnix:
+		if a.(UnixSock).Path() == b.(UnixSock).Path() {
+			return OverlapEqual
+		}
+	}
+
+	return OverlapNone
+}
+
+// FindOverlaps returns every pair of conflicting SockAddrs in sas.  Pairs are
+// ordered by the position of their members in sas and the A member of each
+// pair always precedes its B member in sas.  Mixed IPv4, IPv6, and UNIX socket
+// input is supported, however only SockAddrs of the same type are compared.
+//
+// FindOverlaps sorts each address family by the start of its range and sweeps
+// the result, so it runs in O(n log n + k) time, where k is the number of
+// overlapping pairs found.
+func FindOverlaps(sas SockAddrs) []Overlap {
+	var v4Ranges, v6Ranges []ipRange
+	unixPaths := make(map[string][]int)
+	var unixOrder []string
+
+	for i, sa := range sas {
+		if sa == nil {
+			continue
+		}
+
+		switch sa.Type() {
+		case TypeIPv4, TypeIPv6:
+			first, last := ipAddrRange(*ToIPAddr(sa))
+			r := ipRange{first: first, last: last, idx: i}
+			if sa.Type() == TypeIPv4 {
+				v4Ranges = append(v4Ranges, r)
+			} else {
+				v6Ranges = append(v6Ranges, r)
+			}
+		case TypeUnix:
+			path := sa.(UnixSock).Path()
+			if _, found := unixPaths[path]; !found {
+				unixOrder = append(unixOrder, path)
+			}
+			unixPaths[path] = append(unixPaths[path], i)
+		}
+	}
+
+	var pairs []overlapPair
+	pairs = sweepRanges(v4Ranges, pairs)
+	pairs = sweepRanges(v6Ranges, pairs)
+	for _, path := range unixOrder {
+		idxs := unixPaths[path]
+		for i := 0; i < len(idxs); i++ {
+			for j := i + 1; j < len(idxs); j++ {
+				pairs = append(pairs, overlapPair{a: idxs[i], b: idxs[j], ot: OverlapEqual})
+			}
+		}
+	}
+
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].a != pairs[j].a {
+			return pairs[i].a < pairs[j].a
+		}
+		return pairs[i].b < pairs[j].b
+	})
+
+	overlaps := make([]Overlap, 0, len(pairs))
+	for _, p := range pairs {
+		overlaps = append(overlaps, Overlap{
+			A:    sas[p.a],
+			B:    sas[p.b],
+			Type: p.ot,
+		})
+	}
+
+	return overlaps
+}
+
+// sweepRanges appends every overlapping pair of ranges to pairs.  ranges must
+// all be of the same address family.
+func sweepRanges(ranges []ipRange, pairs []overlapPair) []overlapPair {
+	// Sort by the start of the range, and when two ranges start at the
+	// same address, sort the larger range first so that enclosing ranges
+	// are always visited before the ranges they contain.
+	sort.SliceStable(ranges, func(i, j int) bool {
+		switch ranges[i].first.Cmp(ranges[j].first) {
+		case -1:
+			return true
+		case 1:
+			return false
+		}
+		return ranges[i].last.Cmp(ranges[j].last) > 0
+	})
+
+	// active holds every previously visited range that has not ended
+	// before the current range starts.  Every member of active overlaps
+	// the current range, so the cost of pruning active is bounded by the
+	// number of pairs reported.
+	active := make([]ipRange, 0, len(ranges))
+	for _, cur := range ranges {
+		n := 0
+		for _, prev := range active {
+			if prev.last.Cmp(cur.first) < 0 {
+				continue
+			}
+			active[n] = prev
+			n++
+
+			ot := cmpRanges(prev.first, prev.last, cur.first, cur.last)
+			a, b := prev.idx, cur.idx
+			if a > b {
+				a, b = b, a
+				ot = ot.invert()
+			}
+			pairs = append(pairs, overlapPair{a: a, b: b, ot: ot})
+		}
+		active = append(active[:n], cur)
+	}
+
+	return pairs
+}
+
+// cmpRanges returns the relationship of the inclusive range [aFirst, aLast] to
+// the inclusive range [bFirst, bLast].
+func cmpRanges(aFirst, aLast, bFirst, bLast *big.Int) OverlapType {
+	if aLast.Cmp(bFirst) < 0 || bLast.Cmp(aFirst) < 0 {
+		return OverlapNone
+	}
+
+	firstCmp := aFirst.Cmp(bFirst)
+	lastCmp := aLast.Cmp(bLast)
+	switch {
+	case firstCmp == 0 && lastCmp == 0:
+		return OverlapEqual
+	case firstCmp <= 0 && lastCmp >= 0:
+		return OverlapContains
+	case firstCmp >= 0 && lastCmp <= 0:
+		return OverlapContainedBy
+	default:
+		return OverlapPartial
+	}
+}
+
+// ipAddrRange returns the first and last address of the network ip belongs to
+// as big.Ints.
+func ipAddrRange(ip IPAddr) (first, last *big.Int) {
+	switch v := ip.(type) {
+	case IPv4Addr:
+		first = big.NewInt(int64(v.NetworkAddress()))
+		last = big.NewInt(int64(v.BroadcastAddress()))
+	case IPv6Addr:
+		first = new(big.Int).Set(v.NetworkAddress())
+		last = new(big.Int).Set(v.LastUsable().(IPv6Addr).Address)
+	}
+	return first, last
+}
@@ -0,0 +1,162 @@
+package sockaddr_test
+
+import (
+	"fmt"
+	"testing"
+
+	sockaddr "github.com/hashicorp/go-sockaddr"
+)
+
+func TestOverlapBetween(t *testing.T) {
+	tests := []struct {
+		name string
+		a    sockaddr.SockAddr
+		b    sockaddr.SockAddr
+		want sockaddr.OverlapType
+	}{
+		{
+			name: "ipv4 equal",
+			a:    sockaddr.MustIPv4Addr("10.0.0.1/24"),
+			b:    sockaddr.MustIPv4Addr("10.0.0.200/24"),
+			want: sockaddr.OverlapEqual,
+		},
+		{
+			name: "ipv4 contains",
+			a:    sockaddr.MustIPv4Addr("10.0.0.0/8"),
+			b:    sockaddr.MustIPv4Addr("10.20.0.0/16"),
+			want: sockaddr.OverlapContains,
+		},
+		{
+			name: "ipv4 contained-by",
+			a:    sockaddr.MustIPv4Addr("10.20.30.40"),
+			b:    sockaddr.MustIPv4Addr("10.20.0.0/16"),
+			want: sockaddr.OverlapContainedBy,
+		},
+		{
+			name: "ipv4 none",
+			a:    sockaddr.MustIPv4Addr("10.0.0.0/24"),
+			b:    sockaddr.MustIPv4Addr("10.0.1.0/24"),
+			want: sockaddr.OverlapNone,
+		},
+		{
+			name: "ipv4 partial non-contiguous mask",
+			a:    sockaddr.IPv4Addr{Address: 0x0a000000, Mask: 0xffff00ff},
+			b:    sockaddr.MustIPv4Addr("10.0.128.0/17"),
+			want: sockaddr.OverlapPartial,
+		},
+		{
+			name: "ipv6 contains",
+			a:    sockaddr.MustIPv6Addr("2001:db8::/32"),
+			b:    sockaddr.MustIPv6Addr("2001:db8:1::/48"),
+			want: sockaddr.OverlapContains,
+		},
+		{
+			name: "ipv6 none",
+			a:    sockaddr.MustIPv6Addr("2001:db8::/48"),
+			b:    sockaddr.MustIPv6Addr("2001:db8:1::/48"),
+			want: sockaddr.OverlapNone,
+		},
+		{
+			name: "mixed families",
+			a:    sockaddr.MustIPv4Addr("0.0.0.0/0"),
+			b:    sockaddr.MustIPv6Addr("::/0"),
+			want: sockaddr.OverlapNone,
+		},
+		{
+			name: "unix equal",
+			a:    sockaddr.MustUnixSock("/tmp/foo"),
+			b:    sockaddr.MustUnixSock("/tmp/foo"),
+			want: sockaddr.OverlapEqual,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := sockaddr.OverlapBetween(test.a, test.b); got != test.want {
+				t.Fatalf("OverlapBetween(%s, %s): want %s, got %s", test.a, test.b, test.want, got)
+			}
+
+			if got := sockaddr.Overlaps(test.a, test.b); got != (test.want != sockaddr.OverlapNone) {
+				t.Fatalf("Overlaps(%s, %s): got %t", test.a, test.b, got)
+			}
+		})
+	}
+}
+
+func TestFindOverlaps(t *testing.T) {
+	tests := []struct {
+		name   string
+		inputs []string
+		want   []string
+	}{
+		{
+			name:   "disjoint",
+			inputs: []string{"10.0.0.0/24", "10.0.1.0/24", "2001:db8::/48", "2001:db8:1::/48"},
+			want:   []string{},
+		},
+		{
+			name: "vpc plan",
+			inputs: []string{
+				"10.0.0.0/16",
+				"192.168.0.0/24",
+				"10.0.1.0/24",
+				"2001:db8::/32",
+				"10.0.0.0/8",
+				"10.0.1.0/24",
+				"2001:db8:ff::/48",
+				"192.168.1.0/24",
+			},
+			want: []string{
+				"10.0.0.0/16 contains 10.0.1.0/24",
+				"10.0.0.0/16 contained-by 10.0.0.0/8",
+				"10.0.0.0/16 contains 10.0.1.0/24",
+				"10.0.1.0/24 contained-by 10.0.0.0/8",
+				"10.0.1.0/24 equal 10.0.1.0/24",
+				"2001:db8::/32 contains 2001:db8:ff::/48",
+				"10.0.0.0/8 contains 10.0.1.0/24",
+			},
+		},
+		{
+			name:   "unix sockets",
+			inputs: []string{"/tmp/a", "/tmp/b", "/tmp/a"},
+			want:   []string{`"/tmp/a" equal "/tmp/a"`},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sas := convertToSockAddrs(t, test.inputs)
+			overlaps := sockaddr.FindOverlaps(sas)
+			if len(overlaps) != len(test.want) {
+				t.Fatalf("want %d overlaps, got %d: %v", len(test.want), len(overlaps), overlaps)
+			}
+
+			for i, o := range overlaps {
+				got := fmt.Sprintf("%s %s %s", o.A, o.Type, o.B)
+				if got != test.want[i] {
+					t.Errorf("overlap %d: want %q, got %q", i, test.want[i], got)
+				}
+			}
+		})
+	}
+}
+
+func TestFindOverlaps_large(t *testing.T) {
+	// 4096 disjoint /24s plus a single /8 that contains all of them.
+	sas := make(sockaddr.SockAddrs, 0, 4097)
+	for i := 0; i < 4096; i++ {
+		sas = append(sas, sockaddr.MustIPv4Addr(fmt.Sprintf("10.%d.%d.0/24", i/256, i%256)))
+	}
+	sas = append(sas, sockaddr.MustIPv4Addr("10.0.0.0/8"))
+
+	overlaps := sockaddr.FindOverlaps(sas)
+	if len(overlaps) != 4096 {
+		t.Fatalf("want 4096 overlaps, got %d", len(overlaps))
+	}
+
+	for _, o := range overlaps {
+		if o.Type != sockaddr.OverlapContainedBy {
+			t.Fatalf("want %s to be contained by %s, got %s", o.A, o.B, o.Type)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package sockaddr
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+)
+
+// RandomAvoid is a bitmask of the addresses RandomHost and RandomSubnet must
+// never return.
+type RandomAvoid uint
+
+const (
+	// AvoidNetwork avoids the network address of an IPv4 network or the
+	// Subnet-Router anycast address of an IPv6 network (RFC 4291, §2.6.1).
+	// Point-to-point networks (IPv4 /31 or IPv6 /127) have no network
+	// address to avoid.
+	AvoidNetwork RandomAvoid = 1 << iota
+
+	// AvoidBroadcast avoids the broadcast address of an IPv4 network.
+	AvoidBroadcast
+
+	// AvoidAnycast avoids the reserved IPv6 subnet anycast addresses
+	// (RFC 2526).
+	AvoidAnycast
+
+	// AvoidReserved avoids every reserved address.
+	AvoidReserved = AvoidNetwork | AvoidBroadcast | AvoidAnycast
+)
+
+// maxRandomAttempts bounds the number of times RandomHost will discard a
+// reserved address before giving up.
+const maxRandomAttempts = 1024
+
+// RandomHost returns a random address within network, keeping network's mask.
+// Random numbers are read from rand, so passing a seeded source such as
+// math/rand.New(math/rand.NewSource(seed)) produces reproducible output and
+// passing crypto/rand.Reader produces unpredictable output (e.g. for RFC 4941
+// style privacy addresses).  Addresses selected by avoid are never returned.
+func RandomHost(network IPAddr, rand io.Reader, avoid RandomAvoid) (IPAddr, error) {
+	if network == nil {
+		return nil, fmt.Errorf("network required")
+	}
+
+	bits := ipAddrBits(network)
+	maskBits := network.Maskbits()
+	first, last := ipAddrRange(network)
+
+	switch network.Type() {
+	case TypeIPv4:
+		if avoid&AvoidNetwork != 0 && maskBits < 31 {
+			first.Add(first, big.NewInt(1))
+		}
+		if avoid&AvoidBroadcast != 0 && maskBits < 31 {
+			last.Sub(last, big.NewInt(1))
+		}
+	case TypeIPv6:
+		if avoid&AvoidNetwork != 0 && maskBits < 127 {
+			first.Add(first, big.NewInt(1))
+		}
+		if avoid&AvoidAnycast != 0 && maskBits == 121 {
+			// Every address in a /121 is a reserved anycast address.
+			return nil, fmt.Errorf("no usable addresses in %s", network)
+		}
+	}
+
+	if first.Cmp(last) > 0 {
+		return nil, fmt.Errorf("no usable addresses in %s", network)
+	}
+
+	count := new(big.Int).Sub(last, first)
+	count.Add(count, big.NewInt(1))
+	for i := 0; i < maxRandomAttempts; i++ {
+		n, err := randomBigInt(rand, count)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read random data: %v", err)
+		}
+		addr := n.Add(n, first)
+
+		if network.Type() == TypeIPv6 && avoid&AvoidAnycast != 0 && isReservedAnycast(addr, maskBits) {
+			continue
+		}
+
+		host := newIPAddrFromBigInt(network.Type(), addr, bits)
+		switch v := host.(type) {
+		case IPv4Addr:
+			v.Mask = network.(IPv4Addr).Mask
+			return v, nil
+		case IPv6Addr:
+			v.Mask = network.(IPv6Addr).Mask
+			return v, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unable to find an unreserved address in %s after %d attempts", network, maxRandomAttempts)
+}
+
+// RandomSubnet returns a random subnet of network with the given prefix
+// length.  Random numbers are read from rand (see RandomHost).  AvoidNetwork
+// skips the subnet holding network's first address and AvoidBroadcast skips
+// the subnet holding an IPv4 network's broadcast address (i.e. the "subnet
+// zero" and "all-ones subnet" from RFC 950).  AvoidAnycast has no effect on
+// subnets.
+func RandomSubnet(network IPAddr, prefixLen int, rand io.Reader, avoid RandomAvoid) (IPAddr, error) {
+	if network == nil {
+		return nil, fmt.Errorf("network required")
+	}
+
+	bits := ipAddrBits(network)
+	maskBits := network.Maskbits()
+	if prefixLen < maskBits || prefixLen > bits {
+		return nil, fmt.Errorf("prefix length %d must be between %d and %d for %s", prefixLen, maskBits, bits, network)
+	}
+
+	lo := big.NewInt(0)
+	hi := new(big.Int).Lsh(big.NewInt(1), uint(prefixLen-maskBits))
+	hi.Sub(hi, big.NewInt(1))
+	if avoid&AvoidNetwork != 0 {
+		lo.Add(lo, big.NewInt(1))
+	}
+	if avoid&AvoidBroadcast != 0 && network.Type() == TypeIPv4 {
+		hi.Sub(hi, big.NewInt(1))
+	}
+
+	if lo.Cmp(hi) > 0 {
+		return nil, fmt.Errorf("no usable /%d subnets in %s", prefixLen, network)
+	}
+
+	count := new(big.Int).Sub(hi, lo)
+	count.Add(count, big.NewInt(1))
+	n, err := randomBigInt(rand, count)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read random data: %v", err)
+	}
+	n.Add(n, lo)
+
+	first, _ := ipAddrRange(network)
+	n.Lsh(n, uint(bits-prefixLen))
+	n.Add(n, first)
+
+	return newIPAddrFromBigInt(network.Type(), n, prefixLen), nil
+}
+
+// isReservedAnycast returns true if addr is one of the reserved subnet anycast
+// addresses of an IPv6 subnet with the given prefix length.  Subnets of /64 or
+// larger use the EUI-64 interface identifiers fdff:ffff:ffff:ff80 through
+// fdff:ffff:ffff:ffff.  Smaller subnets reserve their highest 128 addresses.
+func isReservedAnycast(addr *big.Int, maskBits int) bool {
+	if maskBits <= 64 {
+		iid := new(big.Int).And(addr, new(big.Int).SetUint64(^uint64(0)))
+		return iid.Cmp(new(big.Int).SetUint64(0xfdffffffffffff80)) >= 0 &&
+			iid.Cmp(new(big.Int).SetUint64(0xfdffffffffffffff)) <= 0
+	}
+
+	if maskBits > 121 {
+		return false
+	}
+
+	hostMask := new(big.Int).Lsh(big.NewInt(1), uint(IPv6len*8-maskBits))
+	hostMask.Sub(hostMask, big.NewInt(1))
+	hostPart := new(big.Int).And(addr, hostMask)
+	return hostPart.Cmp(new(big.Int).Sub(hostMask, big.NewInt(128))) > 0
+}
+
+// randomBigInt returns a uniform random value in [0, max) read from rand.
+// Unlike crypto/rand.Int, the number of bytes consumed only depends on the
+// values read, so seeded readers always produce the same results.
+func randomBigInt(rand io.Reader, max *big.Int) (*big.Int, error) {
+	if max.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid random range %s", max)
+	}
+
+	bitLen := new(big.Int).Sub(max, big.NewInt(1)).BitLen()
+	if bitLen == 0 {
+		return big.NewInt(0), nil
+	}
+
+	buf := make([]byte, (bitLen+7)/8)
+	n := new(big.Int)
+	for {
+		if _, err := io.ReadFull(rand, buf); err != nil {
+			return nil, err
+		}
+
+		// Clear the excess high bits so that at least half of the reads
+		// fall within range.
+		if extra := uint(len(buf)*8 - bitLen); extra > 0 {
+			buf[0] &= byte(0xff >> extra)
+		}
+
+		n.SetBytes(buf)
+		if n.Cmp(max) < 0 {
+			return n, nil
+		}
+	}
+}
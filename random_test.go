@@ -0,0 +1,187 @@
+package sockaddr_test
+
+import (
+	"math/rand"
+	"testing"
+
+	sockaddr "github.com/hashicorp/go-sockaddr"
+)
+
+func TestRandomHost(t *testing.T) {
+	tests := []struct {
+		name    string
+		network string
+		avoid   sockaddr.RandomAvoid
+		never   []string
+		fail    bool
+	}{
+		{
+			name:    "ipv4 avoid network and broadcast",
+			network: "192.168.10.0/30",
+			avoid:   sockaddr.AvoidReserved,
+			never:   []string{"192.168.10.0/30", "192.168.10.3/30"},
+		},
+		{
+			name:    "ipv4 point-to-point",
+			network: "192.168.10.0/31",
+			avoid:   sockaddr.AvoidReserved,
+		},
+		{
+			name:    "ipv4 host",
+			network: "192.168.10.1",
+			avoid:   sockaddr.AvoidReserved,
+		},
+		{
+			name:    "ipv6 avoid subnet-router anycast",
+			network: "2001:db8::/126",
+			avoid:   sockaddr.AvoidNetwork,
+			never:   []string{"2001:db8::/126"},
+		},
+		{
+			name:    "ipv6 avoid reserved anycast",
+			network: "2001:db8::ff00/120",
+			avoid:   sockaddr.AvoidAnycast,
+			never:   []string{"2001:db8::ff80/120", "2001:db8::ffff/120"},
+		},
+		{
+			name:    "ipv6 all anycast",
+			network: "2001:db8::ff80/121",
+			avoid:   sockaddr.AvoidAnycast,
+			fail:    true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			network := sockaddr.MustIPAddr(test.network)
+			r := rand.New(rand.NewSource(1))
+			for i := 0; i < 256; i++ {
+				host, err := sockaddr.RandomHost(network, r, test.avoid)
+				if test.fail {
+					if err == nil {
+						t.Fatalf("expected failure, got %s", host)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("unable to generate host: %v", err)
+				}
+
+				if !network.Contains(host) {
+					t.Fatalf("%s is not in %s", host, network)
+				}
+				if host.Maskbits() != network.Maskbits() {
+					t.Fatalf("want mask %d, got %s", network.Maskbits(), host)
+				}
+				for _, never := range test.never {
+					if host.String() == never {
+						t.Fatalf("reserved address %s returned", host)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestRandomHost_reproducible(t *testing.T) {
+	networks := []string{"10.0.0.0/8", "2001:db8::/32"}
+	for _, n := range networks {
+		network := sockaddr.MustIPAddr(n)
+		r1 := rand.New(rand.NewSource(42))
+		r2 := rand.New(rand.NewSource(42))
+		for i := 0; i < 16; i++ {
+			a, err := sockaddr.RandomHost(network, r1, sockaddr.AvoidReserved)
+			if err != nil {
+				t.Fatalf("unable to generate host: %v", err)
+			}
+			b, err := sockaddr.RandomHost(network, r2, sockaddr.AvoidReserved)
+			if err != nil {
+				t.Fatalf("unable to generate host: %v", err)
+			}
+			if !a.Equal(b) {
+				t.Fatalf("same seed produced %s and %s", a, b)
+			}
+		}
+	}
+}
+
+func TestRandomSubnet(t *testing.T) {
+	tests := []struct {
+		name      string
+		network   string
+		prefixLen int
+		avoid     sockaddr.RandomAvoid
+		want      []string
+		fail      bool
+	}{
+		{
+			name:      "ipv4",
+			network:   "10.0.0.0/22",
+			prefixLen: 24,
+			want:      []string{"10.0.0.0/24", "10.0.1.0/24", "10.0.2.0/24", "10.0.3.0/24"},
+		},
+		{
+			name:      "ipv4 avoid subnet zero and all-ones",
+			network:   "10.0.0.0/22",
+			prefixLen: 24,
+			avoid:     sockaddr.AvoidReserved,
+			want:      []string{"10.0.1.0/24", "10.0.2.0/24"},
+		},
+		{
+			name:      "ipv6 avoid subnet zero",
+			network:   "2001:db8::/63",
+			prefixLen: 64,
+			avoid:     sockaddr.AvoidReserved,
+			want:      []string{"2001:db8:0:1::/64"},
+		},
+		{
+			name:      "same size",
+			network:   "10.0.0.0/24",
+			prefixLen: 24,
+			want:      []string{"10.0.0.0/24"},
+		},
+		{
+			name:      "nothing left",
+			network:   "10.0.0.0/24",
+			prefixLen: 25,
+			avoid:     sockaddr.AvoidReserved,
+			fail:      true,
+		},
+		{
+			name:      "prefix too short",
+			network:   "10.0.0.0/24",
+			prefixLen: 16,
+			fail:      true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			network := sockaddr.MustIPAddr(test.network)
+			r := rand.New(rand.NewSource(7))
+			seen := make(map[string]bool)
+			for i := 0; i < 64; i++ {
+				subnet, err := sockaddr.RandomSubnet(network, test.prefixLen, r, test.avoid)
+				if test.fail {
+					if err == nil {
+						t.Fatalf("expected failure, got %s", subnet)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("unable to generate subnet: %v", err)
+				}
+				seen[subnet.String()] = true
+			}
+
+			if len(seen) != len(test.want) {
+				t.Fatalf("want %v, got %v", test.want, seen)
+			}
+			for _, want := range test.want {
+				if !seen[want] {
+					t.Fatalf("want %v, got %v", test.want, seen)
+				}
+			}
+		})
+	}
+}
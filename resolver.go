@@ -0,0 +1,239 @@
+package sockaddr
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ResolverConfig is the DNS resolver configuration of a host.
+type ResolverConfig struct {
+	// Nameservers are the nameservers of /etc/resolv.conf in the order they
+	// are listed.  On hosts running systemd-resolved this is usually only
+	// its stub resolver, 127.0.0.53.
+	Nameservers SockAddrs
+
+	// Search are the search domains of /etc/resolv.conf.
+	Search []string
+
+	// Options are the resolver options of /etc/resolv.conf, e.g. "ndots:5"
+	// or "edns0".
+	Options []string
+
+	// LinkNameservers are the upstream nameservers systemd-resolved uses on
+	// each link, by interface name.  It is empty on hosts that do not run
+	// systemd-resolved.
+	LinkNameservers map[string]SockAddrs
+}
+
+// resolverConfigReader is implemented by SystemProviders that know the DNS
+// resolver configuration of their host.
+type resolverConfigReader interface {
+	resolverConfig() (ResolverConfig, error)
+}
+
+const (
+	// resolvConfPath is the path of the resolver configuration file.
+	resolvConfPath = "/etc/resolv.conf"
+
+	// resolvedLinkDir is the directory systemd-resolved saves the DNS
+	// settings of each link to, in a file named after the interface index.
+	resolvedLinkDir = "/run/systemd/resolve/netif"
+)
+
+// GetResolverConfig returns the DNS resolver configuration of the host.
+func GetResolverConfig() (ResolverConfig, error) {
+	return defaultSystem().GetResolverConfig()
+}
+
+// GetResolverConfig is like the package-level GetResolverConfig, but inspects
+// the host of s.
+func (s *System) GetResolverConfig() (ResolverConfig, error) {
+	provider := s.systemProvider()
+	reader, ok := provider.(resolverConfigReader)
+	if !ok {
+		return ResolverConfig{}, fmt.Errorf("unable to read the resolver configuration: not supported by %T", provider)
+	}
+
+	config, err := reader.resolverConfig()
+	if err != nil {
+		return ResolverConfig{}, fmt.Errorf("unable to read the resolver configuration: %v", err)
+	}
+	return config, nil
+}
+
+// GetNameservers returns the nameservers of the host as IfAddrs: first the
+// nameservers of /etc/resolv.conf, which have no interface, followed by the
+// upstream nameservers systemd-resolved uses on each link with the interface of
+// the link.  Loopback stub resolvers such as systemd-resolved's 127.0.0.53 can
+// be dropped with `exclude "rfc" "1122"`.
+//
+// $ sockaddr eval -r '{{GetNameservers | exclude "rfc" "1122" | join "address" " "}}'
+func GetNameservers() (IfAddrs, error) {
+	return defaultSystem().GetNameservers()
+}
+
+// GetNameservers is like the package-level GetNameservers, but inspects the
+// host of s.
+func (s *System) GetNameservers() (IfAddrs, error) {
+	config, err := s.GetResolverConfig()
+	if err != nil {
+		return IfAddrs{}, err
+	}
+
+	ifAddrs := make(IfAddrs, 0, len(config.Nameservers))
+	for _, nameserver := range config.Nameservers {
+		ifAddrs = append(ifAddrs, IfAddr{SockAddr: nameserver})
+	}
+
+	if len(config.LinkNameservers) == 0 {
+		return s.bind(ifAddrs), nil
+	}
+
+	ifs, err := s.systemProvider().Interfaces()
+	if err != nil {
+		return IfAddrs{}, err
+	}
+	for _, intf := range ifs {
+		for _, nameserver := range config.LinkNameservers[intf.Name] {
+			ifAddrs = append(ifAddrs, IfAddr{SockAddr: nameserver, Interface: intf})
+		}
+	}
+
+	return s.bind(ifAddrs), nil
+}
+
+// resolverConfig reads the resolver configuration of the host from
+// /etc/resolv.conf and the per-link settings of systemd-resolved.
+func (hostSystem) resolverConfig() (ResolverConfig, error) {
+	f, err := os.Open(resolvConfPath)
+	if err != nil {
+		return ResolverConfig{}, err
+	}
+	defer f.Close()
+
+	config, err := parseResolvConf(f)
+	if err != nil {
+		return ResolverConfig{}, err
+	}
+
+	linkFiles, _ := filepath.Glob(filepath.Join(resolvedLinkDir, "*"))
+	for _, linkFile := range linkFiles {
+		index, err := strconv.Atoi(filepath.Base(linkFile))
+		if err != nil {
+			continue
+		}
+		intf, err := net.InterfaceByIndex(index)
+		if err != nil {
+			continue
+		}
+
+		f, err := os.Open(linkFile)
+		if err != nil {
+			continue
+		}
+		nameservers, err := parseResolvedLinkFile(f)
+		f.Close()
+		if err != nil || len(nameservers) == 0 {
+			continue
+		}
+
+		if config.LinkNameservers == nil {
+			config.LinkNameservers = make(map[string]SockAddrs)
+		}
+		config.LinkNameservers[intf.Name] = nameservers
+	}
+
+	return config, nil
+}
+
+// parseResolvConf parses a resolv.conf(5) file.  Like the resolver, the last
+// "search" or "domain" line sets the search domains, and nameservers that are
+// not IP addresses are skipped.
+func parseResolvConf(r io.Reader) (ResolverConfig, error) {
+	var config ResolverConfig
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") || strings.HasPrefix(fields[0], ";") {
+			continue
+		}
+
+		switch fields[0] {
+		case "nameserver":
+			if len(fields) < 2 {
+				continue
+			}
+			if nameserver, err := parseNameserver(fields[1]); err == nil {
+				config.Nameservers = append(config.Nameservers, nameserver)
+			}
+		case "domain":
+			if len(fields) >= 2 {
+				config.Search = []string{fields[1]}
+			}
+		case "search":
+			config.Search = append([]string(nil), fields[1:]...)
+		case "options":
+			config.Options = append(config.Options, fields[1:]...)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return ResolverConfig{}, err
+	}
+
+	return config, nil
+}
+
+// parseResolvedLinkFile parses the nameservers of the SERVERS line of a link
+// file saved by systemd-resolved.  Servers that can not be parsed are skipped,
+// as in resolv.conf.
+func parseResolvedLinkFile(r io.Reader) (SockAddrs, error) {
+	var nameservers SockAddrs
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "SERVERS=") {
+			continue
+		}
+
+		for _, server := range strings.Fields(strings.TrimPrefix(line, "SERVERS=")) {
+			if nameserver, err := parseNameserver(server); err == nil {
+				nameservers = append(nameservers, nameserver)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return nameservers, nil
+}
+
+// parseNameserver parses the address of a nameserver.  The address may have a
+// port (e.g. "192.0.2.53:5353" or "[2001:db8::53]:5353"), and the interface
+// zone and the "#" server name used by systemd-resolved are dropped.
+func parseNameserver(s string) (IPAddr, error) {
+	addr := s
+	if i := strings.IndexByte(addr, '#'); i >= 0 {
+		addr = addr[:i]
+	}
+	if i := strings.IndexByte(addr, '%'); i >= 0 {
+		end := strings.IndexByte(addr[i:], ']')
+		if end < 0 {
+			addr = addr[:i]
+		} else {
+			addr = addr[:i] + addr[i+end:]
+		}
+	}
+
+	nameserver, err := NewIPAddr(addr)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse nameserver %q: %v", s, err)
+	}
+	return nameserver, nil
+}
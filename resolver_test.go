@@ -0,0 +1,165 @@
+package sockaddr
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_parseResolvConf(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "resolv.conf"))
+	if err != nil {
+		t.Fatalf("unable to open fixture: %v", err)
+	}
+	defer f.Close()
+
+	config, err := parseResolvConf(f)
+	if err != nil {
+		t.Fatalf("unable to parse fixture: %v", err)
+	}
+
+	// The hostname is skipped, and the "search" line replaces the search
+	// domain of the earlier "domain" line.
+	if got, want := joinNameservers(config.Nameservers), "127.0.0.53 fe80::1"; got != want {
+		t.Errorf("want nameservers %q, got %q", want, got)
+	}
+	if want := []string{"corp.example.com", "example.com"}; !reflect.DeepEqual(config.Search, want) {
+		t.Errorf("want search %q, got %q", want, config.Search)
+	}
+	if want := []string{"edns0", "trust-ad", "ndots:2"}; !reflect.DeepEqual(config.Options, want) {
+		t.Errorf("want options %q, got %q", want, config.Options)
+	}
+	if config.LinkNameservers != nil {
+		t.Errorf("expected no link nameservers, got %v", config.LinkNameservers)
+	}
+
+	config, err = parseResolvConf(strings.NewReader("search a.example\ndomain b.example\n"))
+	if err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+	if want := []string{"b.example"}; !reflect.DeepEqual(config.Search, want) {
+		t.Errorf("want search %q, got %q", want, config.Search)
+	}
+}
+
+func Test_parseResolvedLinkFile(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "resolved_link"))
+	if err != nil {
+		t.Fatalf("unable to open fixture: %v", err)
+	}
+	defer f.Close()
+
+	nameservers, err := parseResolvedLinkFile(f)
+	if err != nil {
+		t.Fatalf("unable to parse fixture: %v", err)
+	}
+	if got, want := joinNameservers(nameservers), "192.0.2.53 2001:db8::53 198.51.100.53:5353"; got != want {
+		t.Errorf("want nameservers %q, got %q", want, got)
+	}
+
+	nameservers, err = parseResolvedLinkFile(strings.NewReader("SERVERS=192.0.2.256 192.0.2.54 bogus\n"))
+	if err != nil {
+		t.Fatalf("unable to parse servers: %v", err)
+	}
+	if got, want := joinNameservers(nameservers), "192.0.2.54"; got != want {
+		t.Errorf("want the invalid servers skipped, got %q", got)
+	}
+}
+
+func Test_parseNameserver(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+		fail  bool
+	}{
+		{input: "192.0.2.53", want: "192.0.2.53"},
+		{input: "192.0.2.53:5353", want: "192.0.2.53:5353"},
+		{input: "2001:db8::53", want: "2001:db8::53"},
+		{input: "fe80::53%2", want: "fe80::53"},
+		{input: "[fe80::53%eth0]:5353", want: "[fe80::53]:5353"},
+		{input: "192.0.2.53#dns.example.net", want: "192.0.2.53"},
+		{input: "ns1.example.com", fail: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			nameserver, err := parseNameserver(test.input)
+			if test.fail {
+				if err == nil {
+					t.Fatalf("expected %q to fail", test.input)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+			if got := nameserver.String(); got != test.want {
+				t.Errorf("want %q, got %q", test.want, got)
+			}
+		})
+	}
+}
+
+func TestGetNameservers(t *testing.T) {
+	fake := NewFakeSystem().
+		AddInterface(net.Interface{Name: "eth0", Flags: net.FlagUp}, MustIPv4Addr("10.0.0.5/24")).
+		AddInterface(net.Interface{Name: "eth1", Flags: net.FlagUp}, MustIPv4Addr("192.168.1.5/24")).
+		SetResolverConfig(ResolverConfig{
+			Nameservers: SockAddrs{MustIPv4Addr("127.0.0.53")},
+			Search:      []string{"example.com"},
+			LinkNameservers: map[string]SockAddrs{
+				"eth1": {MustIPv4Addr("192.168.1.1"), MustIPv6Addr("2001:db8::53")},
+				"eth0": {MustIPv4Addr("10.0.0.2")},
+				"eth9": {MustIPv4Addr("172.16.0.2")},
+			},
+		})
+	SetSystemProvider(fake)
+	defer SetSystemProvider(nil)
+
+	ifAddrs, err := GetNameservers()
+	if err != nil {
+		t.Fatalf("unable to get nameservers: %v", err)
+	}
+
+	// Link nameservers are ordered by interface, and those of unknown
+	// interfaces are skipped.
+	want := []string{
+		" 127.0.0.53",
+		"eth0 10.0.0.2",
+		"eth1 192.168.1.1",
+		"eth1 2001:db8::53",
+	}
+	if len(ifAddrs) != len(want) {
+		t.Fatalf("want %d nameservers, got %d", len(want), len(ifAddrs))
+	}
+	for i, ifAddr := range ifAddrs {
+		if got := ifAddr.Name + " " + ifAddr.SockAddr.String(); got != want[i] {
+			t.Errorf("[%d] want %q, got %q", i, want[i], got)
+		}
+	}
+
+	ifAddrs, _, err = IfByRFC("1122", ifAddrs)
+	if err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+	if len(ifAddrs) != 1 || ifAddrs[0].SockAddr.String() != "127.0.0.53" {
+		t.Errorf("expected RFC 1122 to match the stub resolver, got %v", ifAddrs)
+	}
+
+	SetSystemProvider(minimalSystem{fake: fake})
+	if _, err := GetNameservers(); err == nil {
+		t.Errorf("expected a SystemProvider without a resolver configuration to fail")
+	}
+}
+
+// joinNameservers returns the nameservers separated by spaces.
+func joinNameservers(nameservers SockAddrs) string {
+	strs := make([]string, 0, len(nameservers))
+	for _, nameserver := range nameservers {
+		strs = append(strs, nameserver.String())
+	}
+	return strings.Join(strs, " ")
+}
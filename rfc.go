@@ -2,19 +2,22 @@ package sockaddr
 
 // ForwardingBlacklist is a faux RFC that includes a list of non-forwardable IP
 // blocks.
+//
+// Deprecated: use IsForwardable, which uses the RFC 6890 properties of the
+// blocks in the RFC registry.
 const ForwardingBlacklist = 4294967295
 const ForwardingBlacklistRFC = "4294967295"
 
-// IsRFC tests to see if an SockAddr matches the specified RFC
+// IsRFC tests to see if an SockAddr matches the specified RFC in the RFC
+// registry (see RegisterRFC).
 func IsRFC(rfcNum uint, sa SockAddr) bool {
-	rfcNetMap := KnownRFCs()
-	rfcNets, ok := rfcNetMap[rfcNum]
+	entry, ok := LookupRFC(rfcNum)
 	if !ok {
 		return false
 	}
 
 	var contained bool
-	for _, rfcNet := range rfcNets {
+	for _, rfcNet := range entry.Networks() {
 		if rfcNet.Contains(sa) {
 			contained = true
 			break
@@ -23,7 +26,8 @@ func IsRFC(rfcNum uint, sa SockAddr) bool {
 	return contained
 }
 
-// KnownRFCs returns an initial set of known RFCs.
+// KnownRFCs returns an initial set of known RFCs.  The RFC registry is seeded
+// from this set (see RegisterRFC and ResetRFCs).
 //
 // NOTE (sean@): As this list evolves over time, please submit patches to keep
 // this list current.  If something isn't right, inquire, as it may just be a
@@ -900,11 +904,8 @@ func KnownRFCs() map[uint]SockAddrs {
 			MustIPv4Addr("192.0.0.0/29"), // [RFC7335], §6 IANA Considerations
 		},
 		ForwardingBlacklist: { // Pseudo-RFC
-			// Blacklist of non-forwardable IP blocks taken from RFC6890
-			//
-			// TODO: the attributes for forwardable should be
-			// searcahble and embedded in the main list of RFCs
-			// above.
+			// Blacklist of non-forwardable IP blocks taken from RFC6890.
+			// Kept for compatibility, see IsForwardable.
 			MustIPv4Addr("0.0.0.0/8"),
 			MustIPv4Addr("127.0.0.0/8"),
 			MustIPv4Addr("169.254.0.0/16"),
@@ -930,9 +931,48 @@ func KnownRFCs() map[uint]SockAddrs {
 	}
 }
 
-// VisitAllRFCs iterates over all known RFCs and calls the visitor
+// rfc6890Blocks returns the special-purpose address blocks from [RFC6890],
+// §2.2.2 and §2.2.3 along with their properties.  The "False [1]" values from
+// the registry (e.g. 127.0.0.0/8) are recorded as false and the "N/A" value
+// for 2002::/16 is recorded as not global.
+func rfc6890Blocks() []RFCBlock {
+	return []RFCBlock{
+		{MustIPv4Addr("0.0.0.0/8"), "This host on this network", &SpecialPurpose{Source: true, Destination: false, Forwardable: false, Global: false, ReservedByProtocol: true}},
+		{MustIPv4Addr("10.0.0.0/8"), "Private-Use", &SpecialPurpose{Source: true, Destination: true, Forwardable: true, Global: false, ReservedByProtocol: false}},
+		{MustIPv4Addr("100.64.0.0/10"), "Shared Address Space", &SpecialPurpose{Source: true, Destination: true, Forwardable: true, Global: false, ReservedByProtocol: false}},
+		{MustIPv4Addr("127.0.0.0/8"), "Loopback", &SpecialPurpose{Source: false, Destination: false, Forwardable: false, Global: false, ReservedByProtocol: true}},
+		{MustIPv4Addr("169.254.0.0/16"), "Link Local", &SpecialPurpose{Source: true, Destination: true, Forwardable: false, Global: false, ReservedByProtocol: true}},
+		{MustIPv4Addr("172.16.0.0/12"), "Private-Use", &SpecialPurpose{Source: true, Destination: true, Forwardable: true, Global: false, ReservedByProtocol: false}},
+		{MustIPv4Addr("192.0.0.0/24"), "IETF Protocol Assignments", &SpecialPurpose{Source: false, Destination: false, Forwardable: false, Global: false, ReservedByProtocol: false}},
+		{MustIPv4Addr("192.0.0.0/29"), "IPv4 Service Continuity Prefix", &SpecialPurpose{Source: true, Destination: true, Forwardable: true, Global: false, ReservedByProtocol: false}},
+		{MustIPv4Addr("192.0.2.0/24"), "Documentation (TEST-NET-1)", &SpecialPurpose{Source: false, Destination: false, Forwardable: false, Global: false, ReservedByProtocol: false}},
+		{MustIPv4Addr("192.88.99.0/24"), "6to4 Relay Anycast", &SpecialPurpose{Source: true, Destination: true, Forwardable: true, Global: true, ReservedByProtocol: false}},
+		{MustIPv4Addr("192.168.0.0/16"), "Private-Use", &SpecialPurpose{Source: true, Destination: true, Forwardable: true, Global: false, ReservedByProtocol: false}},
+		{MustIPv4Addr("198.18.0.0/15"), "Benchmarking", &SpecialPurpose{Source: true, Destination: true, Forwardable: true, Global: false, ReservedByProtocol: false}},
+		{MustIPv4Addr("198.51.100.0/24"), "Documentation (TEST-NET-2)", &SpecialPurpose{Source: false, Destination: false, Forwardable: false, Global: false, ReservedByProtocol: false}},
+		{MustIPv4Addr("203.0.113.0/24"), "Documentation (TEST-NET-3)", &SpecialPurpose{Source: false, Destination: false, Forwardable: false, Global: false, ReservedByProtocol: false}},
+		{MustIPv4Addr("240.0.0.0/4"), "Reserved", &SpecialPurpose{Source: false, Destination: false, Forwardable: false, Global: false, ReservedByProtocol: true}},
+		{MustIPv4Addr("255.255.255.255/32"), "Limited Broadcast", &SpecialPurpose{Source: false, Destination: true, Forwardable: false, Global: false, ReservedByProtocol: true}},
+		{MustIPv6Addr("::1/128"), "Loopback Address", &SpecialPurpose{Source: false, Destination: false, Forwardable: false, Global: false, ReservedByProtocol: true}},
+		{MustIPv6Addr("::/128"), "Unspecified Address", &SpecialPurpose{Source: true, Destination: false, Forwardable: false, Global: false, ReservedByProtocol: true}},
+		{MustIPv6Addr("64:ff9b::/96"), "IPv4-IPv6 Translat.", &SpecialPurpose{Source: true, Destination: true, Forwardable: true, Global: true, ReservedByProtocol: false}},
+		{MustIPv6Addr("::ffff:0:0/96"), "IPv4-mapped Address", &SpecialPurpose{Source: false, Destination: false, Forwardable: false, Global: false, ReservedByProtocol: true}},
+		{MustIPv6Addr("100::/64"), "Discard-Only Address Block", &SpecialPurpose{Source: true, Destination: true, Forwardable: true, Global: false, ReservedByProtocol: false}},
+		{MustIPv6Addr("2001::/23"), "IETF Protocol Assignments", &SpecialPurpose{Source: false, Destination: false, Forwardable: false, Global: false, ReservedByProtocol: false}},
+		{MustIPv6Addr("2001::/32"), "TEREDO", &SpecialPurpose{Source: true, Destination: true, Forwardable: true, Global: false, GlobalNotApplicable: true, ReservedByProtocol: false}},
+		{MustIPv6Addr("2001:2::/48"), "Benchmarking", &SpecialPurpose{Source: true, Destination: true, Forwardable: true, Global: false, ReservedByProtocol: false}},
+		{MustIPv6Addr("2001:db8::/32"), "Documentation", &SpecialPurpose{Source: false, Destination: false, Forwardable: false, Global: false, ReservedByProtocol: false}},
+		{MustIPv6Addr("2001:10::/28"), "ORCHID", &SpecialPurpose{Source: false, Destination: false, Forwardable: false, Global: false, ReservedByProtocol: false}},
+		{MustIPv6Addr("2002::/16"), "6to4", &SpecialPurpose{Source: true, Destination: true, Forwardable: true, Global: false, GlobalNotApplicable: true, ReservedByProtocol: false}},
+		{MustIPv6Addr("fc00::/7"), "Unique-Local", &SpecialPurpose{Source: true, Destination: true, Forwardable: true, Global: false, ReservedByProtocol: false}},
+		{MustIPv6Addr("fe80::/10"), "Linked-Scoped Unicast", &SpecialPurpose{Source: true, Destination: true, Forwardable: false, Global: false, ReservedByProtocol: true}},
+	}
+}
+
+// VisitAllRFCs iterates over all RFCs in the RFC registry and calls the
+// visitor.  The visitor may modify the registry.
 func VisitAllRFCs(fn func(rfcNum uint, sockaddrs SockAddrs)) {
-	rfcNetMap := KnownRFCs()
+	entries := rfcs.snapshot()
 
 	// Blacklist of faux-RFCs.  Don't show the world that we're abusing the
 	// RFC system in this library.
@@ -940,9 +980,9 @@ func VisitAllRFCs(fn func(rfcNum uint, sockaddrs SockAddrs)) {
 		ForwardingBlacklist: {},
 	}
 
-	for rfcNum, sas := range rfcNetMap {
-		if _, found := rfcBlacklist[rfcNum]; !found {
-			fn(rfcNum, sas)
+	for _, entry := range entries {
+		if _, found := rfcBlacklist[entry.Number]; !found {
+			fn(entry.Number, entry.Networks())
 		}
 	}
 }
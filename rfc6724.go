@@ -0,0 +1,374 @@
+package sockaddr
+
+import (
+	"math/big"
+	"math/bits"
+	"sort"
+	"sync"
+)
+
+// PolicyEntry is a row in an RFC 6724 policy table.
+type PolicyEntry struct {
+	Prefix     IPv6Addr
+	Precedence uint8
+	Label      uint8
+}
+
+// PolicyTable is an RFC 6724 policy table.  IPv4 addresses are looked up as
+// IPv4-mapped IPv6 addresses (::ffff:0:0/96).
+type PolicyTable []PolicyEntry
+
+// RFC 6724 scopes for unicast addresses, matching the IPv6 multicast scope
+// values (RFC 6724, §3.1).
+const (
+	rfc6724ScopeLinkLocal = 0x2
+	rfc6724ScopeSiteLocal = 0x5
+	rfc6724ScopeGlobal    = 0xe
+)
+
+var (
+	policyTableLock sync.RWMutex
+	policyTable     PolicyTable
+)
+
+// DefaultPolicyTable returns the default policy table from RFC 6724, §2.1.
+func DefaultPolicyTable() PolicyTable {
+	return PolicyTable{
+		{Prefix: newIPv6Prefix("00000000000000000000000000000001", 128), Precedence: 50, Label: 0}, // ::1/128
+		{Prefix: newIPv6Prefix("00000000000000000000000000000000", 0), Precedence: 40, Label: 1},   // ::/0
+		{Prefix: newIPv6Prefix("00000000000000000000ffff00000000", 96), Precedence: 35, Label: 4},  // ::ffff:0:0/96
+		{Prefix: newIPv6Prefix("20020000000000000000000000000000", 16), Precedence: 30, Label: 2},  // 2002::/16
+		{Prefix: newIPv6Prefix("20010000000000000000000000000000", 32), Precedence: 5, Label: 5},   // 2001::/32
+		{Prefix: newIPv6Prefix("fc000000000000000000000000000000", 7), Precedence: 3, Label: 13},   // fc00::/7
+		{Prefix: newIPv6Prefix("00000000000000000000000000000000", 96), Precedence: 1, Label: 3},   // ::/96
+		{Prefix: newIPv6Prefix("fec00000000000000000000000000000", 10), Precedence: 1, Label: 11},  // fec0::/10
+		{Prefix: newIPv6Prefix("3ffe0000000000000000000000000000", 16), Precedence: 1, Label: 12},  // 3ffe::/16
+	}
+}
+
+// SetPolicyTable replaces the policy table used for RFC 6724 address
+// selection.  A nil table restores the default table.
+func SetPolicyTable(table PolicyTable) {
+	policyTableLock.Lock()
+	defer policyTableLock.Unlock()
+	policyTable = append(PolicyTable(nil), table...)
+}
+
+// CurrentPolicyTable returns a copy of the policy table used for RFC 6724
+// address selection.
+func CurrentPolicyTable() PolicyTable {
+	policyTableLock.RLock()
+	defer policyTableLock.RUnlock()
+
+	if len(policyTable) == 0 {
+		return DefaultPolicyTable()
+	}
+	return append(PolicyTable(nil), policyTable...)
+}
+
+// Classify returns the entry with the longest prefix matching ip.  The zero
+// PolicyEntry is returned if no entry matches.
+func (t PolicyTable) Classify(ip IPAddr) PolicyEntry {
+	mapped, ok := rfc6724Address(ip)
+	if !ok {
+		return PolicyEntry{}
+	}
+
+	var best PolicyEntry
+	bestLen := -1
+	for _, entry := range t {
+		if entry.Prefix.ContainsNetwork(mapped) && entry.Prefix.Maskbits() > bestLen {
+			best = entry
+			bestLen = entry.Prefix.Maskbits()
+		}
+	}
+	return best
+}
+
+// OrderSourceAddrs returns the candidates ordered by preference as the source
+// address for dest, following the source address selection rules of RFC 6724,
+// §5.  Rules that depend on state this package does not track (deprecated,
+// home, temporary addresses, and the outgoing interface) are not applied.
+// Candidates of a different address family than dest, and non-IP candidates,
+// are placed last.
+func OrderSourceAddrs(dest SockAddr, candidates SockAddrs) SockAddrs {
+	sorted := append(SockAddrs(nil), candidates...)
+
+	d := ToIPAddr(dest)
+	if dest == nil || d == nil {
+		return sorted
+	}
+
+	table := CurrentPolicyTable()
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return cmpRFC6724Source(table, *d, sorted[i], sorted[j]) < 0
+	})
+	return sorted
+}
+
+// OrderDestinationAddrs returns the destinations ordered by preference
+// following the destination address selection rules of RFC 6724, §6.  The
+// source address for each destination is chosen from sources with
+// OrderSourceAddrs, and destinations without a usable source are placed last.
+//
+// Rules 1 (avoid unusable destinations), 2 (prefer matching scope), 5 (prefer
+// matching label), 6 (prefer higher precedence), 8 (prefer smaller scope), 9
+// (use longest matching prefix) and 10 (leave the order unchanged) are
+// applied.  Rules 3 (avoid deprecated addresses), 4 (prefer home addresses)
+// and 7 (prefer native transport) depend on state this package does not track
+// and are skipped.
+func OrderDestinationAddrs(dests, sources SockAddrs) SockAddrs {
+	table := CurrentPolicyTable()
+
+	type destInfo struct {
+		dest   SockAddr
+		ip     IPAddr
+		source IPAddr
+	}
+	infos := make([]destInfo, 0, len(dests))
+	for _, dest := range dests {
+		info := destInfo{dest: dest}
+		if ip := ToIPAddr(dest); dest != nil && ip != nil {
+			info.ip = *ip
+			if ordered := OrderSourceAddrs(dest, sources); len(ordered) > 0 {
+				info.source = rfc6724Candidate(info.ip, ordered[0])
+			}
+		}
+		infos = append(infos, info)
+	}
+
+	sort.SliceStable(infos, func(i, j int) bool {
+		a, b := infos[i], infos[j]
+
+		// Rule 1: Avoid unusable destinations.
+		if (a.source != nil) != (b.source != nil) {
+			return a.source != nil
+		}
+		if a.source == nil {
+			return false
+		}
+
+		// Rule 2: Prefer matching scope.
+		aScope, bScope := rfc6724Scope(a.ip), rfc6724Scope(b.ip)
+		aMatch := aScope == rfc6724Scope(a.source)
+		bMatch := bScope == rfc6724Scope(b.source)
+		if aMatch != bMatch {
+			return aMatch
+		}
+
+		// Rule 5: Prefer matching label.
+		aPolicy, bPolicy := table.Classify(a.ip), table.Classify(b.ip)
+		aMatch = aPolicy.Label == table.Classify(a.source).Label
+		bMatch = bPolicy.Label == table.Classify(b.source).Label
+		if aMatch != bMatch {
+			return aMatch
+		}
+
+		// Rule 6: Prefer higher precedence.
+		if aPolicy.Precedence != bPolicy.Precedence {
+			return aPolicy.Precedence > bPolicy.Precedence
+		}
+
+		// Rule 8: Prefer smaller scope.
+		if aScope != bScope {
+			return aScope < bScope
+		}
+
+		// Rule 9: Use longest matching prefix.
+		if a.ip.Type() == b.ip.Type() {
+			return commonPrefixLen(a.source, a.ip) > commonPrefixLen(b.source, b.ip)
+		}
+
+		// Rule 10: Otherwise, leave the order unchanged.
+		return false
+	})
+
+	sorted := make(SockAddrs, 0, len(infos))
+	for _, info := range infos {
+		sorted = append(sorted, info.dest)
+	}
+	return sorted
+}
+
+// AscIfPolicyPrecedence is a sorting function to sort IfAddrs by their RFC
+// 6724 scope, addresses with a smaller than global scope sorting last, and
+// then by their precedence in the policy table (e.g. IPv6 before IPv4 before
+// ULAs with the default table).  This is not the source address selection of
+// RFC 6724, which ranks addresses against a destination; use OrderSourceAddrs
+// for that.  Non-IP addresses are deferred in the sort.
+func AscIfPolicyPrecedence(p1Ptr, p2Ptr *IfAddr) int {
+	ip1, ip2 := ToIPAddr(p1Ptr.SockAddr), ToIPAddr(p2Ptr.SockAddr)
+	if p1Ptr.SockAddr == nil || p2Ptr.SockAddr == nil || ip1 == nil || ip2 == nil {
+		return sortDeferDecision
+	}
+
+	scope1, scope2 := rfc6724Scope(*ip1), rfc6724Scope(*ip2)
+	if scope1 > rfc6724ScopeGlobal {
+		scope1 = rfc6724ScopeGlobal
+	}
+	if scope2 > rfc6724ScopeGlobal {
+		scope2 = rfc6724ScopeGlobal
+	}
+	switch {
+	case scope1 > scope2:
+		return sortReceiverBeforeArg
+	case scope1 < scope2:
+		return sortArgBeforeReceiver
+	}
+
+	table := CurrentPolicyTable()
+	prec1, prec2 := table.Classify(*ip1).Precedence, table.Classify(*ip2).Precedence
+	switch {
+	case prec1 > prec2:
+		return sortReceiverBeforeArg
+	case prec1 < prec2:
+		return sortArgBeforeReceiver
+	default:
+		return sortDeferDecision
+	}
+}
+
+// DescIfPolicyPrecedence is identical to AscIfPolicyPrecedence but reverse
+// ordered.
+func DescIfPolicyPrecedence(p1Ptr, p2Ptr *IfAddr) int {
+	return -1 * AscIfPolicyPrecedence(p1Ptr, p2Ptr)
+}
+
+// cmpRFC6724Source compares two candidate source addresses for dest.
+// Returns a negative number if sa is preferred, a positive number if sb is
+// preferred, and 0 if neither is.
+func cmpRFC6724Source(table PolicyTable, dest IPAddr, sa, sb SockAddr) int {
+	a, b := rfc6724Candidate(dest, sa), rfc6724Candidate(dest, sb)
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	}
+
+	// Rule 1: Prefer same address.
+	aSame, bSame := rfc6724SameAddress(a, dest), rfc6724SameAddress(b, dest)
+	if aSame != bSame {
+		if aSame {
+			return -1
+		}
+		return 1
+	}
+
+	// Rule 2: Prefer appropriate scope.
+	aScope, bScope, dScope := rfc6724Scope(a), rfc6724Scope(b), rfc6724Scope(dest)
+	switch {
+	case aScope < bScope && aScope < dScope:
+		return 1
+	case aScope < bScope:
+		return -1
+	case bScope < aScope && bScope < dScope:
+		return -1
+	case bScope < aScope:
+		return 1
+	}
+
+	// Rule 6: Prefer matching label.
+	dLabel := table.Classify(dest).Label
+	aMatch, bMatch := table.Classify(a).Label == dLabel, table.Classify(b).Label == dLabel
+	if aMatch != bMatch {
+		if aMatch {
+			return -1
+		}
+		return 1
+	}
+
+	// Rule 8: Use longest matching prefix.
+	return commonPrefixLen(b, dest) - commonPrefixLen(a, dest)
+}
+
+// rfc6724Candidate returns sa as an IPAddr if it is a source candidate for
+// dest, i.e. if it is an IP address of the same family as dest.
+func rfc6724Candidate(dest IPAddr, sa SockAddr) IPAddr {
+	if sa == nil {
+		return nil
+	}
+
+	ip := ToIPAddr(sa)
+	if ip == nil || (*ip).Type() != dest.Type() {
+		return nil
+	}
+	return *ip
+}
+
+// rfc6724SameAddress returns true if the addresses of a and b are equal,
+// ignoring their masks.
+func rfc6724SameAddress(a, b IPAddr) bool {
+	return a.Host().Equal(b.Host())
+}
+
+// rfc6724Address returns ip as an IPv6 host address, mapping IPv4 addresses
+// into ::ffff:0:0/96.
+func rfc6724Address(ip IPAddr) (IPv6Addr, bool) {
+	switch v := ip.(type) {
+	case IPv4Addr:
+		addr := new(big.Int).Lsh(big.NewInt(0xffff), 32)
+		addr.Or(addr, big.NewInt(int64(v.Address)))
+		return IPv6Addr{
+			Address: IPv6Address(addr),
+			Mask:    ipv6HostMask,
+		}, true
+	case IPv6Addr:
+		return IPv6Addr{
+			Address: v.Address,
+			Mask:    ipv6HostMask,
+		}, true
+	default:
+		return IPv6Addr{}, false
+	}
+}
+
+// rfc6724Scope returns the scope of ip as defined in RFC 6724, §3.1 and §3.2.
+func rfc6724Scope(ip IPAddr) int {
+	netIP := *ip.NetIP()
+	switch ip.Type() {
+	case TypeIPv4:
+		if netIP.IsLoopback() || netIP.IsLinkLocalUnicast() {
+			return rfc6724ScopeLinkLocal
+		}
+		return rfc6724ScopeGlobal
+	default:
+		switch {
+		case netIP.IsMulticast():
+			return int(netIP[1] & 0x0f)
+		case netIP.IsLoopback(), netIP.IsLinkLocalUnicast():
+			return rfc6724ScopeLinkLocal
+		case netIP[0] == 0xfe && netIP[1]&0xc0 == 0xc0:
+			// fec0::/10
+			return rfc6724ScopeSiteLocal
+		default:
+			return rfc6724ScopeGlobal
+		}
+	}
+}
+
+// commonPrefixLen returns the number of leading bits that the addresses of
+// source and dest have in common, up to the prefix length of source.
+func commonPrefixLen(source, dest IPAddr) int {
+	if source.Type() != dest.Type() {
+		return 0
+	}
+
+	var n int
+	switch s := source.(type) {
+	case IPv4Addr:
+		d := dest.(IPv4Addr)
+		n = bits.LeadingZeros32(uint32(s.Address ^ d.Address))
+	case IPv6Addr:
+		d := dest.(IPv6Addr)
+		diff := new(big.Int).Xor(s.Address, d.Address)
+		n = IPv6len*8 - diff.BitLen()
+	}
+
+	if maskBits := source.Maskbits(); n > maskBits {
+		n = maskBits
+	}
+	return n
+}
@@ -0,0 +1,202 @@
+package sockaddr_test
+
+import (
+	"strings"
+	"testing"
+
+	sockaddr "github.com/hashicorp/go-sockaddr"
+)
+
+func sockAddrsString(sas sockaddr.SockAddrs) string {
+	strs := make([]string, 0, len(sas))
+	for _, sa := range sas {
+		strs = append(strs, sa.String())
+	}
+	return strings.Join(strs, " ")
+}
+
+func mustSockAddrs(addrs ...string) sockaddr.SockAddrs {
+	sas := make(sockaddr.SockAddrs, 0, len(addrs))
+	for _, addr := range addrs {
+		sas = append(sas, sockaddr.MustIPAddr(addr))
+	}
+	return sas
+}
+
+func TestPolicyTable_Classify(t *testing.T) {
+	table := sockaddr.DefaultPolicyTable()
+	tests := []struct {
+		addr       string
+		precedence uint8
+		label      uint8
+	}{
+		{addr: "::1", precedence: 50, label: 0},
+		{addr: "2001:db8::1", precedence: 40, label: 1},
+		{addr: "192.168.1.1", precedence: 35, label: 4},
+		{addr: "2002:c000:204::1", precedence: 30, label: 2},
+		{addr: "2001::1", precedence: 5, label: 5},
+		{addr: "fd00::1", precedence: 3, label: 13},
+		{addr: "::c000:201", precedence: 1, label: 3},
+		{addr: "fec0::1", precedence: 1, label: 11},
+	}
+
+	for _, test := range tests {
+		t.Run(test.addr, func(t *testing.T) {
+			entry := table.Classify(sockaddr.MustIPAddr(test.addr))
+			if entry.Precedence != test.precedence || entry.Label != test.label {
+				t.Fatalf("want precedence %d and label %d, got %+v", test.precedence, test.label, entry)
+			}
+		})
+	}
+}
+
+func TestOrderSourceAddrs(t *testing.T) {
+	tests := []struct {
+		name       string
+		dest       string
+		candidates []string
+		want       string
+	}{
+		{
+			name:       "same address",
+			dest:       "2001:db8::1",
+			candidates: []string{"2001:db8::2/64", "2001:db8::1/64"},
+			want:       "2001:db8::1/64 2001:db8::2/64",
+		},
+		{
+			name:       "appropriate scope",
+			dest:       "2001:db8:1::1",
+			candidates: []string{"fe80::1/64", "2001:db8::2/64"},
+			want:       "2001:db8::2/64 fe80::1/64",
+		},
+		{
+			name:       "link-local destination",
+			dest:       "fe80::2",
+			candidates: []string{"2001:db8::2/64", "fe80::1/64"},
+			want:       "fe80::1/64 2001:db8::2/64",
+		},
+		{
+			name:       "matching label",
+			dest:       "2002:c633:6401::1",
+			candidates: []string{"2001:db8::1/64", "2002:c000:204::1/48"},
+			want:       "2002:c000:204::1/48 2001:db8::1/64",
+		},
+		{
+			name:       "longest matching prefix",
+			dest:       "2001:db8:1::1",
+			candidates: []string{"2001:db8:2::1/64", "2001:db8:1::2/64"},
+			want:       "2001:db8:1::2/64 2001:db8:2::1/64",
+		},
+		{
+			name:       "other family last",
+			dest:       "198.51.100.1",
+			candidates: []string{"2001:db8::1/64", "169.254.1.1/16", "192.0.2.1/24"},
+			want:       "192.0.2.1/24 169.254.1.1/16 2001:db8::1/64",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := sockaddr.OrderSourceAddrs(sockaddr.MustIPAddr(test.dest), mustSockAddrs(test.candidates...))
+			if s := sockAddrsString(got); s != test.want {
+				t.Fatalf("want %q, got %q", test.want, s)
+			}
+		})
+	}
+}
+
+func TestOrderDestinationAddrs(t *testing.T) {
+	tests := []struct {
+		name    string
+		dests   []string
+		sources []string
+		want    string
+	}{
+		{
+			name:    "prefer IPv6",
+			dests:   []string{"198.51.100.121", "2001:db8:1::1"},
+			sources: []string{"2001:db8:1::2/64", "169.254.13.78/16", "192.0.2.10/24"},
+			want:    "2001:db8:1::1 198.51.100.121",
+		},
+		{
+			name:    "avoid unusable destinations",
+			dests:   []string{"2001:db8:1::1", "198.51.100.121"},
+			sources: []string{"192.0.2.10/24"},
+			want:    "198.51.100.121 2001:db8:1::1",
+		},
+		{
+			name:    "prefer matching scope",
+			dests:   []string{"2001:db8:1::1", "198.51.100.121"},
+			sources: []string{"fe80::1/64", "198.51.100.117/24"},
+			want:    "198.51.100.121 2001:db8:1::1",
+		},
+		{
+			name:    "prefer higher precedence",
+			dests:   []string{"10.1.2.3", "fd00::1"},
+			sources: []string{"fd00::2/64", "10.1.2.4/8"},
+			want:    "10.1.2.3 fd00::1",
+		},
+		{
+			name:    "prefer smaller scope",
+			dests:   []string{"2001:db8:1::1", "fe80::1"},
+			sources: []string{"2001:db8:1::2/64", "fe80::2/64"},
+			want:    "fe80::1 2001:db8:1::1",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := sockaddr.OrderDestinationAddrs(mustSockAddrs(test.dests...), mustSockAddrs(test.sources...))
+			if s := sockAddrsString(got); s != test.want {
+				t.Fatalf("want %q, got %q", test.want, s)
+			}
+		})
+	}
+}
+
+func TestSortIfByRFC6724(t *testing.T) {
+	defer sockaddr.SetPolicyTable(nil)
+
+	ifAddrs := sockaddr.IfAddrs{
+		{SockAddr: sockaddr.MustIPAddr("fe80::1/64")},
+		{SockAddr: sockaddr.MustIPAddr("127.0.0.1/8")},
+		{SockAddr: sockaddr.MustIPAddr("fd00::2/64")},
+		{SockAddr: sockaddr.MustIPAddr("192.168.1.10/24")},
+		{SockAddr: sockaddr.MustIPAddr("2001:db8::2/64")},
+	}
+
+	sorted, err := sockaddr.SortIfBy("rfc6724", ifAddrs)
+	if err != nil {
+		t.Fatalf("unable to sort: %v", err)
+	}
+	want := "2001:db8::2/64 192.168.1.10/24 fd00::2/64"
+	if got := ifAddrsString(sorted[:3]); got != want {
+		t.Fatalf("want %q, got %q", want, got)
+	}
+
+	// Prefer ULAs over IPv4 with a custom policy table.
+	table := sockaddr.DefaultPolicyTable()
+	for i := range table {
+		if table[i].Label == 13 {
+			table[i].Precedence = 45
+		}
+	}
+	sockaddr.SetPolicyTable(table)
+
+	sorted, err = sockaddr.SortIfBy("rfc6724", ifAddrs)
+	if err != nil {
+		t.Fatalf("unable to sort: %v", err)
+	}
+	want = "fd00::2/64 2001:db8::2/64 192.168.1.10/24"
+	if got := ifAddrsString(sorted[:3]); got != want {
+		t.Fatalf("want %q, got %q", want, got)
+	}
+}
+
+func ifAddrsString(ifAddrs sockaddr.IfAddrs) string {
+	strs := make([]string, 0, len(ifAddrs))
+	for _, ifAddr := range ifAddrs {
+		strs = append(strs, ifAddr.SockAddr.String())
+	}
+	return strings.Join(strs, " ")
+}
@@ -0,0 +1,702 @@
+package sockaddr
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// SpecialPurpose holds the properties of a special-purpose address block as
+// defined in [RFC6890], §2.2.1.
+type SpecialPurpose struct {
+	// Source is true if an address from the block may be used as a source
+	// address.
+	Source bool
+
+	// Destination is true if an address from the block may be used as a
+	// destination address.
+	Destination bool
+
+	// Forwardable is true if a router may forward a datagram whose
+	// destination address is drawn from the block.
+	Forwardable bool
+
+	// Global is true if a datagram whose destination address is drawn from
+	// the block is forwardable beyond a specified administrative domain.
+	Global bool
+
+	// GlobalNotApplicable is true if the registry lists the global
+	// reachability of the block as N/A, as for 6to4 and Teredo, whose
+	// reachability is that of the IPv4 address they embed.  Global is false
+	// for such blocks.
+	GlobalNotApplicable bool
+
+	// ReservedByProtocol is true if the block is reserved by the IP protocol
+	// itself.
+	ReservedByProtocol bool
+}
+
+// RFCBlock is an address block registered under an RFC.
+type RFCBlock struct {
+	Network IPAddr
+	Name    string
+
+	// SpecialPurpose holds the block's RFC 6890 properties, or nil if they
+	// are unknown.
+	SpecialPurpose *SpecialPurpose
+}
+
+// RFCEntry is an entry in the RFC registry: an RFC number, its title, and the
+// address blocks it reserves.
+type RFCEntry struct {
+	Number uint
+	Title  string
+	Blocks []RFCBlock
+}
+
+// rfcRegistry holds the registered RFCs.  It is seeded from KnownRFCs() on
+// first use because KnownRFCs() can not be called before init() has run.
+type rfcRegistry struct {
+	lock    sync.RWMutex
+	once    sync.Once
+	entries map[uint]RFCEntry
+
+	// special holds the special-purpose blocks of RFC 6890 with their
+	// properties.  They are only consulted for the properties of an
+	// address and are not members of any entry.
+	special []RFCBlock
+}
+
+var rfcs rfcRegistry
+
+var (
+	// ianaRFCRE matches RFC references such as "[RFC6890]" or "rfc6890".
+	ianaRFCRE = regexp.MustCompile(`(?i)rfc[\s-]*([0-9]+)`)
+
+	// ianaFootnoteRE matches footnote markers such as "[2]" in IANA address
+	// blocks.
+	ianaFootnoteRE = regexp.MustCompile(`\[[0-9]+\]`)
+)
+
+// knownRFCTitles are the titles of the RFCs returned by KnownRFCs().
+var knownRFCTitles = map[uint]string{
+	919:                 "Broadcasting Internet Datagrams",
+	1112:                "Host Extensions for IP Multicasting",
+	1122:                "Requirements for Internet Hosts -- Communication Layers",
+	1918:                "Address Allocation for Private Internets",
+	2544:                "Benchmarking Methodology for Network Interconnect Devices",
+	2765:                "Stateless IP/ICMP Translation Algorithm (SIIT)",
+	2928:                "Initial IPv6 Sub-TLA ID Assignments",
+	3056:                "Connection of IPv6 Domains via IPv4 Clouds",
+	3068:                "An Anycast Prefix for 6to4 Relay Routers",
+	3171:                "IANA Guidelines for IPv4 Multicast Address Assignments",
+	3330:                "Special-Use IPv4 Addresses",
+	3849:                "IPv6 Address Prefix Reserved for Documentation",
+	3927:                "Dynamic Configuration of IPv4 Link-Local Addresses",
+	4038:                "Application Aspects of IPv6 Transition",
+	4193:                "Unique Local IPv6 Unicast Addresses",
+	4291:                "IP Version 6 Addressing Architecture",
+	4380:                "Teredo: Tunneling IPv6 over UDP through Network Address Translations (NATs)",
+	4773:                "Administration of the IANA Special Purpose IPv6 Address Block",
+	4843:                "An IPv6 Prefix for Overlay Routable Cryptographic Hash Identifiers (ORCHID)",
+	5180:                "IPv6 Benchmarking Methodology for Network Interconnect Devices",
+	5735:                "Special Use IPv4 Addresses",
+	5737:                "IPv4 Address Blocks Reserved for Documentation",
+	6052:                "IPv6 Addressing of IPv4/IPv6 Translators",
+	6333:                "Dual-Stack Lite Broadband Deployments Following IPv4 Exhaustion",
+	6598:                "IANA-Reserved IPv4 Prefix for Shared Address Space",
+	6666:                "A Discard Prefix for IPv6",
+	6890:                "Special-Purpose IP Address Registries",
+	7335:                "IPv4 Service Continuity Prefix",
+	ForwardingBlacklist: "Non-forwardable IP blocks",
+}
+
+// ensure seeds the registry with the built-in RFCs on first use.
+func (r *rfcRegistry) ensure() {
+	r.once.Do(func() {
+		r.lock.Lock()
+		defer r.lock.Unlock()
+		r.reset()
+	})
+}
+
+// reset replaces the registry with the built-in RFCs.  The blocks of each RFC
+// inherit the properties of identical RFC 6890 special-purpose blocks, but the
+// networks of each entry are exactly those of KnownRFCs().  Must be called with
+// the lock held.
+func (r *rfcRegistry) reset() {
+	r.special = rfc6890Blocks()
+	specialByNetwork := make(map[string]RFCBlock, len(r.special))
+	for _, block := range r.special {
+		specialByNetwork[block.Network.String()] = block
+	}
+
+	known := KnownRFCs()
+	r.entries = make(map[uint]RFCEntry, len(known))
+	for rfcNum, sas := range known {
+		entry := RFCEntry{
+			Number: rfcNum,
+			Title:  knownRFCTitles[rfcNum],
+		}
+
+		for _, sa := range sas {
+			block, found := specialByNetwork[sa.String()]
+			if !found {
+				block = RFCBlock{Network: *ToIPAddr(sa)}
+			}
+			entry.addBlock(block)
+		}
+
+		r.entries[rfcNum] = entry
+	}
+}
+
+// lookup returns a copy of the entry for rfcNum.
+func (r *rfcRegistry) lookup(rfcNum uint) (RFCEntry, bool) {
+	r.ensure()
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	entry, found := r.entries[rfcNum]
+	return entry.copy(), found
+}
+
+// snapshot returns a copy of every entry in the registry.
+func (r *rfcRegistry) snapshot() []RFCEntry {
+	r.ensure()
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	entries := make([]RFCEntry, 0, len(r.entries))
+	for _, entry := range r.entries {
+		entries = append(entries, entry.copy())
+	}
+	return entries
+}
+
+// specialPurpose returns the properties of the most specific block with known
+// properties that contains sa.  The registered blocks are examined in the order
+// of their RFC numbers before the built-in RFC 6890 blocks, and the first of
+// several equally specific blocks wins.
+func (r *rfcRegistry) specialPurpose(sa SockAddr) (SpecialPurpose, bool) {
+	r.ensure()
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	rfcNums := make([]uint, 0, len(r.entries))
+	for rfcNum := range r.entries {
+		rfcNums = append(rfcNums, rfcNum)
+	}
+	sort.Slice(rfcNums, func(i, j int) bool { return rfcNums[i] < rfcNums[j] })
+
+	var best *RFCBlock
+	visit := func(blocks []RFCBlock) {
+		for i := range blocks {
+			block := &blocks[i]
+			if block.SpecialPurpose == nil || !block.Network.Contains(sa) {
+				continue
+			}
+
+			if best == nil || block.Network.Maskbits() > best.Network.Maskbits() {
+				best = block
+			}
+		}
+	}
+	for _, rfcNum := range rfcNums {
+		visit(r.entries[rfcNum].Blocks)
+	}
+	visit(r.special)
+
+	if best == nil {
+		return SpecialPurpose{}, false
+	}
+	return *best.SpecialPurpose, true
+}
+
+// Networks returns the networks of every block in the entry.
+func (e RFCEntry) Networks() SockAddrs {
+	sas := make(SockAddrs, 0, len(e.Blocks))
+	for _, block := range e.Blocks {
+		sas = append(sas, block.Network)
+	}
+	return sas
+}
+
+// addBlock adds block to the entry.  If the entry already has a block for the
+// same network, the existing block's name and properties are only replaced
+// when block provides them.
+func (e *RFCEntry) addBlock(block RFCBlock) {
+	for i, existing := range e.Blocks {
+		if !existing.Network.Equal(block.Network) {
+			continue
+		}
+
+		if block.Name != "" {
+			e.Blocks[i].Name = block.Name
+		}
+		if block.SpecialPurpose != nil {
+			e.Blocks[i].SpecialPurpose = block.SpecialPurpose
+		}
+		return
+	}
+
+	e.Blocks = append(e.Blocks, block)
+}
+
+// copy returns a copy of the entry that does not share its blocks.
+func (e RFCEntry) copy() RFCEntry {
+	e.Blocks = append([]RFCBlock(nil), e.Blocks...)
+	for i, block := range e.Blocks {
+		if block.SpecialPurpose != nil {
+			sp := *block.SpecialPurpose
+			e.Blocks[i].SpecialPurpose = &sp
+		}
+	}
+	return e
+}
+
+// RegisterRFC adds networks to the registry under rfcNum.  If rfcNum is
+// already registered, the networks are appended to the existing entry and
+// the title is replaced unless title is empty.  Only IP networks may be
+// registered.
+func RegisterRFC(rfcNum uint, title string, networks SockAddrs) error {
+	blocks, err := rfcBlocks(networks)
+	if err != nil {
+		return err
+	}
+
+	return RegisterRFCBlocks(rfcNum, title, blocks...)
+}
+
+// RegisterRFCBlocks is like RegisterRFC but registers blocks with a name and
+// RFC 6890 properties.  A block for a network that is already registered
+// under rfcNum replaces the existing block's name and properties.
+func RegisterRFCBlocks(rfcNum uint, title string, blocks ...RFCBlock) error {
+	for _, block := range blocks {
+		if block.Network == nil {
+			return fmt.Errorf("unable to register a block without a network")
+		}
+	}
+
+	rfcs.ensure()
+	rfcs.lock.Lock()
+	defer rfcs.lock.Unlock()
+
+	entry, found := rfcs.entries[rfcNum]
+	if !found {
+		entry = RFCEntry{Number: rfcNum}
+	}
+	if title != "" {
+		entry.Title = title
+	}
+
+	for _, block := range blocks {
+		entry.addBlock(block)
+	}
+
+	rfcs.entries[rfcNum] = entry
+	return nil
+}
+
+// OverrideRFC replaces the title and networks registered under rfcNum,
+// registering rfcNum if it is unknown.
+func OverrideRFC(rfcNum uint, title string, networks SockAddrs) error {
+	blocks, err := rfcBlocks(networks)
+	if err != nil {
+		return err
+	}
+
+	rfcs.ensure()
+	rfcs.lock.Lock()
+	defer rfcs.lock.Unlock()
+
+	rfcs.entries[rfcNum] = RFCEntry{
+		Number: rfcNum,
+		Title:  title,
+		Blocks: blocks,
+	}
+	return nil
+}
+
+// UnregisterRFC removes rfcNum from the registry.  Returns false if rfcNum
+// was not registered.
+func UnregisterRFC(rfcNum uint) bool {
+	rfcs.ensure()
+	rfcs.lock.Lock()
+	defer rfcs.lock.Unlock()
+
+	_, found := rfcs.entries[rfcNum]
+	delete(rfcs.entries, rfcNum)
+	return found
+}
+
+// ResetRFCs restores the registry to the built-in set of RFCs returned by
+// KnownRFCs().
+func ResetRFCs() {
+	rfcs.ensure()
+	rfcs.lock.Lock()
+	defer rfcs.lock.Unlock()
+	rfcs.reset()
+}
+
+// LookupRFC returns the registry entry for rfcNum.
+func LookupRFC(rfcNum uint) (RFCEntry, bool) {
+	return rfcs.lookup(rfcNum)
+}
+
+// SpecialPurposeOf returns the RFC 6890 properties of the most specific
+// registered block that contains sa.  Returns false if sa is not within a
+// block with known properties.
+func SpecialPurposeOf(sa SockAddr) (SpecialPurpose, bool) {
+	if sa == nil || sa.Type()&TypeIP == 0 {
+		return SpecialPurpose{}, false
+	}
+
+	return rfcs.specialPurpose(sa)
+}
+
+// IsDestination returns true if sa may be used as a destination address.
+// Addresses outside of the special-purpose blocks are valid destinations.
+func IsDestination(sa SockAddr) bool {
+	if sa == nil || sa.Type()&TypeIP == 0 {
+		return false
+	}
+
+	sp, found := SpecialPurposeOf(sa)
+	return !found || sp.Destination
+}
+
+// IsForwardable returns true if a router may forward a datagram addressed to
+// sa.  Addresses outside of the special-purpose blocks are forwardable.
+func IsForwardable(sa SockAddr) bool {
+	if sa == nil || sa.Type()&TypeIP == 0 {
+		return false
+	}
+
+	sp, found := SpecialPurposeOf(sa)
+	return !found || sp.Forwardable
+}
+
+// IsGlobal returns true if a datagram addressed to sa may be forwarded beyond
+// its administrative domain.  Addresses outside of the special-purpose blocks
+// are global.
+func IsGlobal(sa SockAddr) bool {
+	if sa == nil || sa.Type()&TypeIP == 0 {
+		return false
+	}
+
+	sp, found := SpecialPurposeOf(sa)
+	return !found || sp.Global
+}
+
+// LoadIANARegistryFile loads an IANA special-purpose address registry (e.g.
+// iana-ipv4-special-registry-1.csv or iana-ipv6-special-registry.xml) into
+// the RFC registry.  See LoadIANARegistry.
+func LoadIANARegistryFile(path string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("unable to open IANA registry: %v", err)
+	}
+	defer f.Close()
+
+	return LoadIANARegistry(f)
+}
+
+// LoadIANARegistry loads an IANA special-purpose address registry in either
+// its CSV or XML format into the RFC registry.  Each address block is
+// registered (see RegisterRFCBlocks) under every RFC referenced by the block,
+// along with its RFC 6890 properties if the registry includes them.  Blocks
+// that do not reference an RFC are skipped.  Returns the number of blocks
+// that were registered.
+func LoadIANARegistry(r io.Reader) (int, error) {
+	br := bufio.NewReader(r)
+	for {
+		c, _, err := br.ReadRune()
+		if err != nil {
+			return 0, fmt.Errorf("unable to read IANA registry: %v", err)
+		}
+
+		// Skip leading whitespace and byte order marks
+		if unicode.IsSpace(c) || c == '\uFEFF' {
+			continue
+		}
+
+		if err := br.UnreadRune(); err != nil {
+			return 0, fmt.Errorf("unable to read IANA registry: %v", err)
+		}
+
+		if c == '<' {
+			return loadIANARegistryXML(br)
+		}
+		return loadIANARegistryCSV(br)
+	}
+}
+
+// ianaPropertyColumns are the names of the RFC 6890 property columns in the
+// CSV format of an IANA special-purpose address registry.
+var ianaPropertyColumns = []string{"source", "destination", "forwardable", "globally reachable", "reserved-by-protocol"}
+
+// loadIANARegistryCSV loads the CSV format of an IANA special-purpose address
+// registry.  The "Address Block", "Name", and "RFC" columns are required.
+func loadIANARegistryCSV(r io.Reader) (int, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if err != nil {
+		return 0, fmt.Errorf("unable to read IANA registry header: %v", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"address block", "name", "rfc"} {
+		if _, found := columns[name]; !found {
+			return 0, fmt.Errorf("IANA registry is missing the %q column", name)
+		}
+	}
+
+	hasProperties := true
+	for _, name := range ianaPropertyColumns {
+		if _, found := columns[name]; !found {
+			hasProperties = false
+		}
+	}
+
+	var blocks []ianaBlock
+	for line := 2; ; line++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, fmt.Errorf("unable to read IANA registry: %v", err)
+		}
+
+		field := func(name string) string {
+			i, found := columns[name]
+			if !found || i >= len(record) {
+				return ""
+			}
+			return record[i]
+		}
+
+		block, err := newIANABlock(field("address block"), field("name"), ianaRFCNumbers(field("rfc")))
+		if err != nil {
+			return 0, fmt.Errorf("line %d: %v", line, err)
+		}
+
+		if hasProperties {
+			block.specialPurpose = &SpecialPurpose{
+				Source:              ianaBool(field("source")),
+				Destination:         ianaBool(field("destination")),
+				Forwardable:         ianaBool(field("forwardable")),
+				Global:              ianaBool(field("globally reachable")),
+				GlobalNotApplicable: ianaNotApplicable(field("globally reachable")),
+				ReservedByProtocol:  ianaBool(field("reserved-by-protocol")),
+			}
+		}
+
+		blocks = append(blocks, block)
+	}
+
+	return registerIANABlocks(blocks)
+}
+
+// ianaXMLRecord is a record in the XML format of an IANA special-purpose
+// address registry.
+type ianaXMLRecord struct {
+	Address string `xml:"address"`
+	Name    string `xml:"name"`
+	Spec    struct {
+		Text  string `xml:",chardata"`
+		Xrefs []struct {
+			Type string `xml:"type,attr"`
+			Data string `xml:"data,attr"`
+		} `xml:"xref"`
+	} `xml:"spec"`
+	Source      *string `xml:"source"`
+	Destination *string `xml:"destination"`
+	Forwardable *string `xml:"forwardable"`
+	Global      *string `xml:"global"`
+	Reserved    *string `xml:"reserved"`
+}
+
+// loadIANARegistryXML loads the XML format of an IANA special-purpose address
+// registry.  Every <record> element is loaded, regardless of nesting.
+func loadIANARegistryXML(r io.Reader) (int, error) {
+	d := xml.NewDecoder(r)
+
+	var blocks []ianaBlock
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, fmt.Errorf("unable to read IANA registry: %v", err)
+		}
+
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local != "record" {
+			continue
+		}
+
+		var record ianaXMLRecord
+		if err := d.DecodeElement(&record, &start); err != nil {
+			return 0, fmt.Errorf("unable to decode IANA registry record: %v", err)
+		}
+
+		var rfcNums []uint
+		for _, xref := range record.Spec.Xrefs {
+			if xref.Type == "rfc" {
+				rfcNums = append(rfcNums, ianaRFCNumbers(xref.Data)...)
+			}
+		}
+		rfcNums = append(rfcNums, ianaRFCNumbers(record.Spec.Text)...)
+
+		block, err := newIANABlock(record.Address, record.Name, rfcNums)
+		if err != nil {
+			return 0, err
+		}
+
+		if record.Source != nil && record.Destination != nil && record.Forwardable != nil &&
+			record.Global != nil && record.Reserved != nil {
+			block.specialPurpose = &SpecialPurpose{
+				Source:              ianaBool(*record.Source),
+				Destination:         ianaBool(*record.Destination),
+				Forwardable:         ianaBool(*record.Forwardable),
+				Global:              ianaBool(*record.Global),
+				GlobalNotApplicable: ianaNotApplicable(*record.Global),
+				ReservedByProtocol:  ianaBool(*record.Reserved),
+			}
+		}
+
+		blocks = append(blocks, block)
+	}
+
+	return registerIANABlocks(blocks)
+}
+
+// ianaBlock is an address block parsed from an IANA registry.
+type ianaBlock struct {
+	name           string
+	networks       []IPAddr
+	rfcNums        []uint
+	specialPurpose *SpecialPurpose
+}
+
+// newIANABlock parses the address column of an IANA registry.  The column may
+// hold several comma separated networks and footnote markers.
+func newIANABlock(addresses, name string, rfcNums []uint) (ianaBlock, error) {
+	block := ianaBlock{
+		name:    strings.TrimSpace(name),
+		rfcNums: rfcNums,
+	}
+
+	addresses = ianaFootnoteRE.ReplaceAllString(addresses, "")
+	for _, address := range strings.Split(addresses, ",") {
+		address = strings.TrimSpace(address)
+		if address == "" {
+			continue
+		}
+
+		ip, err := NewIPAddr(address)
+		if err != nil {
+			return ianaBlock{}, fmt.Errorf("invalid address block %q: %v", address, err)
+		}
+		block.networks = append(block.networks, ip)
+	}
+
+	if len(block.networks) == 0 {
+		return ianaBlock{}, fmt.Errorf("no address block for %q", block.name)
+	}
+
+	return block, nil
+}
+
+// registerIANABlocks registers every block that references an RFC.  Titles
+// are only set for RFCs that do not already have one.
+func registerIANABlocks(blocks []ianaBlock) (int, error) {
+	var n int
+	for _, block := range blocks {
+		if len(block.rfcNums) == 0 {
+			continue
+		}
+
+		rfcBlocks := make([]RFCBlock, 0, len(block.networks))
+		for _, network := range block.networks {
+			rfcBlocks = append(rfcBlocks, RFCBlock{
+				Network:        network,
+				Name:           block.name,
+				SpecialPurpose: block.specialPurpose,
+			})
+		}
+
+		for _, rfcNum := range block.rfcNums {
+			var title string
+			if entry, found := LookupRFC(rfcNum); !found || entry.Title == "" {
+				title = block.name
+			}
+
+			if err := RegisterRFCBlocks(rfcNum, title, rfcBlocks...); err != nil {
+				return n, err
+			}
+		}
+		n++
+	}
+
+	return n, nil
+}
+
+// ianaRFCNumbers returns the unique RFC numbers referenced in s.
+func ianaRFCNumbers(s string) []uint {
+	var rfcNums []uint
+	seen := make(map[uint]bool)
+	for _, match := range ianaRFCRE.FindAllStringSubmatch(s, -1) {
+		rfcNum, err := strconv.ParseUint(match[1], 10, 32)
+		if err != nil || seen[uint(rfcNum)] {
+			continue
+		}
+		seen[uint(rfcNum)] = true
+		rfcNums = append(rfcNums, uint(rfcNum))
+	}
+	return rfcNums
+}
+
+// ianaBool parses a boolean from an IANA registry.  Values such as
+// "False [1]" and "N/A" are accepted, the latter as false.
+func ianaBool(s string) bool {
+	s = ianaFootnoteRE.ReplaceAllString(s, "")
+	return strings.EqualFold(strings.TrimSpace(s), "true")
+}
+
+// ianaNotApplicable returns true if a value of an IANA registry is "N/A".
+func ianaNotApplicable(s string) bool {
+	s = ianaFootnoteRE.ReplaceAllString(s, "")
+	return strings.EqualFold(strings.TrimSpace(s), "N/A")
+}
+
+// rfcBlocks returns a block without a name or properties for each network.
+// Returns an error if any of the networks is not an IP network.
+func rfcBlocks(networks SockAddrs) ([]RFCBlock, error) {
+	blocks := make([]RFCBlock, 0, len(networks))
+	for _, network := range networks {
+		ip := ToIPAddr(network)
+		if network == nil || ip == nil {
+			return nil, fmt.Errorf("unable to register %v: only IP networks can be registered", network)
+		}
+		blocks = append(blocks, RFCBlock{Network: *ip})
+	}
+	return blocks, nil
+}
@@ -0,0 +1,321 @@
+package sockaddr_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	sockaddr "github.com/hashicorp/go-sockaddr"
+)
+
+const ianaIPv4RegistryCSV = `Address Block,Name,RFC,Allocation Date,Termination Date,Source,Destination,Forwardable,Globally Reachable,Reserved-by-Protocol
+0.0.0.0/8,"""This network""","[RFC791], Section 3.2",1981-09,N/A,True,False,False,False,True
+192.0.0.0/24 [2],IETF Protocol Assignments,[RFC6890],2010-01,N/A,False,False,False,False,False
+192.31.196.0/24,AS112-v4,[RFC7535],2014-12,N/A,True,True,True,True,False
+198.18.0.0/15,Benchmarking,[RFC2544],1999-03,N/A,True,True,True,False,False
+`
+
+const ianaIPv6RegistryXML = `<?xml version='1.0' encoding='UTF-8'?>
+<registry xmlns="http://www.iana.org/assignments" id="iana-ipv6-special-registry">
+  <title>IANA IPv6 Special-Purpose Address Registry</title>
+  <registry id="iana-ipv6-special-registry-1">
+    <record>
+      <address>64:ff9b:1::/48</address>
+      <name>IPv4-IPv6 Translat.</name>
+      <spec><xref type="rfc" data="rfc8215"/></spec>
+      <allocation>2017-06</allocation>
+    </record>
+    <record>
+      <address>2001::/23 <xref type="note" data="1"/></address>
+      <name>IETF Protocol Assignments</name>
+      <spec><xref type="rfc" data="rfc2928"/></spec>
+    </record>
+    <record>
+      <address>2620:4f:8000::/48</address>
+      <name>Direct Delegation AS112 Service</name>
+      <spec><xref type="rfc" data="rfc7534"/></spec>
+    </record>
+  </registry>
+</registry>
+`
+
+func TestRegisterRFC(t *testing.T) {
+	defer sockaddr.ResetRFCs()
+
+	const internalRFC = 4200000001
+	internal := sockaddr.MustIPAddr("100.127.0.0/16")
+	if sockaddr.IsRFC(internalRFC, internal) {
+		t.Fatalf("unregistered RFC matched")
+	}
+
+	if err := sockaddr.RegisterRFC(internalRFC, "Internal", sockaddr.SockAddrs{internal}); err != nil {
+		t.Fatalf("unable to register: %v", err)
+	}
+	if err := sockaddr.RegisterRFC(internalRFC, "", sockaddr.SockAddrs{internal, sockaddr.MustIPAddr("fd00:1::/32")}); err != nil {
+		t.Fatalf("unable to register: %v", err)
+	}
+
+	entry, found := sockaddr.LookupRFC(internalRFC)
+	if !found || entry.Title != "Internal" || len(entry.Networks()) != 2 {
+		t.Fatalf("unexpected entry: %+v", entry)
+	}
+	if !sockaddr.IsRFC(internalRFC, sockaddr.MustIPAddr("100.127.1.1")) {
+		t.Fatalf("registered RFC did not match")
+	}
+
+	ifAddrs := sockaddr.IfAddrs{
+		{SockAddr: sockaddr.MustIPAddr("100.127.1.1")},
+		{SockAddr: sockaddr.MustIPAddr("10.0.0.1")},
+	}
+	matched, _, err := sockaddr.IfByRFC("4200000001", ifAddrs)
+	if err != nil || len(matched) != 1 {
+		t.Fatalf("IfByRFC did not consult the registry: %v %v", matched, err)
+	}
+
+	var visited bool
+	sockaddr.VisitAllRFCs(func(rfcNum uint, sas sockaddr.SockAddrs) {
+		if rfcNum == internalRFC {
+			visited = true
+		}
+	})
+	if !visited {
+		t.Fatalf("VisitAllRFCs did not visit the registered RFC")
+	}
+
+	if err := sockaddr.RegisterRFC(internalRFC, "", sockaddr.SockAddrs{sockaddr.MustUnixSock("/tmp/foo")}); err == nil {
+		t.Fatalf("expected registration of a UNIX socket to fail")
+	}
+}
+
+func TestOverrideRFC(t *testing.T) {
+	defer sockaddr.ResetRFCs()
+
+	if err := sockaddr.OverrideRFC(1918, "Private", sockaddr.SockAddrs{sockaddr.MustIPAddr("10.0.0.0/8")}); err != nil {
+		t.Fatalf("unable to override: %v", err)
+	}
+	if sockaddr.IsRFC(1918, sockaddr.MustIPAddr("192.168.1.1")) {
+		t.Fatalf("overridden network still matched")
+	}
+	if !sockaddr.IsRFC(1918, sockaddr.MustIPAddr("10.1.1.1")) {
+		t.Fatalf("override did not match")
+	}
+
+	if !sockaddr.UnregisterRFC(1918) {
+		t.Fatalf("unable to unregister")
+	}
+	if sockaddr.UnregisterRFC(1918) {
+		t.Fatalf("unregistered twice")
+	}
+	if _, _, err := sockaddr.IfByRFC("1918", nil); err == nil {
+		t.Fatalf("expected an unregistered RFC to fail")
+	}
+
+	sockaddr.ResetRFCs()
+	if !sockaddr.IsRFC(1918, sockaddr.MustIPAddr("192.168.1.1")) {
+		t.Fatalf("reset did not restore RFC 1918")
+	}
+}
+
+func TestLoadIANARegistry(t *testing.T) {
+	defer sockaddr.ResetRFCs()
+
+	tests := []struct {
+		name     string
+		input    string
+		numBlock int
+		rfcNum   uint
+		title    string
+		match    string
+	}{
+		{
+			name:     "csv",
+			input:    ianaIPv4RegistryCSV,
+			numBlock: 4,
+			rfcNum:   7535,
+			title:    "AS112-v4",
+			match:    "192.31.196.1",
+		},
+		{
+			name:     "xml",
+			input:    "\n" + ianaIPv6RegistryXML,
+			numBlock: 3,
+			rfcNum:   8215,
+			title:    "IPv4-IPv6 Translat.",
+			match:    "64:ff9b:1::1",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			n, err := sockaddr.LoadIANARegistry(strings.NewReader(test.input))
+			if err != nil {
+				t.Fatalf("unable to load registry: %v", err)
+			}
+			if n != test.numBlock {
+				t.Fatalf("want %d blocks, got %d", test.numBlock, n)
+			}
+
+			entry, found := sockaddr.LookupRFC(test.rfcNum)
+			if !found || entry.Title != test.title {
+				t.Fatalf("unexpected entry: %+v", entry)
+			}
+			if !sockaddr.IsRFC(test.rfcNum, sockaddr.MustIPAddr(test.match)) {
+				t.Fatalf("%s is not part of RFC %d", test.match, test.rfcNum)
+			}
+		})
+	}
+
+	// Properties are loaded from registries that include them.
+	sp, found := sockaddr.SpecialPurposeOf(sockaddr.MustIPAddr("192.31.196.1"))
+	if !found || !sp.Global || sp.ReservedByProtocol {
+		t.Fatalf("unexpected properties: %+v", sp)
+	}
+	if _, found := sockaddr.SpecialPurposeOf(sockaddr.MustIPAddr("64:ff9b:1::1")); found {
+		t.Fatalf("properties were set for a registry without them")
+	}
+
+	// A global reachability of N/A is kept apart from false.
+	const notApplicableCSV = `Address Block,Name,RFC,Source,Destination,Forwardable,Globally Reachable,Reserved-by-Protocol
+2002::/16 [9],6to4,[RFC3056],True,True,True,N/A [2],False
+`
+	if _, err := sockaddr.LoadIANARegistry(strings.NewReader(notApplicableCSV)); err != nil {
+		t.Fatalf("unable to load registry: %v", err)
+	}
+	sp, found = sockaddr.SpecialPurposeOf(sockaddr.MustIPAddr("2002::1"))
+	if !found || sp.Global || !sp.GlobalNotApplicable {
+		t.Fatalf("unexpected properties: %+v", sp)
+	}
+
+	// Loaded blocks are added to existing RFCs without replacing their title.
+	entry, _ := sockaddr.LookupRFC(2928)
+	if entry.Title != "Initial IPv6 Sub-TLA ID Assignments" {
+		t.Fatalf("title of an existing RFC was replaced: %q", entry.Title)
+	}
+
+	if _, err := sockaddr.LoadIANARegistry(strings.NewReader("Address Block,Name\n10.0.0.0/8,foo\n")); err == nil {
+		t.Fatalf("expected a registry without an RFC column to fail")
+	}
+	if _, err := sockaddr.LoadIANARegistry(strings.NewReader("Address Block,Name,RFC\nbogus,foo,[RFC1]\n")); err == nil {
+		t.Fatalf("expected an invalid address block to fail")
+	}
+}
+
+func TestSpecialPurposeOf(t *testing.T) {
+	tests := []struct {
+		addr        string
+		found       bool
+		destination bool
+		forwardable bool
+		global      bool
+	}{
+		{addr: "8.8.8.8", destination: true, forwardable: true, global: true},
+		{addr: "10.1.2.3", found: true, destination: true, forwardable: true},
+		{addr: "127.0.0.1", found: true},
+		{addr: "169.254.1.1", found: true, destination: true},
+		{addr: "192.0.0.1", found: true, destination: true, forwardable: true},
+		{addr: "192.0.0.100", found: true},
+		{addr: "255.255.255.255", found: true, destination: true},
+		{addr: "2001::1", found: true, destination: true, forwardable: true},
+		{addr: "2001:4::1", found: true},
+		{addr: "2001:db8::1", found: true},
+		{addr: "2600::1", destination: true, forwardable: true, global: true},
+		{addr: "fe80::1", found: true, destination: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.addr, func(t *testing.T) {
+			ip := sockaddr.MustIPAddr(test.addr)
+			if _, found := sockaddr.SpecialPurposeOf(ip); found != test.found {
+				t.Fatalf("want found %t", test.found)
+			}
+			if got := sockaddr.IsDestination(ip); got != test.destination {
+				t.Fatalf("want destination %t", test.destination)
+			}
+			if got := sockaddr.IsForwardable(ip); got != test.forwardable {
+				t.Fatalf("want forwardable %t", test.forwardable)
+			}
+			if got := sockaddr.IsGlobal(ip); got != test.global {
+				t.Fatalf("want global %t", test.global)
+			}
+		})
+	}
+
+	if sockaddr.IsForwardable(sockaddr.MustUnixSock("/tmp/foo")) {
+		t.Fatalf("UNIX sockets are not forwardable")
+	}
+
+	if sp, _ := sockaddr.SpecialPurposeOf(sockaddr.MustIPAddr("255.255.255.255")); !sp.ReservedByProtocol {
+		t.Errorf("the limited broadcast address is reserved by protocol")
+	}
+
+	// The properties of the special-purpose blocks do not add them to the
+	// RFC 6890 entry.
+	entry, found := sockaddr.LookupRFC(6890)
+	if !found {
+		t.Fatalf("RFC 6890 is not registered")
+	}
+	if got, want := entry.Networks(), sockaddr.KnownRFCs()[6890]; !reflect.DeepEqual(got, want) {
+		t.Errorf("want the RFC 6890 networks %v, got %v", want, got)
+	}
+}
+
+func TestRegisterRFCBlocks(t *testing.T) {
+	defer sockaddr.ResetRFCs()
+
+	ip := sockaddr.MustIPAddr("100.127.1.1")
+	if !sockaddr.IsForwardable(ip) {
+		t.Fatalf("%s is not forwardable", ip)
+	}
+
+	block := sockaddr.RFCBlock{
+		Network:        sockaddr.MustIPAddr("100.127.0.0/16"),
+		Name:           "Internal",
+		SpecialPurpose: &sockaddr.SpecialPurpose{Source: true, Destination: true},
+	}
+	if err := sockaddr.RegisterRFCBlocks(4200000001, "Internal", block); err != nil {
+		t.Fatalf("unable to register: %v", err)
+	}
+
+	// 100.127.0.0/16 is more specific than RFC 6598's 100.64.0.0/10
+	if sockaddr.IsForwardable(ip) || !sockaddr.IsDestination(ip) {
+		t.Fatalf("properties of the most specific block were not used")
+	}
+
+	// Of equally specific blocks, the one of the lowest RFC number wins.
+	other := block
+	other.SpecialPurpose = &sockaddr.SpecialPurpose{Forwardable: true}
+	if err := sockaddr.RegisterRFCBlocks(4200000002, "Other", other); err != nil {
+		t.Fatalf("unable to register: %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		if sockaddr.IsForwardable(ip) {
+			t.Fatalf("properties of RFC 4200000002 were used over those of RFC 4200000001")
+		}
+	}
+	sockaddr.UnregisterRFC(4200000002)
+
+	ifAddrs := sockaddr.IfAddrs{
+		{SockAddr: ip},
+		{SockAddr: sockaddr.MustIPAddr("203.0.113.1")},
+		{SockAddr: sockaddr.MustIPAddr("1.1.1.1")},
+	}
+	tests := []struct {
+		flags string
+		want  int
+	}{
+		{flags: "forwardable", want: 1},
+		{flags: "global", want: 1},
+		{flags: "destination", want: 2},
+		{flags: "forwardable|destination", want: 2},
+		{flags: "global|destination", want: 2},
+	}
+	for _, test := range tests {
+		matched, _, err := sockaddr.IfByFlag(test.flags, ifAddrs)
+		if err != nil {
+			t.Fatalf("unable to filter by %q: %v", test.flags, err)
+		}
+		if len(matched) != test.want {
+			t.Fatalf("%q: want %d matches, got %v", test.flags, test.want, matched)
+		}
+	}
+}
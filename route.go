@@ -0,0 +1,530 @@
+package sockaddr
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Route is an entry in the routing table.
+type Route struct {
+	// Destination is the network reachable through this route.  Default
+	// routes have a mask length of zero (i.e. 0.0.0.0/0 or ::/0).
+	Destination IPAddr
+
+	// Gateway is the next hop, or nil for directly connected networks.
+	Gateway IPAddr
+
+	// Source is the preferred source address, or nil if the route does not
+	// specify one.
+	Source IPAddr
+
+	// Metric is the priority of the route.  Lower metrics are preferred.
+	Metric uint32
+
+	// IfName is the name of the outgoing interface.
+	IfName string
+
+	// Table is the name of the routing table (e.g. "main" or "local"), or
+	// its number if the table has no well known name.  Empty if unknown.
+	Table string
+
+	// Protocol is the origin of the route (e.g. "kernel", "static" or
+	// "dhcp").  Empty if unknown.
+	Protocol string
+}
+
+// IsDefault returns true if the route is a default route.
+func (r Route) IsDefault() bool {
+	return r.Destination != nil && r.Destination.Maskbits() == 0
+}
+
+// GetDefaultRoutes returns the default routes of the main routing table of the
+// given address type (TypeIPv4 or TypeIPv6) ordered by metric, lowest metric
+// first.
+func GetDefaultRoutes(sockType SockAddrType) ([]Route, error) {
+	return defaultSystem().GetDefaultRoutes(sockType)
+}
+
+// GetDefaultRoutes is like the package-level GetDefaultRoutes, but inspects the
+// host of s.
+func (s *System) GetDefaultRoutes(sockType SockAddrType) ([]Route, error) {
+	snapshot := s.routeSnapshot()
+	if snapshot.routesErr != nil {
+		return nil, snapshot.routesErr
+	}
+
+	return defaultRoutes(snapshot.routes, sockType), nil
+}
+
+// inMainTable returns true if the route is in the main routing table, or in
+// the "default" table the kernel consults after it.  Routes of unknown tables
+// are assumed to be in the main table.  Routes of policy routing tables (e.g.
+// the table of a WireGuard tunnel) are only used for selected traffic.
+func (r Route) inMainTable() bool {
+	switch r.Table {
+	case "", "main", "default":
+		return true
+	default:
+		return false
+	}
+}
+
+// defaultRoutes returns the default routes of the main routing table of the
+// given address type ordered by metric.  Routes through the loopback interface
+// are skipped.
+func defaultRoutes(routes []Route, sockType SockAddrType) []Route {
+	var defaults []Route
+	for _, route := range routes {
+		if route.IsDefault() && route.inMainTable() && route.Destination.Type() == sockType && route.IfName != "" && route.IfName != "lo" {
+			defaults = append(defaults, route)
+		}
+	}
+
+	sort.SliceStable(defaults, func(i, j int) bool {
+		return defaults[i].Metric < defaults[j].Metric
+	})
+	return defaults
+}
+
+// defaultIfNames returns the names of the interfaces with a default route.
+// Interfaces with an IPv4 default route come first, ordered by metric,
+// followed by interfaces that only have an IPv6 default route.
+func defaultIfNames(routes []Route) []string {
+	var ifNames []string
+	seen := make(map[string]bool)
+	for _, sockType := range []SockAddrType{TypeIPv4, TypeIPv6} {
+		for _, route := range defaultRoutes(routes, sockType) {
+			if !seen[route.IfName] {
+				seen[route.IfName] = true
+				ifNames = append(ifNames, route.IfName)
+			}
+		}
+	}
+	return ifNames
+}
+
+// defaultGateway returns the gateway of the lowest metric default route of the
+// main routing table of the given address type that leaves through ifName.
+func defaultGateway(routes []Route, ifName string, sockType SockAddrType) IPAddr {
+	var best *Route
+	for i := range routes {
+		route := &routes[i]
+		if !route.IsDefault() || !route.inMainTable() || route.Gateway == nil || route.IfName != ifName || route.Destination.Type() != sockType {
+			continue
+		}
+
+		if best == nil || route.Metric < best.Metric {
+			best = route
+		}
+	}
+
+	if best == nil {
+		return nil
+	}
+	return best.Gateway
+}
+
+// Routing table, protocol and attribute constants from the Linux kernel's
+// <linux/rtnetlink.h> and <linux/netlink.h>.
+const (
+	nlmsgHdrLen    = 16
+	nlmsgError     = 2
+	nlmsgDone      = 3
+	rtmNewRoute    = 24
+	rtMsgLen       = 12
+	rtNexthopLen   = 8
+	rtaHdrLen      = 4
+	rtaDst         = 1
+	rtaOif         = 4
+	rtaGateway     = 5
+	rtaPriority    = 6
+	rtaPrefSrc     = 7
+	rtaMultipath   = 9
+	rtaTable       = 15
+	rtnUnicast     = 1
+	rtmFCloned     = 0x200
+	afInet         = 2
+	afInet6        = 10
+	rtTableDefault = 253
+	rtTableMain    = 254
+	rtTableLocal   = 255
+)
+
+// rtProtocolNames maps route protocol numbers to the names used by ip(8).
+var rtProtocolNames = map[uint8]string{
+	0:   "unspec",
+	1:   "redirect",
+	2:   "kernel",
+	3:   "boot",
+	4:   "static",
+	8:   "gated",
+	9:   "ra",
+	10:  "mrt",
+	11:  "zebra",
+	12:  "bird",
+	13:  "dnrouted",
+	14:  "xorp",
+	15:  "ntk",
+	16:  "dhcp",
+	17:  "mrouted",
+	18:  "keepalived",
+	42:  "babel",
+	186: "bgp",
+	187: "isis",
+	188: "ospf",
+	189: "rip",
+	192: "eigrp",
+}
+
+// rtTableName returns the name ip(8) uses for a routing table number.
+func rtTableName(table uint32) string {
+	switch table {
+	case rtTableDefault:
+		return "default"
+	case rtTableMain:
+		return "main"
+	case rtTableLocal:
+		return "local"
+	default:
+		return strconv.FormatUint(uint64(table), 10)
+	}
+}
+
+// rtProtocolName returns the name ip(8) uses for a route protocol number.
+func rtProtocolName(protocol uint8) string {
+	if name, found := rtProtocolNames[protocol]; found {
+		return name
+	}
+	return strconv.FormatUint(uint64(protocol), 10)
+}
+
+// parseNetlinkRoutes parses the RTM_NEWROUTE messages of a netlink route dump
+// in host byte order.  Only unicast routes are returned; local, broadcast,
+// multicast and unreachable routes, as well as cached routes, are skipped.
+// Multipath (ECMP) routes are returned as one Route per nexthop.  ifName maps
+// an interface index to its name.
+func parseNetlinkRoutes(b []byte, ifName func(index int) string) ([]Route, error) {
+	var routes []Route
+	for len(b) > 0 {
+		if len(b) < nlmsgHdrLen {
+			return nil, fmt.Errorf("truncated netlink message header")
+		}
+		msgLen := int(binary.NativeEndian.Uint32(b[0:4]))
+		msgType := binary.NativeEndian.Uint16(b[4:6])
+		if msgLen < nlmsgHdrLen || msgLen > len(b) {
+			return nil, fmt.Errorf("invalid netlink message length %d", msgLen)
+		}
+		data := b[nlmsgHdrLen:msgLen]
+		b = b[min(netlinkAlign(msgLen), len(b)):]
+
+		switch msgType {
+		case nlmsgDone:
+			continue
+		case nlmsgError:
+			return nil, fmt.Errorf("netlink route dump failed")
+		case rtmNewRoute:
+		default:
+			continue
+		}
+
+		msgRoutes, err := parseNetlinkRoute(data, ifName)
+		if err != nil {
+			return nil, err
+		}
+		routes = append(routes, msgRoutes...)
+	}
+
+	return routes, nil
+}
+
+// parseNetlinkRoute parses the body of a single RTM_NEWROUTE message.  Returns
+// one Route per nexthop of a multipath route, and no routes if the route is not
+// a unicast route.
+func parseNetlinkRoute(data []byte, ifName func(index int) string) ([]Route, error) {
+	if len(data) < rtMsgLen {
+		return nil, fmt.Errorf("truncated route message")
+	}
+
+	family, dstLen := data[0], int(data[1])
+	table, protocol, routeType := uint32(data[4]), data[5], data[7]
+	flags := binary.NativeEndian.Uint32(data[8:12])
+	if routeType != rtnUnicast || flags&rtmFCloned != 0 {
+		return nil, nil
+	}
+
+	var addrLen int
+	switch family {
+	case afInet:
+		addrLen = IPv4len
+	case afInet6:
+		addrLen = IPv6len
+	default:
+		return nil, nil
+	}
+	if dstLen > addrLen*8 {
+		return nil, fmt.Errorf("invalid destination prefix length %d", dstLen)
+	}
+
+	route := Route{
+		Destination: netlinkIPAddr(make([]byte, addrLen), dstLen),
+		Protocol:    rtProtocolName(protocol),
+	}
+
+	var nexthops []Route
+	attrs := data[rtMsgLen:]
+	for len(attrs) >= rtaHdrLen {
+		attrLen := int(binary.NativeEndian.Uint16(attrs[0:2]))
+		attrType := binary.NativeEndian.Uint16(attrs[2:4])
+		if attrLen < rtaHdrLen || attrLen > len(attrs) {
+			return nil, fmt.Errorf("invalid route attribute length %d", attrLen)
+		}
+		value := attrs[rtaHdrLen:attrLen]
+		attrs = attrs[min(netlinkAlign(attrLen), len(attrs)):]
+
+		switch attrType {
+		case rtaDst, rtaGateway, rtaPrefSrc:
+			if len(value) != addrLen {
+				return nil, fmt.Errorf("invalid address length %d in route attribute %d", len(value), attrType)
+			}
+			switch attrType {
+			case rtaDst:
+				route.Destination = netlinkIPAddr(value, dstLen)
+			case rtaGateway:
+				route.Gateway = netlinkIPAddr(value, addrLen*8)
+			case rtaPrefSrc:
+				route.Source = netlinkIPAddr(value, addrLen*8)
+			}
+		case rtaOif, rtaPriority, rtaTable:
+			if len(value) != 4 {
+				return nil, fmt.Errorf("invalid length %d in route attribute %d", len(value), attrType)
+			}
+			v := binary.NativeEndian.Uint32(value)
+			switch attrType {
+			case rtaOif:
+				route.IfName = ifName(int(v))
+			case rtaPriority:
+				route.Metric = v
+			case rtaTable:
+				table = v
+			}
+		case rtaMultipath:
+			var err error
+			nexthops, err = parseNetlinkNexthops(value, addrLen, ifName)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	route.Table = rtTableName(table)
+
+	if len(nexthops) == 0 {
+		return []Route{route}, nil
+	}
+
+	routes := make([]Route, 0, len(nexthops))
+	for _, nexthop := range nexthops {
+		r := route
+		r.Gateway, r.IfName = nexthop.Gateway, nexthop.IfName
+		routes = append(routes, r)
+	}
+	return routes, nil
+}
+
+// parseNetlinkNexthops parses the rtnexthop entries of an RTA_MULTIPATH route
+// attribute.  The Gateway and IfName of each returned Route are set.
+func parseNetlinkNexthops(b []byte, addrLen int, ifName func(index int) string) ([]Route, error) {
+	var nexthops []Route
+	for len(b) >= rtNexthopLen {
+		nexthopLen := int(binary.NativeEndian.Uint16(b[0:2]))
+		if nexthopLen < rtNexthopLen || nexthopLen > len(b) {
+			return nil, fmt.Errorf("invalid nexthop length %d", nexthopLen)
+		}
+		index := int(int32(binary.NativeEndian.Uint32(b[4:8])))
+		attrs := b[rtNexthopLen:nexthopLen]
+		b = b[min(netlinkAlign(nexthopLen), len(b)):]
+
+		nexthop := Route{IfName: ifName(index)}
+		err := visitNetlinkAttrs(attrs, func(attrType uint16, value []byte) error {
+			if attrType != rtaGateway {
+				return nil
+			}
+			if len(value) != addrLen {
+				return fmt.Errorf("invalid address length %d in nexthop attribute %d", len(value), attrType)
+			}
+			nexthop.Gateway = netlinkIPAddr(value, addrLen*8)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		nexthops = append(nexthops, nexthop)
+	}
+
+	return nexthops, nil
+}
+
+// netlinkAlign rounds a netlink message or attribute length up to the next
+// multiple of four.
+func netlinkAlign(n int) int {
+	return (n + 3) &^ 3
+}
+
+// netlinkIPAddr returns the IPAddr for an address in network byte order.
+func netlinkIPAddr(b []byte, prefixLen int) IPAddr {
+	if len(b) == IPv4len {
+		return IPv4Addr{
+			Address: IPv4Address(binary.BigEndian.Uint32(b)),
+			Mask:    IPv4Mask(^uint32(0) << uint(32-prefixLen)),
+		}
+	}
+	return newIPv6Prefix(hex.EncodeToString(b), prefixLen)
+}
+
+// ipRouteTypes are the route types printed by ip(8) in front of the
+// destination of routes that are not unicast routes.
+var ipRouteTypes = map[string]bool{
+	"anycast":     true,
+	"blackhole":   true,
+	"broadcast":   true,
+	"local":       true,
+	"multicast":   true,
+	"nat":         true,
+	"prohibit":    true,
+	"throw":       true,
+	"unreachable": true,
+}
+
+// parseIPRouteCmd parses the output of `ip -4 route show table all` or `ip -6
+// route show table all` for Linux.  sockType selects the address family used
+// for default routes.  Only unicast routes are returned.  Routes without a
+// table are in the main table.  Multipath (ECMP) routes are returned as one
+// Route per nexthop.
+func parseIPRouteCmd(routeOut string, sockType SockAddrType) ([]Route, error) {
+	defaultDest := "0.0.0.0/0"
+	if sockType == TypeIPv6 {
+		defaultDest = "::/0"
+	}
+
+	var routes []Route
+
+	// multipath is the route the following "nexthop" lines belong to, or
+	// nil if they belong to a skipped route.  nexthops is true once the
+	// route has been replaced by its nexthops.
+	var multipath *Route
+	var nexthops bool
+	for _, line := range strings.Split(routeOut, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) > 0 && fields[0] == "nexthop" {
+			if multipath == nil {
+				continue
+			}
+			if !nexthops {
+				routes = routes[:len(routes)-1]
+				nexthops = true
+			}
+
+			route := *multipath
+			if err := parseIPRouteFields(fields, &route); err != nil {
+				return nil, err
+			}
+			routes = append(routes, route)
+			continue
+		}
+
+		multipath, nexthops = nil, false
+		if len(fields) > 0 && fields[0] == "unicast" {
+			fields = fields[1:]
+		}
+		if len(fields) == 0 || ipRouteTypes[fields[0]] {
+			continue
+		}
+
+		dest := fields[0]
+		if dest == "default" {
+			dest = defaultDest
+		}
+		destination, err := NewIPAddr(dest)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse route destination %q: %v", fields[0], err)
+		}
+
+		// ip(8) omits the table of routes in the main table and the
+		// protocol of routes installed at boot.
+		route := Route{
+			Destination: destination,
+			Table:       "main",
+			Protocol:    "boot",
+		}
+		if err := parseIPRouteFields(fields, &route); err != nil {
+			return nil, err
+		}
+		routes = append(routes, route)
+		multipath = &route
+	}
+
+	return routes, nil
+}
+
+// parseIPRouteFields sets the attributes of route from the key and value pairs
+// that follow the destination (or "nexthop") in the fields of an ip(8) route.
+func parseIPRouteFields(fields []string, route *Route) error {
+	var err error
+	for i := 1; i+1 < len(fields); i += 2 {
+		value := fields[i+1]
+		switch fields[i] {
+		case "via":
+			if route.Gateway, err = NewIPAddr(value); err != nil {
+				return fmt.Errorf("unable to parse route gateway %q: %v", value, err)
+			}
+		case "src":
+			if route.Source, err = NewIPAddr(value); err != nil {
+				return fmt.Errorf("unable to parse route source %q: %v", value, err)
+			}
+		case "dev":
+			route.IfName = value
+		case "metric":
+			metric, err := strconv.ParseUint(value, 10, 32)
+			if err != nil {
+				return fmt.Errorf("unable to parse route metric %q: %v", value, err)
+			}
+			route.Metric = uint32(metric)
+		case "table":
+			route.Table = value
+		case "proto":
+			route.Protocol = value
+		case "onlink", "linkdown", "dead", "pervasive", "notify":
+			// Flags without a value
+			i--
+		}
+	}
+	return nil
+}
+
+// routesFromIPCmd runs the "routes" and "routes6" ip(8) commands and returns
+// the IPv4 and IPv6 routes they list.
+func (ri routeInfo) routesFromIPCmd() ([]Route, error) {
+	var routes []Route
+	for _, family := range []struct {
+		cmd      string
+		sockType SockAddrType
+	}{
+		{cmd: "routes", sockType: TypeIPv4},
+		{cmd: "routes6", sockType: TypeIPv6},
+	} {
+		out, err := ri.output(ri.cmds[family.cmd])
+		if err != nil {
+			return nil, fmt.Errorf("unable to list routes: %v", err)
+		}
+
+		familyRoutes, err := parseIPRouteCmd(string(out), family.sockType)
+		if err != nil {
+			return nil, err
+		}
+		routes = append(routes, familyRoutes...)
+	}
+	return routes, nil
+}
@@ -1,5 +1,16 @@
 package sockaddr
 
+import (
+	"context"
+	"os/exec"
+	"time"
+)
+
+// commandTimeout bounds every command run to gather route information.  A
+// shorter deadline on the context passed to RouteInfo.WithContext takes
+// precedence.
+const commandTimeout = 5 * time.Second
+
 // RouteInterface specifies an interface for obtaining memoized route table and
 // network information from a given OS.
 type RouteInterface interface {
@@ -7,6 +18,10 @@ type RouteInterface interface {
 	// default route or an error and an empty string if a problem was
 	// encountered.
 	GetDefaultInterfaceName() (string, error)
+
+	// GetRoutes returns the IPv4 and IPv6 unicast routes of the routing
+	// table or an error if the routing table could not be read.
+	GetRoutes() ([]Route, error)
 }
 
 // VisitCommands visits each command used by the platform-specific RouteInfo
@@ -17,3 +32,24 @@ func (ri routeInfo) VisitCommands(fn func(name string, cmd []string)) {
 		fn(k, cmds)
 	}
 }
+
+// WithContext returns a copy of the RouteInfo that runs its commands with ctx.
+// Commands are always bounded by a timeout, even if ctx has no deadline.
+func (ri routeInfo) WithContext(ctx context.Context) routeInfo {
+	ri.ctx = ctx
+	return ri
+}
+
+// output runs cmd and returns its standard output.  The command is killed if
+// it does not complete within commandTimeout or before the RouteInfo's context
+// is done.
+func (ri routeInfo) output(cmd []string) ([]byte, error) {
+	ctx := ri.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+
+	return exec.CommandContext(ctx, cmd[0], cmd[1:]...).Output()
+}
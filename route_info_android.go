@@ -1,26 +1,31 @@
 package sockaddr
 
 import (
+	"context"
 	"errors"
-	"os/exec"
 )
 
 type routeInfo struct {
 	cmds map[string][]string
+	ctx  context.Context
 }
 
 // NewRouteInfo returns a Android-specific implementation of the RouteInfo
 // interface.
 func NewRouteInfo() (routeInfo, error) {
 	return routeInfo{
-		cmds: map[string][]string{"ip": {"/system/bin/ip", "route", "get", "8.8.8.8"}},
+		cmds: map[string][]string{
+			"ip":      {"/system/bin/ip", "route", "get", "8.8.8.8"},
+			"routes":  {"/system/bin/ip", "-4", "route", "show", "table", "all"},
+			"routes6": {"/system/bin/ip", "-6", "route", "show", "table", "all"},
+		},
 	}, nil
 }
 
 // GetDefaultInterfaceName returns the interface name attached to the default
 // route on the default interface.
 func (ri routeInfo) GetDefaultInterfaceName() (string, error) {
-	out, err := exec.Command(ri.cmds["ip"][0], ri.cmds["ip"][1:]...).Output()
+	out, err := ri.output(ri.cmds["ip"])
 	if err != nil {
 		return "", err
 	}
@@ -32,3 +37,9 @@ func (ri routeInfo) GetDefaultInterfaceName() (string, error) {
 	}
 	return ifName, nil
 }
+
+// GetRoutes returns the unicast routes of all routing tables as listed by
+// ip(8).
+func (ri routeInfo) GetRoutes() ([]Route, error) {
+	return ri.routesFromIPCmd()
+}
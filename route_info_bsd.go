@@ -2,7 +2,10 @@
 
 package sockaddr
 
-import "os/exec"
+import (
+	"context"
+	"errors"
+)
 
 var cmds map[string][]string = map[string][]string{
 	"route": {"/sbin/route", "-n", "get", "default"},
@@ -10,6 +13,7 @@ var cmds map[string][]string = map[string][]string{
 
 type routeInfo struct {
 	cmds map[string][]string
+	ctx  context.Context
 }
 
 // NewRouteInfo returns a BSD-specific implementation of the RouteInfo
@@ -23,7 +27,7 @@ func NewRouteInfo() (routeInfo, error) {
 // GetDefaultInterfaceName returns the interface name attached to the default
 // route on the default interface.
 func (ri routeInfo) GetDefaultInterfaceName() (string, error) {
-	out, err := exec.Command(cmds["route"][0], cmds["route"][1:]...).Output()
+	out, err := ri.output(cmds["route"])
 	if err != nil {
 		return "", err
 	}
@@ -34,3 +38,8 @@ func (ri routeInfo) GetDefaultInterfaceName() (string, error) {
 	}
 	return ifName, nil
 }
+
+// GetRoutes is not supported on this platform and always returns an error.
+func (ri routeInfo) GetRoutes() ([]Route, error) {
+	return nil, errors.New("unable to list routes: unsupported platform")
+}
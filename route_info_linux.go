@@ -3,12 +3,18 @@
 package sockaddr
 
 import (
+	"context"
 	"errors"
+	"net"
+	"os"
 	"os/exec"
+	"syscall"
 )
 
 type routeInfo struct {
-	cmds map[string][]string
+	cmds  map[string][]string
+	files map[string]string
+	ctx   context.Context
 }
 
 // NewRouteInfo returns a Linux-specific implementation of the RouteInfo
@@ -22,14 +28,29 @@ func NewRouteInfo() (routeInfo, error) {
 	}
 
 	return routeInfo{
-		cmds: map[string][]string{"ip": {path, "route"}},
+		cmds: map[string][]string{
+			"ip":      {path, "route"},
+			"routes":  {path, "-4", "route", "show", "table", "all"},
+			"routes6": {path, "-6", "route", "show", "table", "all"},
+		},
+		files: map[string]string{
+			"route":      "/proc/net/route",
+			"ipv6_route": "/proc/net/ipv6_route",
+		},
 	}, nil
 }
 
 // GetDefaultInterfaceName returns the interface name attached to the default
-// route on the default interface.
+// route on the default interface.  The routing table is read from /proc and
+// ip(8) is only run if /proc/net/route can not be read (e.g. when /proc is not
+// mounted).
 func (ri routeInfo) GetDefaultInterfaceName() (string, error) {
-	out, err := exec.Command(ri.cmds["ip"][0], ri.cmds["ip"][1:]...).Output()
+	routes, err := ri.kernelRoutes()
+	if err == nil {
+		return defaultIfNameFromKernelRoutes(routes)
+	}
+
+	out, err := ri.output(ri.cmds["ip"])
 	if err != nil {
 		return "", err
 	}
@@ -40,3 +61,61 @@ func (ri routeInfo) GetDefaultInterfaceName() (string, error) {
 	}
 	return ifName, nil
 }
+
+// kernelRoutes reads the IPv4 and IPv6 routing tables from /proc.  The IPv6
+// table is skipped if it is missing, e.g. when IPv6 is disabled.
+func (ri routeInfo) kernelRoutes() ([]kernelRoute, error) {
+	f, err := os.Open(ri.files["route"])
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	routes, err := parseProcNetRoute(f)
+	if err != nil {
+		return nil, err
+	}
+
+	f6, err := os.Open(ri.files["ipv6_route"])
+	if err != nil {
+		return routes, nil
+	}
+	defer f6.Close()
+
+	routes6, err := parseProcNetIPv6Route(f6)
+	if err != nil {
+		return nil, err
+	}
+	return append(routes, routes6...), nil
+}
+
+// GetRoutes returns the unicast routes of all routing tables.  The routes are
+// read over netlink.  If netlink is unavailable the main table is read from
+// /proc, and if /proc can not be read either ip(8) is run.
+func (ri routeInfo) GetRoutes() ([]Route, error) {
+	if b, err := syscall.NetlinkRIB(syscall.RTM_GETROUTE, syscall.AF_UNSPEC); err == nil {
+		return parseNetlinkRoutes(b, netlinkIfName)
+	}
+
+	if kernelRoutes, err := ri.kernelRoutes(); err == nil {
+		routes := make([]Route, 0, len(kernelRoutes))
+		for _, kernelRoute := range kernelRoutes {
+			if kernelRoute.usable() {
+				routes = append(routes, kernelRoute.route())
+			}
+		}
+		return routes, nil
+	}
+
+	return ri.routesFromIPCmd()
+}
+
+// netlinkIfName returns the name of the interface with the given index, or an
+// empty string if the interface no longer exists.
+func netlinkIfName(index int) string {
+	ifi, err := net.InterfaceByIndex(index)
+	if err != nil {
+		return ""
+	}
+	return ifi.Name
+}
@@ -1,8 +1,8 @@
 package sockaddr
 
 import (
+	"context"
 	"errors"
-	"os/exec"
 )
 
 var cmds map[string][]string = map[string][]string{
@@ -11,6 +11,7 @@ var cmds map[string][]string = map[string][]string{
 
 type routeInfo struct {
 	cmds map[string][]string
+	ctx  context.Context
 }
 
 // NewRouteInfo returns a BSD-specific implementation of the RouteInfo
@@ -24,7 +25,7 @@ func NewRouteInfo() (routeInfo, error) {
 // GetDefaultInterfaceName returns the interface name attached to the default
 // route on the default interface.
 func (ri routeInfo) GetDefaultInterfaceName() (string, error) {
-	out, err := exec.Command(cmds["route"][0], cmds["route"][1:]...).Output()
+	out, err := ri.output(cmds["route"])
 	if err != nil {
 		return "", err
 	}
@@ -35,3 +36,8 @@ func (ri routeInfo) GetDefaultInterfaceName() (string, error) {
 	}
 	return ifName, nil
 }
+
+// GetRoutes is not supported on this platform and always returns an error.
+func (ri routeInfo) GetRoutes() ([]Route, error) {
+	return nil, errors.New("unable to list routes: unsupported platform")
+}
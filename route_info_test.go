@@ -57,6 +57,15 @@ func Test_parseLinuxDefaultIfName(t *testing.T) {
 `,
 			want: "wlan0",
 		},
+		{
+			name: "Several default routes",
+			routeOut: `default via 192.168.1.1 dev wlan0 proto dhcp metric 600
+default via 10.1.2.1 dev eth0 proto dhcp metric 100
+10.1.2.0/24 dev eth0 proto kernel scope link src 10.1.2.5 metric 100
+192.168.1.0/24 dev wlan0 proto kernel scope link src 192.168.1.174 metric 600
+`,
+			want: "eth0",
+		},
 	}
 
 	for _, tc := range testCases {
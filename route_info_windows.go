@@ -1,6 +1,9 @@
 package sockaddr
 
-import "os/exec"
+import (
+	"context"
+	"errors"
+)
 
 var cmds map[string][]string = map[string][]string{
 	"netstat":  {"netstat", "-rn"},
@@ -9,6 +12,7 @@ var cmds map[string][]string = map[string][]string{
 
 type routeInfo struct {
 	cmds map[string][]string
+	ctx  context.Context
 }
 
 // NewRouteInfo returns a BSD-specific implementation of the RouteInfo
@@ -22,12 +26,12 @@ func NewRouteInfo() (routeInfo, error) {
 // GetDefaultInterfaceName returns the interface name attached to the default
 // route on the default interface.
 func (ri routeInfo) GetDefaultInterfaceName() (string, error) {
-	ifNameOut, err := exec.Command(cmds["netstat"][0], cmds["netstat"][1:]...).Output()
+	ifNameOut, err := ri.output(cmds["netstat"])
 	if err != nil {
 		return "", err
 	}
 
-	ipconfigOut, err := exec.Command(cmds["ipconfig"][0], cmds["ipconfig"][1:]...).Output()
+	ipconfigOut, err := ri.output(cmds["ipconfig"])
 	if err != nil {
 		return "", err
 	}
@@ -39,3 +43,8 @@ func (ri routeInfo) GetDefaultInterfaceName() (string, error) {
 
 	return ifName, nil
 }
+
+// GetRoutes is not supported on this platform and always returns an error.
+func (ri routeInfo) GetRoutes() ([]Route, error) {
+	return nil, errors.New("unable to list routes: unsupported platform")
+}
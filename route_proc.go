@@ -0,0 +1,214 @@
+package sockaddr
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Route flags from the Linux kernel's <linux/route.h>.
+const (
+	rtfUp      = 0x0001
+	rtfGateway = 0x0002
+	rtfReject  = 0x0200
+)
+
+// kernelRoute is a route read from the Linux kernel's routing table.
+type kernelRoute struct {
+	ifName  string
+	dest    IPAddr
+	gateway IPAddr
+	metric  uint32
+	flags   uint32
+}
+
+// isDefault returns true if the route is a usable default route.
+func (r kernelRoute) isDefault() bool {
+	return r.dest.Maskbits() == 0 && r.usable() && r.ifName != "lo"
+}
+
+// usable returns true if the route is up and does not reject traffic.
+func (r kernelRoute) usable() bool {
+	return r.flags&rtfUp != 0 && r.flags&rtfReject == 0
+}
+
+// route returns the kernelRoute as a Route.  /proc does not report the source,
+// table or protocol of a route.
+func (r kernelRoute) route() Route {
+	return Route{
+		Destination: r.dest,
+		Gateway:     r.gateway,
+		Metric:      r.metric,
+		IfName:      r.ifName,
+	}
+}
+
+// parseProcNetRoute parses the contents of /proc/net/route.  Addresses and
+// masks are printed by the kernel as hex encoded 32-bit integers in host byte
+// order, the flags in hex, and the metric in decimal.
+func parseProcNetRoute(r io.Reader) ([]kernelRoute, error) {
+	var routes []kernelRoute
+
+	scanner := bufio.NewScanner(r)
+	var columns map[string]int
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		if columns == nil {
+			columns = make(map[string]int, len(fields))
+			for i, name := range fields {
+				columns[name] = i
+			}
+			for _, name := range []string{"Iface", "Destination", "Gateway", "Flags", "Metric", "Mask"} {
+				if _, found := columns[name]; !found {
+					return nil, fmt.Errorf("/proc/net/route is missing the %q column", name)
+				}
+			}
+			continue
+		}
+
+		if len(fields) < len(columns) {
+			return nil, fmt.Errorf("line %d: expected %d fields, got %d", line, len(columns), len(fields))
+		}
+
+		var values [4]uint32
+		for i, name := range []string{"Destination", "Gateway", "Flags", "Mask"} {
+			v, err := strconv.ParseUint(fields[columns[name]], 16, 32)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid %s %q: %v", line, name, fields[columns[name]], err)
+			}
+			values[i] = uint32(v)
+		}
+		dest, gateway, flags, mask := values[0], values[1], values[2], values[3]
+
+		metric, err := strconv.ParseUint(fields[columns["Metric"]], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid Metric %q: %v", line, fields[columns["Metric"]], err)
+		}
+
+		route := kernelRoute{
+			ifName: fields[columns["Iface"]],
+			dest: IPv4Addr{
+				Address: IPv4Address(procNetUint32(dest)),
+				Mask:    IPv4Mask(procNetUint32(mask)),
+			},
+			metric: uint32(metric),
+			flags:  flags,
+		}
+		if flags&rtfGateway != 0 {
+			route.gateway = IPv4Addr{
+				Address: IPv4Address(procNetUint32(gateway)),
+				Mask:    IPv4HostMask,
+			}
+		}
+		routes = append(routes, route)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read /proc/net/route: %v", err)
+	}
+
+	return routes, nil
+}
+
+// parseProcNetIPv6Route parses the contents of /proc/net/ipv6_route.  Each
+// line holds the destination, its prefix length, the source, its prefix
+// length, the next hop, the metric, the reference and use counts, the flags,
+// and the interface name.
+func parseProcNetIPv6Route(r io.Reader) ([]kernelRoute, error) {
+	var routes []kernelRoute
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 10 {
+			return nil, fmt.Errorf("line %d: expected 10 fields, got %d", line, len(fields))
+		}
+
+		prefixLen, err := strconv.ParseUint(fields[1], 16, 8)
+		if err != nil || prefixLen > 128 || !isProcNetIPv6(fields[0]) {
+			return nil, fmt.Errorf("line %d: invalid destination %s/%s", line, fields[0], fields[1])
+		}
+		if !isProcNetIPv6(fields[4]) {
+			return nil, fmt.Errorf("line %d: invalid next hop %q", line, fields[4])
+		}
+
+		metric, err := strconv.ParseUint(fields[5], 16, 32)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid metric %q: %v", line, fields[5], err)
+		}
+		flags, err := strconv.ParseUint(fields[8], 16, 32)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid flags %q: %v", line, fields[8], err)
+		}
+
+		route := kernelRoute{
+			ifName: fields[9],
+			dest:   newIPv6Prefix(fields[0], int(prefixLen)),
+			metric: uint32(metric),
+			flags:  uint32(flags),
+		}
+		if flags&rtfGateway != 0 {
+			route.gateway = newIPv6Prefix(fields[4], IPv6len*8)
+		}
+		routes = append(routes, route)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read /proc/net/ipv6_route: %v", err)
+	}
+
+	return routes, nil
+}
+
+// defaultIfNameFromKernelRoutes returns the interface of the IPv4 default
+// route with the lowest metric.  IPv6 default routes are only used when there
+// is no IPv4 default route.
+func defaultIfNameFromKernelRoutes(routes []kernelRoute) (string, error) {
+	var best *kernelRoute
+	for i := range routes {
+		route := &routes[i]
+		if !route.isDefault() {
+			continue
+		}
+
+		switch {
+		case best == nil,
+			route.dest.Type() == TypeIPv4 && best.dest.Type() != TypeIPv4,
+			route.dest.Type() == best.dest.Type() && route.metric < best.metric:
+			best = route
+		}
+	}
+
+	if best == nil {
+		return "", errors.New("No default interface found")
+	}
+	return best.ifName, nil
+}
+
+// isProcNetIPv6 returns true if s is a hex encoded IPv6 address.
+func isProcNetIPv6(s string) bool {
+	if len(s) != 2*IPv6len {
+		return false
+	}
+
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
+
+// procNetUint32 converts a 32-bit integer printed in host byte order to its
+// value in network byte order.
+func procNetUint32(v uint32) uint32 {
+	var b [4]byte
+	binary.NativeEndian.PutUint32(b[:], v)
+	return binary.BigEndian.Uint32(b[:])
+}
@@ -0,0 +1,121 @@
+package sockaddr
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func Test_parseProcNetRoute(t *testing.T) {
+	if binary.NativeEndian.Uint16([]byte{1, 0}) != 1 {
+		t.Skip("fixture was captured on a little-endian host")
+	}
+
+	f, err := os.Open(filepath.Join("testdata", "proc_net_route"))
+	if err != nil {
+		t.Fatalf("unable to open fixture: %v", err)
+	}
+	defer f.Close()
+
+	routes, err := parseProcNetRoute(f)
+	if err != nil {
+		t.Fatalf("unable to parse fixture: %v", err)
+	}
+
+	want := []struct {
+		ifName  string
+		dest    string
+		gateway string
+		metric  uint32
+	}{
+		{ifName: "wlan0", dest: "0.0.0.0/0", gateway: "192.168.1.1", metric: 600},
+		{ifName: "eth0", dest: "0.0.0.0/0", gateway: "10.1.2.1", metric: 100},
+		{ifName: "eth0", dest: "10.1.2.0/24", metric: 100},
+		{ifName: "wlan0", dest: "192.168.1.0/24", metric: 600},
+		{ifName: "docker0", dest: "172.17.0.0/16"},
+	}
+	if len(routes) != len(want) {
+		t.Fatalf("want %d routes, got %d", len(want), len(routes))
+	}
+	for i, route := range routes {
+		var gateway string
+		if route.gateway != nil {
+			gateway = route.gateway.String()
+		}
+		if route.ifName != want[i].ifName || route.dest.String() != want[i].dest || gateway != want[i].gateway || route.metric != want[i].metric {
+			t.Errorf("route %d: want %+v, got %s %s %q %d", i, want[i], route.ifName, route.dest, gateway, route.metric)
+		}
+	}
+
+	ifName, err := defaultIfNameFromKernelRoutes(routes)
+	if err != nil || ifName != "eth0" {
+		t.Fatalf("want eth0, got %q: %v", ifName, err)
+	}
+}
+
+func Test_parseProcNetIPv6Route(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "proc_net_ipv6_route"))
+	if err != nil {
+		t.Fatalf("unable to open fixture: %v", err)
+	}
+	defer f.Close()
+
+	routes, err := parseProcNetIPv6Route(f)
+	if err != nil {
+		t.Fatalf("unable to parse fixture: %v", err)
+	}
+	if len(routes) != 8 {
+		t.Fatalf("want 8 routes, got %d", len(routes))
+	}
+
+	if dest := routes[0].dest.String(); dest != "2001:db8:1::/64" {
+		t.Errorf("want 2001:db8:1::/64, got %s", dest)
+	}
+	if gateway := routes[2].gateway; gateway == nil || gateway.String() != "fe80::1" {
+		t.Errorf("want gateway fe80::1, got %v", gateway)
+	}
+	if routes[2].metric != 1024 {
+		t.Errorf("want metric 1024, got %d", routes[2].metric)
+	}
+
+	// The unreachable default route on lo is skipped, and wlan0 has a
+	// lower metric than eth0.
+	ifName, err := defaultIfNameFromKernelRoutes(routes)
+	if err != nil || ifName != "wlan0" {
+		t.Fatalf("want wlan0, got %q: %v", ifName, err)
+	}
+}
+
+func Test_defaultIfNameFromKernelRoutes(t *testing.T) {
+	routes := []kernelRoute{
+		{ifName: "eth1", dest: newIPv6Prefix("00000000000000000000000000000000", 0), metric: 1, flags: rtfUp | rtfGateway},
+		{ifName: "eth0", dest: IPv4Addr{}, metric: 100, flags: rtfUp | rtfGateway},
+	}
+
+	// IPv4 default routes are preferred over IPv6 default routes.
+	if ifName, err := defaultIfNameFromKernelRoutes(routes); err != nil || ifName != "eth0" {
+		t.Fatalf("want eth0, got %q: %v", ifName, err)
+	}
+
+	if _, err := defaultIfNameFromKernelRoutes(routes[:0]); err == nil {
+		t.Fatalf("expected an empty table to fail")
+	}
+}
+
+func Test_parseProcNetRouteErrors(t *testing.T) {
+	inputs := []string{
+		"Iface\tDestination\n",
+		"Iface\tDestination\tGateway\tFlags\tRefCnt\tUse\tMetric\tMask\tMTU\tWindow\tIRTT\neth0\tzzzzzzzz\t00000000\t0001\t0\t0\t0\t00FFFFFF\t0\t0\t0\n",
+	}
+	for _, input := range inputs {
+		if _, err := parseProcNetRoute(strings.NewReader(input)); err == nil {
+			t.Errorf("expected %q to fail", input)
+		}
+	}
+
+	if _, err := parseProcNetIPv6Route(strings.NewReader("20010db8 40 eth0\n")); err == nil {
+		t.Errorf("expected a truncated line to fail")
+	}
+}
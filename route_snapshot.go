@@ -0,0 +1,46 @@
+package sockaddr
+
+import (
+	"context"
+)
+
+// routeSnapshot is a copy of the route information of the host, so that
+// sorting or filtering many IfAddrs reads the routing table once.
+type routeSnapshot struct {
+	routes        []Route
+	routesErr     error
+	defaultIfName string
+	defaultIfErr  error
+}
+
+// takeRouteSnapshot reads the routes and the default interface of the host
+// through provider.
+func takeRouteSnapshot(ctx context.Context, provider SystemProvider) *routeSnapshot {
+	snapshot := &routeSnapshot{}
+
+	ri, err := provider.RouteInfo(ctx)
+	if err != nil {
+		snapshot.routesErr, snapshot.defaultIfErr = err, err
+		return snapshot
+	}
+
+	snapshot.routes, snapshot.routesErr = ri.GetRoutes()
+	snapshot.defaultIfName, snapshot.defaultIfErr = ri.GetDefaultInterfaceName()
+	return snapshot
+}
+
+// defaultIfNames returns the names of the interfaces with a default route
+// ordered by preference (see AscIfDefault).  Platforms that can not list their
+// routes only report the interface of the default route.
+func (s *routeSnapshot) defaultIfNames() ([]string, error) {
+	if s.routesErr == nil {
+		if ifNames := defaultIfNames(s.routes); len(ifNames) > 0 {
+			return ifNames, nil
+		}
+	}
+
+	if s.defaultIfErr != nil {
+		return nil, s.defaultIfErr
+	}
+	return []string{s.defaultIfName}, nil
+}
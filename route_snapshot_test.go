@@ -0,0 +1,139 @@
+package sockaddr
+
+import (
+	"context"
+	"net"
+	"os/exec"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingSystem is a FakeSystem that counts how often its routing table is
+// read.  Reading it takes delay.
+type countingSystem struct {
+	*FakeSystem
+	delay time.Duration
+	reads int32
+}
+
+func (s *countingSystem) RouteInfo(ctx context.Context) (RouteInterface, error) {
+	atomic.AddInt32(&s.reads, 1)
+	time.Sleep(s.delay)
+	return s.FakeSystem.RouteInfo(ctx)
+}
+
+func newCountingSystem(delay time.Duration) *countingSystem {
+	fake := NewFakeSystem().
+		AddInterface(net.Interface{Index: 1, Name: "eth0", Flags: net.FlagUp}, MustIPv4Addr("10.0.0.5/24")).
+		AddInterface(net.Interface{Index: 2, Name: "eth1", Flags: net.FlagUp}, MustIPv4Addr("192.168.1.5/24")).
+		AddRoute(Route{Destination: MustIPv4Addr("0.0.0.0/0"), Gateway: MustIPv4Addr("192.168.1.1"), IfName: "eth1"})
+	return &countingSystem{FakeSystem: fake, delay: delay}
+}
+
+func TestPackageRoutesFresh(t *testing.T) {
+	provider := newCountingSystem(0)
+	SetSystemProvider(provider)
+	defer SetSystemProvider(nil)
+
+	for i := 0; i < 2; i++ {
+		ifAddrs, err := GetDefaultInterfaces()
+		if err != nil {
+			t.Fatalf("unable to get the default interfaces: %v", err)
+		}
+		if len(ifAddrs) != 1 || ifAddrs[0].Name != "eth1" {
+			t.Fatalf("want eth1 as the default interface, got %v", ifAddrs)
+		}
+	}
+	if reads := atomic.LoadInt32(&provider.reads); reads != 2 {
+		t.Errorf("want the routing table read on every call, got %d reads", reads)
+	}
+
+	provider.FakeSystem.routes[0].IfName = "eth0"
+	ifAddrs, err := GetDefaultInterfaces()
+	if err != nil {
+		t.Fatalf("unable to get the default interfaces: %v", err)
+	}
+	if len(ifAddrs) != 1 || ifAddrs[0].Name != "eth0" {
+		t.Errorf("want the changed default route seen right away, got %v", ifAddrs)
+	}
+}
+
+func TestSortRouteSnapshot(t *testing.T) {
+	provider := newCountingSystem(0)
+	SetSystemProvider(provider)
+	defer SetSystemProvider(nil)
+
+	ifAddrs, err := GetAllInterfaces()
+	if err != nil {
+		t.Fatalf("unable to get interfaces: %v", err)
+	}
+
+	OrderedIfAddrBy(AscIfDefault).Sort(ifAddrs)
+	if ifAddrs[0].Name != "eth1" {
+		t.Errorf("want eth1 sorted first, got %s", ifAddrs[0].Name)
+	}
+	if reads := atomic.LoadInt32(&provider.reads); reads != 1 {
+		t.Errorf("want the routing table read once per sort, got %d reads", reads)
+	}
+	for _, ifAddr := range ifAddrs {
+		if ifAddr.system != nil {
+			t.Errorf("%s is still bound to the System of the sort", ifAddr.Name)
+		}
+	}
+}
+
+func TestRouteInfoWithContext(t *testing.T) {
+	if _, err := exec.LookPath("sleep"); err != nil {
+		t.Skip("sleep(1) is not available")
+	}
+
+	ri, err := NewRouteInfo()
+	if err != nil {
+		t.Fatalf("bad: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := ri.WithContext(ctx).output([]string{"sleep", "10"}); err == nil {
+		t.Fatalf("expected the command to be killed")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("command ran for %v after its context expired", elapsed)
+	}
+}
+
+func TestSystemRouteSnapshot(t *testing.T) {
+	provider := newCountingSystem(0)
+	system := NewSystem(context.Background(), provider)
+
+	ifAddrs, err := system.GetAllInterfaces()
+	if err != nil {
+		t.Fatalf("unable to get interfaces: %v", err)
+	}
+	if reads := atomic.LoadInt32(&provider.reads); reads != 0 {
+		t.Fatalf("want the routing table read on first use, got %d reads", reads)
+	}
+
+	OrderedIfAddrBy(AscIfDefault).Sort(ifAddrs)
+	if ifAddrs[0].Name != "eth1" {
+		t.Errorf("want eth1 sorted first, got %s", ifAddrs[0].Name)
+	}
+	if gateway, err := IfAttr("gateway", ifAddrs[0]); err != nil || gateway != "192.168.1.1" {
+		t.Errorf("want gateway 192.168.1.1, got %q (%v)", gateway, err)
+	}
+	if _, err := system.GetDefaultInterfaces(); err != nil {
+		t.Fatalf("unable to get the default interfaces: %v", err)
+	}
+	if reads := atomic.LoadInt32(&provider.reads); reads != 1 {
+		t.Errorf("want the routing table read once, got %d reads", reads)
+	}
+
+	// The System does not replace the SystemProvider of the package-level
+	// functions.
+	if _, ok := CurrentSystemProvider().(hostSystem); !ok {
+		t.Errorf("unexpected SystemProvider %T", CurrentSystemProvider())
+	}
+}
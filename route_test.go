@@ -0,0 +1,235 @@
+package sockaddr
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func Test_parseNetlinkRoutes(t *testing.T) {
+	if binary.NativeEndian.Uint16([]byte{1, 0}) != 1 {
+		t.Skip("fixture was captured on a little-endian host")
+	}
+
+	b, err := os.ReadFile(filepath.Join("testdata", "netlink_routes"))
+	if err != nil {
+		t.Fatalf("unable to read fixture: %v", err)
+	}
+
+	routes, err := parseNetlinkRoutes(b, func(index int) string {
+		return map[int]string{1: "lo", 4: "eth0"}[index]
+	})
+	if err != nil {
+		t.Fatalf("unable to parse fixture: %v", err)
+	}
+
+	// Local, broadcast and multicast routes are skipped.
+	want := []string{
+		"0.0.0.0/0 via 192.0.2.1 src  dev eth0 metric 0 table main proto boot",
+		"192.0.2.0/24 via  src 192.0.2.2 dev eth0 metric 0 table main proto kernel",
+		"fd00::/64 via  src  dev eth0 metric 256 table main proto kernel",
+		"fe80::/64 via  src  dev eth0 metric 256 table main proto kernel",
+		"::/0 via fd00::1 src  dev eth0 metric 1024 table main proto boot",
+	}
+	checkRoutes(t, routes, want)
+
+	if _, err := parseNetlinkRoutes(b[:len(b)-1], func(int) string { return "" }); err == nil {
+		t.Errorf("expected a truncated dump to fail")
+	}
+}
+
+func Test_parseNetlinkRoutesMultipath(t *testing.T) {
+	if binary.NativeEndian.Uint16([]byte{1, 0}) != 1 {
+		t.Skip("fixture was captured on a little-endian host")
+	}
+
+	b, err := os.ReadFile(filepath.Join("testdata", "netlink_routes_multipath"))
+	if err != nil {
+		t.Fatalf("unable to read fixture: %v", err)
+	}
+
+	routes, err := parseNetlinkRoutes(b, func(index int) string {
+		return map[int]string{1: "lo", 4: "eth0", 12: "vecmp0"}[index]
+	})
+	if err != nil {
+		t.Fatalf("unable to parse fixture: %v", err)
+	}
+
+	// The ECMP route to 198.51.100.0/24 is returned once per nexthop.
+	want := []string{
+		"0.0.0.0/0 via 192.0.2.1 src  dev eth0 metric 0 table main proto boot",
+		"192.0.2.0/24 via  src 192.0.2.2 dev eth0 metric 0 table main proto kernel",
+		"198.51.100.0/24 via 192.0.2.1 src  dev eth0 metric 50 table main proto static",
+		"198.51.100.0/24 via 203.0.113.1 src  dev vecmp0 metric 50 table main proto static",
+		"203.0.113.0/24 via  src 203.0.113.2 dev vecmp0 metric 0 table main proto kernel",
+	}
+	checkRoutes(t, routes, want)
+
+}
+
+func Test_parseIPRouteCmd(t *testing.T) {
+	tests := []struct {
+		name     string
+		sockType SockAddrType
+		out      string
+		want     []string
+	}{
+		{
+			name:     "ipv4",
+			sockType: TypeIPv4,
+			out: `default via 10.1.2.1 dev eth0 proto dhcp src 10.1.2.5 metric 100
+10.1.2.0/24 dev eth0 proto kernel scope link src 10.1.2.5 metric 100
+198.51.100.0/24 via 10.1.2.254 dev eth0 table 100 onlink
+local 127.0.0.1 dev lo table local proto kernel scope host src 127.0.0.1
+broadcast 10.1.2.255 dev eth0 table local proto kernel scope link src 10.1.2.5
+`,
+			want: []string{
+				"0.0.0.0/0 via 10.1.2.1 src 10.1.2.5 dev eth0 metric 100 table main proto dhcp",
+				"10.1.2.0/24 via  src 10.1.2.5 dev eth0 metric 100 table main proto kernel",
+				"198.51.100.0/24 via 10.1.2.254 src  dev eth0 metric 0 table 100 proto boot",
+			},
+		},
+		{
+			name:     "ipv6",
+			sockType: TypeIPv6,
+			out: `2001:db8:1::/64 dev eth0 proto ra metric 100 pref medium
+default via fe80::1 dev eth0 proto ra metric 1024 expires 1795sec pref medium
+local ::1 dev lo table local proto kernel metric 0 pref medium
+multicast ff00::/8 dev eth0 table local proto kernel metric 256 pref medium
+`,
+			want: []string{
+				"2001:db8:1::/64 via  src  dev eth0 metric 100 table main proto ra",
+				"::/0 via fe80::1 src  dev eth0 metric 1024 table main proto ra",
+			},
+		},
+		{
+			name:     "multipath",
+			sockType: TypeIPv4,
+			out: `default proto static metric 50
+	nexthop via 192.0.2.1 dev eth0 weight 1
+	nexthop via 203.0.113.1 dev eth1 weight 2
+blackhole 198.51.100.0/24 proto static
+	nexthop via 192.0.2.1 dev eth0 weight 1
+192.0.2.0/24 dev eth0 proto kernel scope link src 192.0.2.2
+`,
+			want: []string{
+				"0.0.0.0/0 via 192.0.2.1 src  dev eth0 metric 50 table main proto static",
+				"0.0.0.0/0 via 203.0.113.1 src  dev eth1 metric 50 table main proto static",
+				"192.0.2.0/24 via  src 192.0.2.2 dev eth0 metric 0 table main proto kernel",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			routes, err := parseIPRouteCmd(test.out, test.sockType)
+			if err != nil {
+				t.Fatalf("unable to parse routes: %v", err)
+			}
+			checkRoutes(t, routes, test.want)
+		})
+	}
+
+	if _, err := parseIPRouteCmd("default via nowhere dev eth0\n", TypeIPv4); err == nil {
+		t.Errorf("expected an invalid gateway to fail")
+	}
+}
+
+func Test_defaultGateway(t *testing.T) {
+	routes, err := parseIPRouteCmd(`default via 10.1.2.1 dev eth0 metric 100
+default via 10.1.2.254 dev eth0 metric 50
+default via 192.168.1.1 dev wlan0 metric 600
+default via 10.99.0.1 dev eth0 table 100 metric 0
+default via 10.8.0.1 dev tun0 table 100
+10.1.2.0/24 dev eth0 proto kernel scope link src 10.1.2.5
+`, TypeIPv4)
+	if err != nil {
+		t.Fatalf("unable to parse routes: %v", err)
+	}
+
+	tests := []struct {
+		ifName   string
+		sockType SockAddrType
+		want     string
+	}{
+		{ifName: "eth0", sockType: TypeIPv4, want: "10.1.2.254"},
+		{ifName: "wlan0", sockType: TypeIPv4, want: "192.168.1.1"},
+		{ifName: "eth0", sockType: TypeIPv6},
+		{ifName: "docker0", sockType: TypeIPv4},
+		{ifName: "tun0", sockType: TypeIPv4},
+	}
+	for _, test := range tests {
+		var got string
+		if gateway := defaultGateway(routes, test.ifName, test.sockType); gateway != nil {
+			got = gateway.String()
+		}
+		if got != test.want {
+			t.Errorf("%s %v: want gateway %q, got %q", test.ifName, test.sockType, test.want, got)
+		}
+	}
+}
+
+func Test_defaultRoutes(t *testing.T) {
+	routes, err := parseIPRouteCmd(`default via 192.168.1.1 dev wlan0 metric 600
+default via 10.1.2.1 dev eth0 metric 100
+default via 172.16.0.1 dev lo
+default dev wg0 table 51820
+10.1.2.0/24 dev eth0 proto kernel scope link src 10.1.2.5
+`, TypeIPv4)
+	if err != nil {
+		t.Fatalf("unable to parse routes: %v", err)
+	}
+	routes6, err := parseIPRouteCmd(`default via fe80::1 dev wwan0 proto ra metric 50
+default via fe80::2 dev eth0 proto ra metric 1024
+default dev wg0 table 51820 metric 0
+`, TypeIPv6)
+	if err != nil {
+		t.Fatalf("unable to parse routes: %v", err)
+	}
+	routes = append(routes, routes6...)
+
+	checkRoutes(t, defaultRoutes(routes, TypeIPv4), []string{
+		"0.0.0.0/0 via 10.1.2.1 src  dev eth0 metric 100 table main proto boot",
+		"0.0.0.0/0 via 192.168.1.1 src  dev wlan0 metric 600 table main proto boot",
+	})
+	checkRoutes(t, defaultRoutes(routes, TypeIPv6), []string{
+		"::/0 via fe80::1 src  dev wwan0 metric 50 table main proto ra",
+		"::/0 via fe80::2 src  dev eth0 metric 1024 table main proto ra",
+	})
+
+	// Interfaces with an IPv4 default route come first, and the default
+	// routes of policy routing tables are ignored.
+	got := strings.Join(defaultIfNames(routes), " ")
+	if want := "eth0 wlan0 wwan0"; got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
+
+// checkRoutes compares routes with their expected string representation.
+func checkRoutes(t *testing.T, routes []Route, want []string) {
+	t.Helper()
+
+	if len(routes) != len(want) {
+		t.Fatalf("want %d routes, got %d: %+v", len(want), len(routes), routes)
+	}
+	for i, route := range routes {
+		if got := routeString(route); got != want[i] {
+			t.Errorf("route %d: want %q, got %q", i, want[i], got)
+		}
+	}
+}
+
+// routeString formats a route for comparison in tests.
+func routeString(r Route) string {
+	addr := func(ip IPAddr) string {
+		if ip == nil {
+			return ""
+		}
+		return ip.String()
+	}
+	return fmt.Sprintf("%s via %s src %s dev %s metric %d table %s proto %s",
+		addr(r.Destination), addr(r.Gateway), addr(r.Source), r.IfName, r.Metric, r.Table, r.Protocol)
+}
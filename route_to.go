@@ -0,0 +1,171 @@
+package sockaddr
+
+import (
+	"fmt"
+	"net"
+)
+
+// RouteTo returns the interface address used as the source address and the
+// gateway used to reach dest, the equivalent of `ip route get`.  The route is
+// chosen by longest-prefix match over the main routing table (lowest metric
+// first on a tie), and the source address is the route's preferred source or,
+// if the route has none, the address on the route's interface preferred by
+// RFC 6724.  The gateway is nil if dest is on a directly connected network.
+//
+// If the routing table can not be read or has no route to dest, the source
+// address is looked up by connecting a UDP socket to dest, which sends no
+// packets.  The gateway is nil in that case.  The fallback is not used when a
+// SystemProvider has been set.
+func RouteTo(dest SockAddr) (src IfAddr, gateway IPAddr, err error) {
+	return defaultSystem().RouteTo(dest)
+}
+
+// RouteTo is like the package-level RouteTo, but inspects the host of s.
+func (s *System) RouteTo(dest SockAddr) (src IfAddr, gateway IPAddr, err error) {
+	if dest == nil || ToIPAddr(dest) == nil {
+		return IfAddr{}, nil, fmt.Errorf("unable to route to %v: only IP addresses are supported", dest)
+	}
+	destIP := (*ToIPAddr(dest)).Host()
+
+	ifAddrs, err := s.GetAllInterfaces()
+	if err != nil {
+		return IfAddr{}, nil, err
+	}
+
+	if src, found := localSource(destIP, ifAddrs); found {
+		return src, nil, nil
+	}
+
+	if snapshot := s.routeSnapshot(); snapshot.routesErr == nil {
+		if route := lookupRoute(snapshot.routes, destIP); route != nil {
+			if src, err := routeSource(*route, destIP, ifAddrs); err == nil {
+				return src, route.Gateway, nil
+			}
+		}
+	}
+
+	if !s.isHostSystem() {
+		return IfAddr{}, nil, fmt.Errorf("unable to route to %s: no route found", destIP)
+	}
+
+	src, err = udpConnectSource(destIP, ifAddrs)
+	if err != nil {
+		return IfAddr{}, nil, err
+	}
+	return src, nil, nil
+}
+
+// GetInterfaceForDestination returns the IfAddr used as the source address to
+// reach dest (see RouteTo).
+func GetInterfaceForDestination(dest string) (IfAddrs, error) {
+	return defaultSystem().GetInterfaceForDestination(dest)
+}
+
+// GetInterfaceForDestination is like the package-level
+// GetInterfaceForDestination, but inspects the host of s.
+func (s *System) GetInterfaceForDestination(dest string) (IfAddrs, error) {
+	destIP, err := NewIPAddr(dest)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse destination %q: %v", dest, err)
+	}
+
+	src, _, err := s.RouteTo(destIP)
+	if err != nil {
+		return nil, err
+	}
+	return IfAddrs{src}, nil
+}
+
+// localSource returns the IfAddr of a local destination, the way the kernel's
+// local routing table does: an address of this host is reached through its own
+// interface, and addresses within a loopback network through the loopback
+// interface.
+func localSource(dest IPAddr, ifAddrs IfAddrs) (IfAddr, bool) {
+	for _, ifAddr := range ifAddrs {
+		if ifAddr.SockAddr == nil || ifAddr.SockAddr.Type() != dest.Type() {
+			continue
+		}
+
+		ip := *ToIPAddr(ifAddr.SockAddr)
+		if ip.Host().Equal(dest) || (ifAddr.Flags&net.FlagLoopback != 0 && ip.Contains(dest)) {
+			return ifAddr, true
+		}
+	}
+	return IfAddr{}, false
+}
+
+// lookupRoute returns the most specific route in the main routing table that
+// contains dest, or nil if there is no such route.  Routes of equal prefix
+// length are ordered by metric.
+func lookupRoute(routes []Route, dest IPAddr) *Route {
+	var best *Route
+	for i := range routes {
+		route := &routes[i]
+		if !route.inMainTable() || route.IfName == "" || !route.Destination.Contains(dest) {
+			continue
+		}
+
+		switch {
+		case best == nil,
+			route.Destination.Maskbits() > best.Destination.Maskbits(),
+			route.Destination.Maskbits() == best.Destination.Maskbits() && route.Metric < best.Metric:
+			best = route
+		}
+	}
+	return best
+}
+
+// routeSource returns the IfAddr to use as the source address to reach dest
+// through route.  The route's preferred source is used if it is set,
+// otherwise the addresses on the route's interface are ordered following RFC
+// 6724.
+func routeSource(route Route, dest IPAddr, ifAddrs IfAddrs) (IfAddr, error) {
+	var candidates SockAddrs
+	for _, ifAddr := range ifAddrs {
+		if ifAddr.Name != route.IfName || ifAddr.SockAddr == nil || ifAddr.SockAddr.Type() != dest.Type() {
+			continue
+		}
+
+		if route.Source != nil {
+			if ip := ToIPAddr(ifAddr.SockAddr); ip != nil && (*ip).Host().Equal(route.Source) {
+				return ifAddr, nil
+			}
+			continue
+		}
+		candidates = append(candidates, ifAddr.SockAddr)
+	}
+
+	if len(candidates) == 0 {
+		return IfAddr{}, fmt.Errorf("unable to find a source address on %s for %s", route.IfName, dest)
+	}
+
+	best := OrderSourceAddrs(dest, candidates)[0]
+	for _, ifAddr := range ifAddrs {
+		if ifAddr.Name == route.IfName && ifAddr.SockAddr != nil && ifAddr.SockAddr.Equal(best) {
+			return ifAddr, nil
+		}
+	}
+	return IfAddr{}, fmt.Errorf("unable to find a source address on %s for %s", route.IfName, dest)
+}
+
+// udpConnectSource returns the IfAddr holding the local address the kernel
+// selects for a UDP socket connected to dest.  Connecting a UDP socket does
+// not send any packets.
+func udpConnectSource(dest IPAddr, ifAddrs IfAddrs) (IfAddr, error) {
+	conn, err := net.DialUDP("udp", nil, &net.UDPAddr{IP: *dest.NetIP(), Port: 9})
+	if err != nil {
+		return IfAddr{}, fmt.Errorf("unable to route to %s: %v", dest, err)
+	}
+	defer conn.Close()
+
+	local := conn.LocalAddr().(*net.UDPAddr)
+	for _, ifAddr := range ifAddrs {
+		if ifAddr.SockAddr == nil {
+			continue
+		}
+		if ip := ToIPAddr(ifAddr.SockAddr); ip != nil && (*ip).NetIP().Equal(local.IP) {
+			return ifAddr, nil
+		}
+	}
+	return IfAddr{}, fmt.Errorf("unable to find the interface of source address %s for %s", local.IP, dest)
+}
@@ -0,0 +1,137 @@
+package sockaddr
+
+import (
+	"net"
+	"testing"
+)
+
+func Test_lookupRoute(t *testing.T) {
+	routes, err := parseIPRouteCmd(`default via 10.1.2.1 dev eth0 metric 100
+default via 192.168.1.1 dev wlan0 metric 600
+10.1.2.0/24 dev eth0 proto kernel scope link src 10.1.2.5
+10.20.0.0/16 via 192.168.1.254 dev wlan0 metric 600
+10.20.0.0/16 via 10.1.2.254 dev eth0 metric 50
+10.20.5.0/24 via 10.1.2.253 dev eth0 table 100
+`, TypeIPv4)
+	if err != nil {
+		t.Fatalf("unable to parse routes: %v", err)
+	}
+
+	tests := []struct {
+		dest    string
+		ifName  string
+		gateway string
+	}{
+		{dest: "8.8.8.8", ifName: "eth0", gateway: "10.1.2.1"},
+		{dest: "10.1.2.77", ifName: "eth0"},
+		{dest: "10.20.0.1", ifName: "eth0", gateway: "10.1.2.254"},
+		// Tables other than the main table are not consulted.
+		{dest: "10.20.5.1", ifName: "eth0", gateway: "10.1.2.254"},
+	}
+	for _, test := range tests {
+		route := lookupRoute(routes, MustIPv4Addr(test.dest))
+		if route == nil {
+			t.Errorf("%s: expected a route", test.dest)
+			continue
+		}
+
+		var gateway string
+		if route.Gateway != nil {
+			gateway = route.Gateway.String()
+		}
+		if route.IfName != test.ifName || gateway != test.gateway {
+			t.Errorf("%s: want %s via %q, got %s via %q", test.dest, test.ifName, test.gateway, route.IfName, gateway)
+		}
+	}
+
+	if route := lookupRoute(routes, MustIPv6Addr("2001:db8::1")); route != nil {
+		t.Errorf("expected no IPv6 route, got %+v", *route)
+	}
+}
+
+func Test_routeSource(t *testing.T) {
+	eth0 := net.Interface{Index: 2, Name: "eth0", Flags: net.FlagUp}
+	lo := net.Interface{Index: 1, Name: "lo", Flags: net.FlagUp | net.FlagLoopback}
+	ifAddrs := IfAddrs{
+		{SockAddr: MustIPv4Addr("127.0.0.1/8"), Interface: lo},
+		{SockAddr: MustIPv4Addr("10.1.2.5/24"), Interface: eth0},
+		{SockAddr: MustIPv4Addr("10.1.2.6/24"), Interface: eth0},
+		{SockAddr: MustIPv6Addr("fe80::1/64"), Interface: eth0},
+		{SockAddr: MustIPv6Addr("2001:db8::5/64"), Interface: eth0},
+	}
+
+	tests := []struct {
+		name  string
+		route string
+		dest  string
+		want  string
+	}{
+		{
+			name:  "preferred source",
+			route: "default via 10.1.2.1 dev eth0 src 10.1.2.6",
+			dest:  "8.8.8.8",
+			want:  "10.1.2.6/24",
+		},
+		{
+			name:  "first address",
+			route: "default via 10.1.2.1 dev eth0",
+			dest:  "8.8.8.8",
+			want:  "10.1.2.5/24",
+		},
+		{
+			name:  "RFC 6724 scope",
+			route: "default via fe80::ff dev eth0",
+			dest:  "2001:db8:1::1",
+			want:  "2001:db8::5/64",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dest := MustIPAddr(test.dest)
+			routes, err := parseIPRouteCmd(test.route, dest.Type())
+			if err != nil {
+				t.Fatalf("unable to parse route: %v", err)
+			}
+
+			src, err := routeSource(routes[0], dest, ifAddrs)
+			if err != nil {
+				t.Fatalf("unable to select a source: %v", err)
+			}
+			if src.SockAddr.String() != test.want || src.Name != "eth0" {
+				t.Errorf("want %s on eth0, got %s on %s", test.want, src.SockAddr, src.Name)
+			}
+		})
+	}
+
+	routes, _ := parseIPRouteCmd("default via 10.1.2.1 dev eth0 src 10.9.9.9", TypeIPv4)
+	if _, err := routeSource(routes[0], MustIPv4Addr("8.8.8.8"), ifAddrs); err == nil {
+		t.Errorf("expected a missing preferred source to fail")
+	}
+
+	for dest, want := range map[string]string{
+		"127.0.0.9": "127.0.0.1/8",
+		"10.1.2.6":  "10.1.2.6/24",
+	} {
+		src, found := localSource(MustIPAddr(dest), ifAddrs)
+		if !found || src.SockAddr.String() != want {
+			t.Errorf("%s: want local source %s, got %v", dest, want, src.SockAddr)
+		}
+	}
+	if _, found := localSource(MustIPv4Addr("10.1.2.7"), ifAddrs); found {
+		t.Errorf("expected 10.1.2.7 not to be local")
+	}
+}
+
+func TestRouteTo(t *testing.T) {
+	src, gateway, err := RouteTo(MustIPv4Addr("127.0.0.1"))
+	if err != nil {
+		t.Skipf("unable to route to the loopback address: %v", err)
+	}
+	if src.Flags&net.FlagLoopback == 0 || gateway != nil {
+		t.Errorf("expected the loopback interface without a gateway, got %v via %v", src, gateway)
+	}
+
+	if _, _, err := RouteTo(MustUnixSock("/tmp/sock")); err == nil {
+		t.Errorf("expected a unix socket to fail")
+	}
+}
@@ -0,0 +1,79 @@
+package sockaddr
+
+import (
+	"context"
+	"sync"
+)
+
+// System inspects a host through a SystemProvider.  Unlike the package-level
+// functions, which read the routing table whenever they need it, a System
+// reads it at most once: on first use by the default interface functions,
+// AscIfDefault, RouteTo or the "gateway" attribute.  The IfAddrs returned by a System refer back to it, so sorting
+// them and reading their attributes use the same provider and routes.  A
+// System is safe for concurrent use.
+type System struct {
+	ctx      context.Context
+	provider SystemProvider
+
+	// shared is true for the System of the package-level functions, which
+	// reads the current SystemProvider and the current routing table.
+	shared bool
+
+	routesOnce sync.Once
+	routes     *routeSnapshot
+}
+
+// NewSystem returns a System that inspects the host through provider, or
+// through the current SystemProvider if provider is nil.  Commands run to read
+// the routing table are bound to ctx.
+func NewSystem(ctx context.Context, provider SystemProvider) *System {
+	if provider == nil {
+		provider = CurrentSystemProvider()
+	}
+	return &System{ctx: ctx, provider: provider}
+}
+
+// defaultSystem returns the System used by the package-level functions.
+func defaultSystem() *System {
+	return &System{shared: true}
+}
+
+// systemProvider returns the SystemProvider of s.
+func (s *System) systemProvider() SystemProvider {
+	if s == nil || s.shared {
+		return CurrentSystemProvider()
+	}
+	return s.provider
+}
+
+// isHostSystem returns true if s inspects the host the program runs on.
+func (s *System) isHostSystem() bool {
+	_, ok := s.systemProvider().(hostSystem)
+	return ok
+}
+
+// routeSnapshot returns the route information of s, reading the routing table
+// on first use.  The package-level functions read it on every call.
+func (s *System) routeSnapshot() *routeSnapshot {
+	if s == nil || s.shared {
+		return takeRouteSnapshot(context.Background(), CurrentSystemProvider())
+	}
+
+	s.routesOnce.Do(func() {
+		s.routes = takeRouteSnapshot(s.ctx, s.provider)
+	})
+	return s.routes
+}
+
+// bind returns ifAddrs with their System set to s, so that their attributes
+// and sort order use the route information of s.  The IfAddrs of the
+// package-level functions are left unbound.
+func (s *System) bind(ifAddrs IfAddrs) IfAddrs {
+	if s.shared {
+		return ifAddrs
+	}
+	for i := range ifAddrs {
+		ifAddrs[i].system = s
+	}
+	return ifAddrs
+}
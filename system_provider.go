@@ -0,0 +1,71 @@
+package sockaddr
+
+import (
+	"context"
+	"net"
+	"sync"
+)
+
+// SystemProvider supplies the interfaces, addresses and routes of a host.
+// Every function that inspects the host (GetAllInterfaces, the default
+// interface functions, RouteTo, WatchInterfaces, and the template sources built
+// on them) reads it through the current SystemProvider, so a FakeSystem can be
+// used to evaluate templates against a fixed topology.
+type SystemProvider interface {
+	// Interfaces returns the network interfaces of the host.
+	Interfaces() ([]net.Interface, error)
+
+	// Addrs returns the addresses of an interface returned by
+	// Interfaces.
+	Addrs(ifi net.Interface) ([]net.Addr, error)
+
+	// RouteInfo returns the route information of the host.  Commands run
+	// to read the routing table are bound to ctx.
+	RouteInfo(ctx context.Context) (RouteInterface, error)
+}
+
+// hostSystem is the SystemProvider of the host the program runs on.
+type hostSystem struct{}
+
+// Interfaces returns the network interfaces of the host.
+func (hostSystem) Interfaces() ([]net.Interface, error) {
+	return net.Interfaces()
+}
+
+// Addrs returns the addresses of an interface of the host.
+func (hostSystem) Addrs(ifi net.Interface) ([]net.Addr, error) {
+	return ifi.Addrs()
+}
+
+// RouteInfo returns the platform-specific route information of the host.
+func (hostSystem) RouteInfo(ctx context.Context) (RouteInterface, error) {
+	ri, err := NewRouteInfo()
+	if err != nil {
+		return nil, err
+	}
+	return ri.WithContext(ctx), nil
+}
+
+var (
+	systemProviderLock sync.RWMutex
+	systemProvider     SystemProvider = hostSystem{}
+)
+
+// SetSystemProvider replaces the SystemProvider used to inspect the host.  A
+// nil provider restores the host the program runs on.
+func SetSystemProvider(provider SystemProvider) {
+	if provider == nil {
+		provider = hostSystem{}
+	}
+
+	systemProviderLock.Lock()
+	systemProvider = provider
+	systemProviderLock.Unlock()
+}
+
+// CurrentSystemProvider returns the SystemProvider used to inspect the host.
+func CurrentSystemProvider() SystemProvider {
+	systemProviderLock.RLock()
+	defer systemProviderLock.RUnlock()
+	return systemProvider
+}
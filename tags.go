@@ -0,0 +1,188 @@
+package sockaddr
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// NetworkTag labels a network.  Addresses are tagged with the label of the
+// most specific network that contains them.
+type NetworkTag struct {
+	Network IPAddr
+	Label   string
+}
+
+// tagTable holds the network tags used by IfByTag and the "tag" attribute.
+// The table is replaced as a whole when it is (re)loaded.
+type tagTable struct {
+	lock sync.RWMutex
+	path string
+	tags []NetworkTag
+}
+
+var tags tagTable
+
+// LoadTagsFile replaces the network tags with the tags read from path (see
+// LoadTags).  The path is remembered so the tags can be reloaded with
+// ReloadTags.
+func LoadTagsFile(path string) error {
+	newTags, err := readTagsFile(path)
+	if err != nil {
+		return err
+	}
+
+	tags.lock.Lock()
+	defer tags.lock.Unlock()
+	tags.path = path
+	tags.tags = newTags
+	return nil
+}
+
+// ReloadTags re-reads the file most recently loaded with LoadTagsFile.  The
+// existing tags are kept if the file can not be read.
+func ReloadTags() error {
+	tags.lock.RLock()
+	path := tags.path
+	tags.lock.RUnlock()
+
+	if path == "" {
+		return fmt.Errorf("unable to reload tags: no tags file has been loaded")
+	}
+
+	return LoadTagsFile(path)
+}
+
+// LoadTags replaces the network tags with the tags read from r.  Each line
+// holds a network in CIDR notation followed by its label, separated by
+// whitespace.  Blank lines and text following a '#' are ignored.  For
+// example:
+//
+//	# site networks
+//	10.1.0.0/16     dc1
+//	10.1.200.0/24   storage
+//	2001:db8:1::/48 dc1
+func LoadTags(r io.Reader) error {
+	newTags, err := parseTags(r)
+	if err != nil {
+		return err
+	}
+
+	SetTags(newTags...)
+	return nil
+}
+
+// SetTags replaces the network tags.  Calling SetTags without any tags clears
+// the table.
+func SetTags(newTags ...NetworkTag) {
+	newTags = append([]NetworkTag(nil), newTags...)
+
+	tags.lock.Lock()
+	defer tags.lock.Unlock()
+	tags.path = ""
+	tags.tags = newTags
+}
+
+// Tags returns a copy of the network tags.
+func Tags() []NetworkTag {
+	tags.lock.RLock()
+	defer tags.lock.RUnlock()
+	return append([]NetworkTag(nil), tags.tags...)
+}
+
+// TagOf returns the label of the most specific tagged network that contains
+// the address of sa.  The mask of sa is ignored, so an interface address of
+// 10.1.200.5/16 is tagged by a 10.1.200.0/24 network.  Returns false if sa is
+// not within a tagged network.
+func TagOf(sa SockAddr) (string, bool) {
+	if sa == nil {
+		return "", false
+	}
+
+	ip := ToIPAddr(sa)
+	if ip == nil {
+		return "", false
+	}
+	host := (*ip).Host()
+
+	tags.lock.RLock()
+	defer tags.lock.RUnlock()
+
+	var best *NetworkTag
+	for i := range tags.tags {
+		tag := &tags.tags[i]
+		if !tag.Network.Contains(host) {
+			continue
+		}
+
+		if best == nil || tag.Network.Maskbits() > best.Network.Maskbits() {
+			best = tag
+		}
+	}
+
+	if best == nil {
+		return "", false
+	}
+	return best.Label, true
+}
+
+// readTagsFile reads and parses a tags file.
+func readTagsFile(path string) ([]NetworkTag, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open tags file: %v", err)
+	}
+	defer f.Close()
+
+	return parseTags(f)
+}
+
+// parseTags parses the tag format described in LoadTags.  A network listed
+// more than once takes the last label.
+func parseTags(r io.Reader) ([]NetworkTag, error) {
+	var newTags []NetworkTag
+	byNetwork := make(map[string]int)
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if i := strings.IndexByte(text, '#'); i >= 0 {
+			text = text[:i]
+		}
+
+		fields := strings.Fields(text)
+		switch len(fields) {
+		case 0:
+			continue
+		case 2:
+		default:
+			return nil, fmt.Errorf("line %d: expected a network and a label, got %q", line, strings.TrimSpace(text))
+		}
+
+		network, err := NewIPAddr(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid network %q: %v", line, fields[0], err)
+		}
+
+		tag := NetworkTag{
+			Network: network,
+			Label:   fields[1],
+		}
+
+		key := network.String()
+		if i, found := byNetwork[key]; found {
+			newTags[i] = tag
+			continue
+		}
+		byNetwork[key] = len(newTags)
+		newTags = append(newTags, tag)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read tags: %v", err)
+	}
+
+	return newTags, nil
+}
@@ -0,0 +1,129 @@
+package sockaddr_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	sockaddr "github.com/hashicorp/go-sockaddr"
+)
+
+const testTags = `# site networks
+10.1.0.0/16      dc1
+10.1.200.0/24    storage   # SAN
+10.9.0.0/16      mgmt
+2001:db8:1::/48  dc1
+10.9.0.0/16      oob
+`
+
+func TestTagOf(t *testing.T) {
+	defer sockaddr.SetTags()
+
+	if err := sockaddr.LoadTags(strings.NewReader(testTags)); err != nil {
+		t.Fatalf("unable to load tags: %v", err)
+	}
+	if n := len(sockaddr.Tags()); n != 4 {
+		t.Fatalf("want 4 tags, got %d", n)
+	}
+
+	tests := []struct {
+		addr  string
+		label string
+	}{
+		{addr: "10.1.2.3", label: "dc1"},
+		{addr: "10.1.200.5/16", label: "storage"},
+		{addr: "10.9.1.1", label: "oob"},
+		{addr: "2001:db8:1::1/64", label: "dc1"},
+		{addr: "192.168.1.1", label: ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.addr, func(t *testing.T) {
+			ip := sockaddr.MustIPAddr(test.addr)
+			label, found := sockaddr.TagOf(ip)
+			if label != test.label || found != (test.label != "") {
+				t.Fatalf("want %q, got %q", test.label, label)
+			}
+			if attr, _ := sockaddr.Attr(ip, "tag"); attr != test.label {
+				t.Fatalf("want tag attribute %q, got %q", test.label, attr)
+			}
+		})
+	}
+
+	ifAddrs := sockaddr.IfAddrs{
+		{SockAddr: sockaddr.MustIPAddr("10.1.2.3/16")},
+		{SockAddr: sockaddr.MustIPAddr("10.1.200.5/24")},
+		{SockAddr: sockaddr.MustIPAddr("192.168.1.1/24")},
+	}
+	included, err := sockaddr.IncludeIfs("tag", "storage|mgmt", ifAddrs)
+	if err != nil {
+		t.Fatalf("unable to include: %v", err)
+	}
+	if len(included) != 1 || included[0].SockAddr.String() != "10.1.200.5/24" {
+		t.Fatalf("unexpected result: %v", included)
+	}
+	excluded, err := sockaddr.ExcludeIfs("tag", "dc1", ifAddrs)
+	if err != nil {
+		t.Fatalf("unable to exclude: %v", err)
+	}
+	if len(excluded) != 2 {
+		t.Fatalf("unexpected result: %v", excluded)
+	}
+
+	for _, input := range []string{"10.0.0.0/8\n", "10.0.0.0/8 a b\n", "bogus dc1\n"} {
+		if err := sockaddr.LoadTags(strings.NewReader(input)); err == nil {
+			t.Fatalf("expected %q to fail", input)
+		}
+	}
+}
+
+func TestReloadTags(t *testing.T) {
+	defer sockaddr.SetTags()
+
+	dir, err := ioutil.TempDir("", "sockaddr")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "tags")
+	if err := ioutil.WriteFile(path, []byte("10.0.0.0/8 dc1\n"), 0644); err != nil {
+		t.Fatalf("unable to write tags: %v", err)
+	}
+	if err := sockaddr.LoadTagsFile(path); err != nil {
+		t.Fatalf("unable to load tags: %v", err)
+	}
+
+	ip := sockaddr.MustIPAddr("10.1.1.1")
+	if label, _ := sockaddr.TagOf(ip); label != "dc1" {
+		t.Fatalf("want dc1, got %q", label)
+	}
+
+	if err := ioutil.WriteFile(path, []byte("10.0.0.0/8 dc2\n"), 0644); err != nil {
+		t.Fatalf("unable to write tags: %v", err)
+	}
+	if err := sockaddr.ReloadTags(); err != nil {
+		t.Fatalf("unable to reload tags: %v", err)
+	}
+	if label, _ := sockaddr.TagOf(ip); label != "dc2" {
+		t.Fatalf("want dc2, got %q", label)
+	}
+
+	// A broken file leaves the existing tags in place.
+	if err := ioutil.WriteFile(path, []byte("bogus\n"), 0644); err != nil {
+		t.Fatalf("unable to write tags: %v", err)
+	}
+	if err := sockaddr.ReloadTags(); err == nil {
+		t.Fatalf("expected reload of a broken file to fail")
+	}
+	if label, _ := sockaddr.TagOf(ip); label != "dc2" {
+		t.Fatalf("want dc2, got %q", label)
+	}
+
+	sockaddr.SetTags()
+	if err := sockaddr.ReloadTags(); err == nil {
+		t.Fatalf("expected reload without a file to fail")
+	}
+}
@@ -32,6 +32,65 @@ it possible to `sort`, `filter`, `limit`, seek (via the `offset` function), or
 `unique` the list.  To extract useful string information, the `attr` and `join`
 functions return a single string value.  See below for details.
 
+The routing table is only read if a template needs it, i.e. for the default
+interface functions, `GetInterfaceForDestination`, `sort "default"` and the
+`gateway` attribute, and at most once per evaluation.  On platforms where it is
+read by running route(8) or netstat(1), `ParseContext` bounds those commands
+with a context:
+
+    ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+    defer cancel()
+    out, err := template.ParseContext(ctx, `{{GetDefaultInterfaces | attr "address"}}`)
+
+The interfaces, addresses and routes are read through
+`sockaddr.CurrentSystemProvider()`.  To test templates without depending on the
+interfaces of the host, evaluate them with `ParseWithSystem` against a
+`sockaddr.FakeSystem` built in code or loaded from a JSON fixture:
+
+    fake, err := sockaddr.LoadFakeSystemFile("testdata/topology.json")
+    if err != nil {
+      t.Fatal(err)
+    }
+    out, err := template.ParseWithSystem(`{{GetPrivateIP}}`, sockaddr.NewSystem(context.Background(), fake))
+
+Unlike `sockaddr.SetSystemProvider()`, which replaces the provider of the whole
+process, this only affects the one evaluation.
+
+A snapshot of a live host written by `sockaddr snapshot` (or
+`sockaddr.WriteSnapshot()`) uses the same format, and `ParseWithSnapshot`
+evaluates a template against one:
+
+    out, err := template.ParseWithSnapshot(`{{GetPrivateIP}}`, "host.json")
+
+The equivalent on the command line is `sockaddr eval -snapshot host.json`.
+
+By default `GetAllInterfaces`, and the sources built on it such as
+`GetPrivateIP`, fail if the addresses of any interface can not be read, e.g.
+because of a broken tunnel interface.  `GetAllInterfacesPartial` skips the
+failing interfaces and addresses instead:
+
+    {{ GetAllInterfacesPartial | include "flags" "forwardable|up" | include "RFC" "6890" | attr "address" }}
+
+The initial "dot" is read the same way as `GetAllInterfacesPartial`, so an
+interface that can not be read does not fail every template: the dot holds
+the remaining addresses, and only templates that call `GetAllInterfaces` or a
+source built on it fail.  Use `GetAllInterfaces` instead of the dot where a
+missing interface must be an error:
+
+    {{ GetAllInterfaces | include "name" "^tun0$" | attr "address" }}
+
+In Go, `sockaddr.GetAllInterfacesPartial()` also reports which interfaces and
+addresses were skipped, and its result can be evaluated with `ParseIfAddrs`:
+
+    ifAddrs, err := sockaddr.GetAllInterfacesPartial()
+    var ifErrs sockaddr.InterfaceErrors
+    if errors.As(err, &ifErrs) {
+      log.Printf("[WARN] skipping interfaces: %v", err)
+    } else if err != nil {
+      return err
+    }
+    out, err := template.ParseIfAddrs(`{{. | include "RFC" "6890" | attr "address"}}`, ifAddrs)
+
 Important note: see the
 https://github.com/hashicorp/go-sockaddr/tree/master/cmd/sockaddr utility for
 more examples and for a CLI utility to experiment with the template syntax.
@@ -44,6 +103,14 @@ Example:
 
     {{ GetAllInterfaces }}
 
+`GetAllInterfacesPartial` - Returns the IfAddr structs of `GetAllInterfaces`,
+skipping the interfaces whose addresses can not be read and the addresses that
+can not be converted instead of failing.
+
+Example:
+
+    {{ GetAllInterfacesPartial | include "type" "IPv4" }}
+
 
 `GetDefaultInterfaces` - Returns one IfAddr for every IP that is on the
 interface containing the default route for the host.
@@ -52,6 +119,54 @@ Example:
 
     {{ GetDefaultInterfaces }}
 
+`GetDefaultInterfacesV4` - Returns one IfAddr for every IPv4 address on the
+interface of the IPv4 default route with the lowest metric.
+
+Example:
+
+    {{ GetDefaultInterfacesV4 | attr "address" }}
+
+`GetDefaultInterfacesV6` - Returns one IfAddr for every IPv6 address on the
+interface of the IPv6 default route with the lowest metric.  Useful on hosts
+with an IPv6-only uplink.
+
+Example:
+
+    {{ GetDefaultInterfacesV6 | exclude "class" "link-local" | attr "address" }}
+
+`GetInterfaceForDestination` - Returns the IfAddr used as the source address
+to reach a destination, the equivalent of `ip route get`.  The most specific
+route to the destination is used, and the source address is the preferred
+source of the route or the address of the route's interface selected by RFC
+6724.  On multi-homed hosts this is the address peers on that network reach
+this host on, which is not necessarily on the default interface.
+
+Example:
+
+    {{ GetInterfaceForDestination "10.20.0.1" | attr "address" }}
+
+`GetNameservers` - Returns one IfAddr for every nameserver of the host: first
+the nameservers of /etc/resolv.conf, which have no interface, followed by the
+upstream nameservers systemd-resolved uses on each link, with the interface of
+the link.  Loopback stub resolvers such as systemd-resolved's 127.0.0.53 can
+be dropped with `exclude "rfc" "1122"`.  The search domains and options of
+/etc/resolv.conf are returned by `sockaddr.GetResolverConfig()`.
+
+Example:
+
+    {{ GetNameservers | exclude "rfc" "1122" | join "address" " " }}
+
+`GetNeighbors` - Returns one IfAddr for every entry of the neighbor table (the
+ARP cache for IPv4 and the NDP neighbor cache for IPv6), the equivalent of `ip
+neigh`.  The address of each IfAddr is the IP address of the neighbor, its
+interface is the interface the neighbor was seen on, and the `lladdr` and
+`neighbor_state` attributes are the link-layer address and state of the
+entry.  The neighbor table can only be read on Linux.
+
+Example:
+
+    {{ GetNeighbors | include "name" "eth0" | include "neighbor_state" "reachable" | join "address" " " }}
+
 `GetPrivateInterfaces` - Returns one IfAddr for every forwardable IP address
 that is included in RFC 6890 and whose interface is marked as up.  NOTE: RFC 6890 is a more exhaustive
 version of RFC1918 because it spans IPv4 and IPv6, however, RFC6890 does permit the
@@ -125,6 +240,7 @@ criteria is comma separated (`,`):
   - `address`, `+address`: Ascending sort of IfAddrs by Address
   - `-address`: Descending sort of IfAddrs by Address
   - `default`, `+default`: Ascending sort of IfAddrs, IfAddr with a default route first
+    (ordered by the metric of the default route, IPv4 default routes first)
   - `-default`: Descending sort of IfAddrs, IfAttr with default route last
   - `name`, `+name`: Ascending sort of IfAddrs by lexical ordering of interface name
   - `-name`: Descending sort of IfAddrs by lexical ordering of interface name
@@ -132,6 +248,18 @@ criteria is comma separated (`,`):
   - `-port`: Descending sort of IfAddrs by port number
   - `private`, `+private`: Ascending sort of IfAddrs with private addresses first
   - `-private`: Descending sort IfAddrs with private addresses last
+  - `rfc6724`, `+rfc6724`: Ascending sort of IfAddrs by their RFC 6724 scope,
+    addresses with a global scope first, then by the precedence of the
+    address in the RFC 6724 policy table (global IPv6, IPv4, 6to4, Teredo,
+    then ULAs with the default table, see `sockaddr.SetPolicyTable()`).  This
+    does not rank the addresses against a destination as RFC 6724 source
+    address selection does (see `sockaddr.OrderSourceAddrs()`)
+  - `-rfc6724`: Descending sort of IfAddrs by their RFC 6724 scope and
+    precedence
+  - `speed`, `+speed`: Ascending sort of IfAddrs by the speed of their
+    interface (interfaces of unknown speed first)
+  - `-speed`: Descending sort of IfAddrs by the speed of their interface
+    (fastest interfaces first)
   - `size`, `+size`: Ascending sort of IfAddrs by their network size as determined
     by their netmask (larger networks first)
   - `-size`: Descending sort of IfAddrs by their network size as determined by their
@@ -150,18 +278,54 @@ arguments.  Both `exclude` and `include` take two arguments.  The list of
 available filtering criteria is:
   - "address": Filter IfAddrs based on a regexp matching the string representation
     of the address
+  - "class": Filter IfAddrs based on a named address class.  Multiple classes
+    can be specified together by using the pipe character (`|`).  Valid classes
+    include: `benchmarking`, `cgnat`, `documentation`, `link-local`,
+    `loopback`, `multicast`, `private` (RFC 1918 and RFC 4193), and `ula`.
   - "flag","flags": Filter IfAddrs based on the list of flags specified.  Multiple
     flags can be passed together using the pipe character (`|`) to create an inclusive
     bitmask of flags.  The list of flags is included below.
+  - "kind": Filter IfAddrs based on the kind of their interface.  Multiple
+    kinds can be specified together by using the pipe character (`|`).  Kinds
+    include: `physical`, `loopback`, `bridge`, `veth`, `vlan`, `bond`, `tun`,
+    `tap`, `wireguard`, and `dummy`; other virtual interfaces use the kernel's
+    name of their link type (e.g. `vxlan`).  Interface kinds are only known
+    on Linux.
+  - "multicast_scope": Filter IfAddrs based on the scope of a multicast group.
+    Multiple scopes can be specified together by using the pipe character (`|`).
+    Valid scopes include: `interface`, `link`, `realm`, `admin`, `site`,
+    `organization`, `global`, `reserved`, `unassigned`, and `none` (for
+    addresses that are not multicast groups).  IPv4 scopes follow RFC 2365.
   - "name": Filter IfAddrs based on a regexp matching the interface name.
+  - "neighbor_state": Filter IfAddrs returned by `GetNeighbors` based on the
+    state of their neighbor table entry.  Multiple states can be specified
+    together by using the pipe character (`|`).  States include:
+    `incomplete`, `reachable`, `stale`, `delay`, `probe`, `failed`,
+    `permanent`, and `none`.
   - "network": Filter IfAddrs based on whether a netowkr is included in a given
     CIDR.  More than one CIDR can be passed in if each network is separated by
     the pipe character (`|`).
+  - "operstate": Filter IfAddrs based on the operational state of their
+    interface (RFC 2863).  Multiple states can be specified together by using
+    the pipe character (`|`).  States include: `up`, `down`,
+    `lowerlayerdown`, `dormant`, `notpresent`, `testing`, and `unknown`.
+    Unlike the `up` flag, an interface without a carrier is not
+    operationally up.  Loopback and some virtual interfaces report
+    `unknown`.  Operational states are only known on Linux.
   - "port": Filter IfAddrs based on an exact match of the port number (number must
     be expressed as a string)
   - "rfc", "rfcs": Filter IfAddrs based on the matching RFC.  If more than one RFC
     is specified, the list of RFCs can be joined together using the pipe character (`|`).
+    RFCs are looked up in the RFC registry, which applications can extend with
+    `sockaddr.RegisterRFC()` or `sockaddr.LoadIANARegistryFile()`.  The name of
+    an address class (see "class") can be used in place of an RFC number.
   - "size": Filter IfAddrs based on the exact match of the mask size.
+  - "tag": Filter IfAddrs based on the label of the most specific tagged
+    network containing the address.  Multiple labels can be specified together
+    by using the pipe character (`|`).  Tags are loaded with
+    `sockaddr.LoadTagsFile()` (or the `-tags` flag of `sockaddr eval`) from a
+    file with one CIDR and label per line, and can be reloaded at runtime with
+    `sockaddr.ReloadTags()`.
   - "type": Filter IfAddrs based on their SockAddr type.  Multiple types can be
     specified together by using the pipe character (`|`).  Valid types include:
     `ip`, `ipv4`, `ipv6`, and `unix`.
@@ -169,6 +333,7 @@ available filtering criteria is:
 Example:
 
     {{ GetPrivateInterfaces | exclude "type" "IPv6" }}
+    {{ GetPrivateInterfaces | include "operstate" "up" | sort "-speed" | attr "address" }}
 
 
 `unique`: Removes duplicate entries from the IfAddrs list, assuming the list has
@@ -202,16 +367,15 @@ operation's value.
 
 Supported operations include:
 
-  - `address`: Adds the value, a positive or negative value expressed as a
-    decimal string, to the address.  The sign is required.  This value is
-    allowed to over or underflow networks (e.g. 127.255.255.255 `"address" "+1"`
-    will return "128.0.0.0").  Addresses will wrap at IPv4 or IPv6 boundaries.
-  - `network`: Add the value, a positive or negative value expressed as a
-    decimal string, to the network address.  The sign is required.  Positive
-    values are added to the network address.  Negative values are subtracted
-    from the network's broadcast address (e.g. 127.0.0.1 `"network" "-1"` will
-    return "127.255.255.255").  Values that overflow the network size will
-    safely wrap.
+  - `address`: Adds the value, a positive or negative integer, to the
+    address.  The sign is required.  This value is allowed to over or
+    underflow networks (e.g. 127.255.255.255 `"address" "+1"` will return
+    "128.0.0.0").  Addresses will wrap at IPv4 or IPv6 boundaries.
+  - `network`: Add the value, a positive or negative integer, to the network
+    address.  The sign is required.  Positive values are added to the network
+    address.  Negative values are subtracted from the network's broadcast
+    address (e.g. 127.0.0.1 `"network" "-1"` will return "127.255.255.255").
+    Values that overflow the network size will safely wrap.
   - `mask`: Applies the given network mask to the address. The network mask is
   	expressed as a decimal value (e.g. network mask "24" corresponds to
   	`255.255.255.0`). After applying the network mask, the network mask of the
@@ -219,6 +383,23 @@ Supported operations include:
   	of the input address depending on which network is larger
   	(e.g. 192.168.10.20/24 `"mask" "16"` will return "192.168.0.0/16" but
   	192.168.10.20/24 `"mask" "28"` will return "192.168.10.16/24").
+  - `port`: Sets the port to the value, or adjusts the port if the value is
+    signed (e.g. 10.0.0.1:80 `"port" "+1"` will return "10.0.0.1:81").
+  - `prefix`: Sets the prefix length to the value without masking the address,
+    or adjusts the prefix length if the value is signed (e.g. 10.1.2.3/8
+    `"prefix" "24"` will return "10.1.2.3/24").
+  - `and`, `or`, `xor`: Applies a bitwise operation to the address.  The value
+    is either an address of the same type or an integer (e.g. 10.1.2.3/8
+    `"and" "255.255.0.255"` will return "10.1.0.3/8" and fe80::/64 `"or"
+    "::1"` will return "fe80::1/64").
+  - `subnet`: Selects a child network.  The value is the child's prefix length
+    and index separated by a colon.  Negative indexes count back from the last
+    child network (e.g. 10.0.0.0/16 `"subnet" "24:3"` will return
+    "10.0.3.0/24" and `"subnet" "24:-1"` will return "10.0.255.0/24").
+
+Integer values are decimal unless prefixed with `0x` for hex (e.g.
+`"address" "+0x100"`), and must fit in the width of the address (32 bits for
+IPv4 and 128 bits for IPv6).
 
 Example:
 
@@ -227,6 +408,7 @@ Example:
     {{ GetPrivateInterfaces | include "type" "IP" | math "network" "+2" | attr "address" }}
     {{ GetPrivateInterfaces | include "type" "IP" | math "network" "-2" | attr "address" }}
     {{ GetPrivateInterfaces | include "type" "IP" | math "mask" "24" | attr "address" }}
+    {{ GetPrivateInterfaces | include "type" "IPv6" | math "subnet" "64:0x10" | math "or" "::53" | attr "address" }}
     {{ GetPrivateInterfaces | include "flags" "forwardable|up" | include "type" "IPv4" | math "network" "+2" | attr "address" }}
 
 
@@ -263,19 +445,48 @@ Example:
 
 
 `exclude` and `include` flags:
+  - `bogon`: Is the IP a bogon?  Multicast addresses and addresses that are
+    not globally reachable (RFC 6890) are always bogons, as are addresses in
+    the full bogons list if one was loaded with `sockaddr.LoadBogonsFile()` (or
+    the `-bogons` flag of `sockaddr eval`).
   - `broadcast`
+  - `dadfailed`: Did the IPv6 address fail duplicate address detection?
+  - `deprecated`: Has the preferred lifetime of the address expired?
+  - `destination`: May the IP be used as a destination address (RFC 6890)?
   - `down`: Is the interface down?
-  - `forwardable`: Is the IP forwardable?
+  - `dynamic`: Does the address expire (e.g. DHCP or SLAAC)?
+  - `forwardable`: Is the IP forwardable (RFC 6890)?
+  - `global`: Is the IP forwardable beyond its administrative domain (RFC 6890)?
   - `global unicast`
   - `interface-local multicast`
   - `link-local multicast`
   - `link-local unicast`
   - `loopback`
   - `multicast`
+  - `noprefixroute`: Was the address added without a route to its network?
+  - `permanent`: Does the address never expire?
   - `point-to-point`
+  - `temporary`: Is the IPv6 address an RFC 4941 temporary (privacy) address?
+  - `tentative`: Is duplicate address detection of the IPv6 address pending?
   - `unspecified`: Is the IfAddr the IPv6 unspecified address?
   - `up`: Is the interface up?
 
+The address flags (`dadfailed`, `deprecated`, `dynamic`, `noprefixroute`,
+`permanent`, `temporary` and `tentative`) are only known on Linux, and a list
+of them matches an IfAddr with any of the flags set, e.g. to skip the addresses
+that should not be advertised to other hosts:
+
+    {{ GetAllInterfaces | exclude "flags" "temporary|deprecated|tentative" | attr "address" }}
+
+`GetPrivateInterfaces` and `GetPublicInterfaces` skip temporary, deprecated,
+tentative and duplicate addresses.
+
+A list that mixes address flags with the other flags matches an IfAddr only
+if both parts do, so the following includes the temporary and deprecated
+addresses of the interfaces that are up:
+
+    {{ GetAllInterfaces | include "flags" "up|temporary|deprecated" | attr "address" }}
+
 
 Attributes for `attr`, `Attr`, and `join`:
 
@@ -286,26 +497,65 @@ SockAddr Type:
 IPAddr Type:
   - `address`
   - `binary`
+  - `class`: Address classes the address belongs to joined by `|` (e.g.
+    `private|ula`)
   - `first_usable`
   - `hex`
   - `host`
   - `last_usable`
   - `mask_bits`
+  - `multicast_mac`: Ethernet group address of a multicast group
+  - `multicast_scope`: Scope of a multicast group (see the "multicast_scope"
+    filter), or `none`
   - `netmask`
   - `network`
   - `octets`: Decimal values per byte
   - `port`
   - `size`: Number of hosts in the network
+  - `tag`: Label of the most specific tagged network containing the address
+    (see the "tag" filter)
 
 IPv4Addr Type:
   - `broadcast`
   - `uint32`: unsigned integer representation of the value
 
 IPv6Addr Type:
+  - `multicast_flags`: Flags of a multicast group (e.g. `permanent` or
+    `transient|prefix`)
+  - `solicited_node`: Solicited-node multicast group of a unicast address
   - `uint128`: unsigned integer representation of the value
 
 UnixSock Type:
   - `path`
 
+IfAddr Type:
+  - `address_flags`: Address flags joined by `|` (e.g. `temporary|dynamic`),
+    or empty if they are unknown
+  - `carrier`: `true` if the interface has a carrier, e.g. a cable is
+    plugged in
+  - `duplex`: Duplex mode of the interface: `full`, `half` or `unknown`
+  - `flags`
+  - `gateway`: Gateway of the default route through the interface for the
+    address family of the address, or empty if there is none.  Read from the
+    routing table of the evaluation; for IfAddrs passed to `ParseIfAddrs` it
+    is read from the current routing table
+  - `kind`: Kind of the interface (see the "kind" filter)
+  - `lladdr`: Link-layer address of a neighbor returned by `GetNeighbors`
+  - `master`: Name of the bridge or bond the interface is enslaved to, or
+    empty
+  - `name`
+  - `neighbor_state`: State of a neighbor returned by `GetNeighbors` (see the
+    "neighbor_state" filter)
+  - `operstate`: Operational state of the interface (see the "operstate"
+    filter)
+  - `preferred_lifetime`: Seconds until the address is deprecated, or
+    `forever`
+  - `rx_bytes`, `tx_bytes`: Bytes received and transmitted by the interface
+  - `rx_errors`, `tx_errors`: Receive and transmit errors of the interface
+  - `speed_mbps`: Speed of the interface in Mbit/s, or empty if it is
+    unknown
+  - `valid_lifetime`: Seconds until the address is removed, or `forever`
+  - `vlan_id`: VLAN ID of a `vlan` interface
+
 */
 package template
@@ -2,6 +2,8 @@ package template
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"text/template"
 
@@ -32,11 +34,42 @@ func init() {
 		// pipeline.
 		"GetAllInterfaces": sockaddr.GetAllInterfaces,
 
+		// GetAllInterfacesPartial - Returns the IfAddrs of
+		// `GetAllInterfaces` without the interfaces whose addresses
+		// can not be read and the addresses that can not be
+		// converted, instead of failing.
+		"GetAllInterfacesPartial": skipInterfaceErrors(sockaddr.GetAllInterfacesPartial),
+
 		// GetDefaultInterfaces - Returns one IfAddr for every IP that
 		// is on the interface containing the default route for the
 		// host.
 		"GetDefaultInterfaces": sockaddr.GetDefaultInterfaces,
 
+		// GetDefaultInterfacesV4 - Returns one IfAddr for every IPv4
+		// address on the interface of the IPv4 default route with the
+		// lowest metric.
+		"GetDefaultInterfacesV4": sockaddr.GetDefaultInterfacesV4,
+
+		// GetDefaultInterfacesV6 - Returns one IfAddr for every IPv6
+		// address on the interface of the IPv6 default route with the
+		// lowest metric.
+		"GetDefaultInterfacesV6": sockaddr.GetDefaultInterfacesV6,
+
+		// GetInterfaceForDestination - Returns the IfAddr used as the
+		// source address to reach the given destination address.
+		"GetInterfaceForDestination": sockaddr.GetInterfaceForDestination,
+
+		// GetNeighbors - Returns one IfAddr for every entry of the
+		// neighbor table (ARP and NDP) with the IP address of the
+		// neighbor, the interface it was seen on, and its link-layer
+		// address and state.
+		"GetNeighbors": sockaddr.GetNeighbors,
+
+		// GetNameservers - Returns one IfAddr for every nameserver of
+		// the host: the nameservers of /etc/resolv.conf followed by the
+		// per-link upstream nameservers of systemd-resolved.
+		"GetNameservers": sockaddr.GetNameservers,
+
 		// GetPrivateInterfaces - Returns one IfAddr for every IP that
 		// matches RFC 6890, are attached to the interface with the
 		// default route, and are forwardable IP addresses.  NOTE: RFC
@@ -114,15 +147,87 @@ func Attr(selectorName string, ifAddrsRaw interface{}) (string, error) {
 	}
 }
 
+// systemFuncs returns the source and helper functions of SourceFuncs and
+// HelperFuncs that inspect the host, bound to system so that an evaluation
+// uses a single SystemProvider and reads the routing table at most once.
+func systemFuncs(system *sockaddr.System) template.FuncMap {
+	return template.FuncMap{
+		"GetAllInterfaces":           system.GetAllInterfaces,
+		"GetAllInterfacesPartial":    skipInterfaceErrors(system.GetAllInterfacesPartial),
+		"GetDefaultInterfaces":       system.GetDefaultInterfaces,
+		"GetDefaultInterfacesV4":     system.GetDefaultInterfacesV4,
+		"GetDefaultInterfacesV6":     system.GetDefaultInterfacesV6,
+		"GetInterfaceForDestination": system.GetInterfaceForDestination,
+		"GetNeighbors":               system.GetNeighbors,
+		"GetNameservers":             system.GetNameservers,
+		"GetPrivateInterfaces":       system.GetPrivateInterfaces,
+		"GetPublicInterfaces":        system.GetPublicInterfaces,
+		"GetPrivateIP":               system.GetPrivateIP,
+		"GetPrivateIPs":              system.GetPrivateIPs,
+		"GetPublicIP":                system.GetPublicIP,
+		"GetPublicIPs":               system.GetPublicIPs,
+		"GetInterfaceIP":             system.GetInterfaceIP,
+		"GetInterfaceIPs":            system.GetInterfaceIPs,
+	}
+}
+
+// skipInterfaceErrors returns a source function that returns the IfAddrs of
+// getAllInterfacesPartial and only fails if the interfaces can not be listed at
+// all, i.e. the sockaddr.InterfaceErrors of the skipped entries are dropped.
+func skipInterfaceErrors(getAllInterfacesPartial func() (sockaddr.IfAddrs, error)) func() (sockaddr.IfAddrs, error) {
+	return func() (sockaddr.IfAddrs, error) {
+		ifAddrs, err := getAllInterfacesPartial()
+		var ifErrs sockaddr.InterfaceErrors
+		if err != nil && !errors.As(err, &ifErrs) {
+			return nil, err
+		}
+		return ifAddrs, nil
+	}
+}
+
 // Parse parses input as template input using the addresses available on the
-// host, then returns the string output if there are no errors.
+// host, then returns the string output if there are no errors.  The initial
+// "dot" holds the addresses that can be read: interfaces and addresses that
+// can not be read are skipped, as with GetAllInterfacesPartial, rather than
+// failing the template.  Templates that call GetAllInterfaces or the sources
+// built on it (e.g. GetPrivateIP) still fail on them.
 func Parse(input string) (string, error) {
-	addrs, err := sockaddr.GetAllInterfaces()
+	return ParseContext(context.Background(), input)
+}
+
+// ParseContext is like Parse, but commands run to read the routing table are
+// bound to ctx.  The routing table is only read if the template uses it, e.g.
+// with GetDefaultInterfaces, sort "default" or the "gateway" attribute, and
+// at most once per evaluation.
+func ParseContext(ctx context.Context, input string) (string, error) {
+	return ParseWithSystem(input, sockaddr.NewSystem(ctx, nil))
+}
+
+// ParseWithSnapshot parses input as template input against the host captured
+// in the snapshot file at snapshotPath (see sockaddr.WriteSnapshot) instead of
+// the live host, then returns the string output if there are no errors.  Only
+// this evaluation uses the snapshot; the sockaddr.SystemProvider of the process
+// is left alone.
+func ParseWithSnapshot(input, snapshotPath string) (string, error) {
+	snapshot, err := sockaddr.LoadFakeSystemFile(snapshotPath)
+	if err != nil {
+		return "", errwrap.Wrapf("unable to load snapshot: {{err}}", err)
+	}
+
+	return ParseWithSystem(input, sockaddr.NewSystem(context.Background(), snapshot))
+}
+
+// ParseWithSystem parses input as template input against the host inspected by
+// system, then returns the string output if there are no errors.  Evaluations
+// that share a System share its snapshot of the routing table.  As with Parse,
+// the initial "dot" skips the interfaces and addresses that can not be read.
+func ParseWithSystem(input string, system *sockaddr.System) (string, error) {
+	addrs, err := skipInterfaceErrors(system.GetAllInterfacesPartial)()
 	if err != nil {
 		return "", errwrap.Wrapf("unable to query interface addresses: {{err}}", err)
 	}
 
-	return ParseIfAddrs(input, addrs)
+	return parseIfAddrsTemplate(system, input, addrs, template.New("sockaddr.Parse"))
 }
 
 // ParseIfAddrs parses input as template input using the IfAddrs inputs, then
@@ -134,12 +239,19 @@ func ParseIfAddrs(input string, ifAddrs sockaddr.IfAddrs) (string, error) {
 // ParseIfAddrsTemplate parses input as template input using the IfAddrs inputs,
 // then returns the string output if there are no errors.
 func ParseIfAddrsTemplate(input string, ifAddrs sockaddr.IfAddrs, tmplIn *template.Template) (string, error) {
+	return parseIfAddrsTemplate(sockaddr.NewSystem(context.Background(), nil), input, ifAddrs, tmplIn)
+}
+
+// parseIfAddrsTemplate parses input as template input using the IfAddrs
+// inputs, with the functions that inspect the host bound to system.
+func parseIfAddrsTemplate(system *sockaddr.System, input string, ifAddrs sockaddr.IfAddrs, tmplIn *template.Template) (string, error) {
 	// Create a template, add the function map, and parse the text.
 	tmpl, err := tmplIn.Option("missingkey=error").
 		Funcs(SourceFuncs).
 		Funcs(SortFuncs).
 		Funcs(FilterFuncs).
 		Funcs(HelperFuncs).
+		Funcs(systemFuncs(system)).
 		Parse(input)
 	if err != nil {
 		return "", errwrap.Wrapf(fmt.Sprintf("unable to parse template %+q: {{err}}", input), err)
@@ -1,8 +1,14 @@
 package template_test
 
 import (
+	"context"
+	"errors"
+	"net"
+	"path/filepath"
 	"testing"
+	"time"
 
+	sockaddr "github.com/hashicorp/go-sockaddr"
 	socktmpl "github.com/hashicorp/go-sockaddr/template"
 )
 
@@ -276,3 +282,203 @@ func TestSockAddr_Parse(t *testing.T) {
 		})
 	}
 }
+
+func TestParseWithFakeSystem(t *testing.T) {
+	fake, err := sockaddr.LoadFakeSystemFile(filepath.Join("..", "testdata", "fake_system.json"))
+	if err != nil {
+		t.Fatalf("unable to load fixture: %v", err)
+	}
+	sockaddr.SetSystemProvider(fake)
+	defer sockaddr.SetSystemProvider(nil)
+
+	tests := []struct {
+		name   string
+		input  string
+		output string
+	}{
+		{
+			name:   "GetPrivateIP",
+			input:  `{{GetPrivateIP}}`,
+			output: `10.0.0.5`,
+		},
+		{
+			name:   "GetPublicIP",
+			input:  `{{GetPublicIP}}`,
+			output: `34.120.5.10`,
+		},
+		{
+			name:   "GetDefaultInterfaces",
+			input:  `{{GetDefaultInterfaces | include "type" "IPv4" | attr "name"}}`,
+			output: `eth0`,
+		},
+		{
+			name:   "GetDefaultInterfacesV6",
+			input:  `{{GetDefaultInterfacesV6 | exclude "class" "link-local" | attr "address"}}`,
+			output: `fd00:10::5`,
+		},
+		{
+			name:   `sort "default"`,
+			input:  `{{GetAllInterfaces | include "type" "IPv4" | sort "default" | join "name" " "}}`,
+			output: `eth0 eth1 lo`,
+		},
+		{
+			name:   "GetInterfaceForDestination",
+			input:  `{{GetInterfaceForDestination "34.120.5.77" | attr "address"}}`,
+			output: `34.120.5.10`,
+		},
+		{
+			name:   `include "kind"`,
+			input:  `{{GetAllInterfaces | include "kind" "physical|vlan" | include "type" "IPv4" | join "name" " "}}`,
+			output: `eth0 eth1`,
+		},
+		{
+			name:   "vlan_id",
+			input:  `{{GetAllInterfaces | include "kind" "vlan" | attr "vlan_id"}}`,
+			output: `120`,
+		},
+		{
+			name:   `include "operstate"`,
+			input:  `{{GetAllInterfaces | include "operstate" "up" | include "type" "IPv4" | sort "-speed" | join "name" " "}}`,
+			output: `eth1 eth0`,
+		},
+		{
+			name:   "speed_mbps",
+			input:  `{{GetAllInterfaces | include "type" "IPv4" | sort "-speed" | attr "speed_mbps"}}`,
+			output: `25000`,
+		},
+		{
+			name:   "GetNeighbors",
+			input:  `{{GetNeighbors | include "neighbor_state" "reachable|permanent" | include "type" "IPv4" | join "address" " "}}`,
+			output: `10.0.0.1 34.120.5.1`,
+		},
+		{
+			name:   "lladdr",
+			input:  `{{GetNeighbors | include "network" "10.0.0.7/32" | attr "lladdr"}}`,
+			output: `02:42:0a:00:00:07`,
+		},
+		{
+			name:   "GetNameservers",
+			input:  `{{GetNameservers | exclude "rfc" "1122" | include "type" "IPv4" | join "address" " "}}`,
+			output: `10.0.0.2 34.120.5.53`,
+		},
+		{
+			name:   "gateway",
+			input:  `{{GetAllInterfaces | include "name" "eth1" | attr "gateway"}}`,
+			output: `34.120.5.1`,
+		},
+		{
+			name:   "interface without addresses",
+			input:  `{{GetAllInterfaces | include "name" "wlan0" | len}}`,
+			output: `0`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			out, err := socktmpl.Parse(test.input)
+			if err != nil {
+				t.Fatalf("bad: %v", err)
+			}
+			if out != test.output {
+				t.Errorf("expected %+q, received %+q", test.output, out)
+			}
+		})
+	}
+}
+
+func TestParseContext(t *testing.T) {
+	fake, err := sockaddr.LoadFakeSystemFile(filepath.Join("..", "testdata", "fake_system.json"))
+	if err != nil {
+		t.Fatalf("unable to load fixture: %v", err)
+	}
+	sockaddr.SetSystemProvider(fake)
+	defer sockaddr.SetSystemProvider(nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	out, err := socktmpl.ParseContext(ctx, `{{GetAllInterfaces | include "type" "IPv4" | sort "default" | attr "gateway"}}`)
+	if err != nil {
+		t.Fatalf("unable to parse: %v", err)
+	}
+	if out != "10.0.0.1" {
+		t.Errorf("want 10.0.0.1, got %q", out)
+	}
+}
+
+func TestParseWithSnapshot(t *testing.T) {
+	path := filepath.Join("..", "testdata", "fake_system.json")
+	out, err := socktmpl.ParseWithSnapshot(`{{GetDefaultInterfaces | include "type" "IPv4" | attr "gateway"}}`, path)
+	if err != nil {
+		t.Fatalf("unable to parse with snapshot: %v", err)
+	}
+	if out != "10.0.0.1" {
+		t.Errorf("want 10.0.0.1, got %q", out)
+	}
+
+	if _, ok := sockaddr.CurrentSystemProvider().(*sockaddr.FakeSystem); ok {
+		t.Errorf("expected the system provider to be left alone")
+	}
+
+	if _, err := socktmpl.ParseWithSnapshot(`{{GetPrivateIP}}`, filepath.Join("..", "testdata", "missing.json")); err == nil {
+		t.Errorf("expected a missing snapshot to fail")
+	}
+}
+
+// brokenSystem is a FakeSystem whose tun0 interface can not be read.
+type brokenSystem struct {
+	*sockaddr.FakeSystem
+}
+
+func (s brokenSystem) Addrs(ifi net.Interface) ([]net.Addr, error) {
+	if ifi.Name == "tun0" {
+		return nil, errors.New("tunnel is gone")
+	}
+	return s.FakeSystem.Addrs(ifi)
+}
+
+func TestParseGetAllInterfacesPartial(t *testing.T) {
+	system := sockaddr.NewSystem(context.Background(), brokenSystem{sockaddr.NewFakeSystem().
+		AddInterface(net.Interface{Name: "eth0", Flags: net.FlagUp}, sockaddr.MustIPv4Addr("10.0.0.5/24")).
+		AddInterface(net.Interface{Name: "tun0", Flags: net.FlagUp}, sockaddr.MustIPv4Addr("172.16.0.5/30"))})
+
+	tests := []struct {
+		name   string
+		input  string
+		output string
+		fail   bool
+	}{
+		{
+			name:   "GetAllInterfacesPartial",
+			input:  `{{GetAllInterfacesPartial | join "name" " "}}`,
+			output: `eth0`,
+		},
+		{
+			name:   "dot",
+			input:  `{{. | join "address" " "}}`,
+			output: `10.0.0.5`,
+		},
+		{
+			name:  "GetAllInterfaces",
+			input: `{{GetAllInterfaces | join "name" " "}}`,
+			fail:  true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			out, err := socktmpl.ParseWithSystem(test.input, system)
+			if test.fail {
+				if err == nil {
+					t.Fatalf("expected %+q to fail", test.input)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unable to parse %+q: %v", test.input, err)
+			}
+			if out != test.output {
+				t.Errorf("expected %+q, received %+q", test.output, out)
+			}
+		})
+	}
+}
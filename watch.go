@@ -0,0 +1,333 @@
+package sockaddr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+)
+
+// InterfaceEventType is the kind of change reported by an InterfaceEvent.
+type InterfaceEventType int
+
+const (
+	// AddressAdded is reported when an address is added to an interface.
+	AddressAdded InterfaceEventType = iota + 1
+
+	// AddressRemoved is reported when an address is removed from an
+	// interface.
+	AddressRemoved
+
+	// LinkUp is reported when an interface comes up or a new interface is
+	// added in the up state.
+	LinkUp
+
+	// LinkDown is reported when an interface goes down or an interface in
+	// the up state is removed.
+	LinkDown
+
+	// DefaultRouteChanged is reported when a default route is added,
+	// removed or changed.
+	DefaultRouteChanged
+)
+
+// watchPollInterval is how often WatchInterfaces polls the interfaces on
+// platforms without change notifications.
+const watchPollInterval = 5 * time.Second
+
+// watchSettleDelay is how long WatchInterfaces waits after a change
+// notification before reading the interfaces, so that a burst of
+// notifications (e.g. a DHCP renewal) is handled at once.
+const watchSettleDelay = 100 * time.Millisecond
+
+// String returns the name of the event type.
+func (t InterfaceEventType) String() string {
+	switch t {
+	case AddressAdded:
+		return "address added"
+	case AddressRemoved:
+		return "address removed"
+	case LinkUp:
+		return "link up"
+	case LinkDown:
+		return "link down"
+	case DefaultRouteChanged:
+		return "default route changed"
+	default:
+		return fmt.Sprintf("unknown event type %d", int(t))
+	}
+}
+
+// InterfaceEvent is a change of the interfaces of the host.
+type InterfaceEvent struct {
+	Type InterfaceEventType
+
+	// IfAddr is the address that was added or removed.  For link events
+	// only the Interface is set.  For DefaultRouteChanged events the
+	// Interface is the interface of the preferred default route, or empty
+	// if the host no longer has a default route.
+	IfAddr IfAddr
+}
+
+// WatchInterfaces reports changes to the addresses, link state and default
+// routes of the host until ctx is done, after which the channel is closed.  On
+// Linux changes are detected with netlink notifications; on other platforms,
+// if netlink is unavailable or fails, or if a SystemProvider has been set, the
+// interfaces are polled.  Events are sent as the difference between two reads
+// of the interfaces, so short-lived changes may be missed.  No events are sent
+// until the interfaces have been read once.
+func WatchInterfaces(ctx context.Context) <-chan InterfaceEvent {
+	events := make(chan InterfaceEvent)
+	system := NewSystem(ctx, nil)
+
+	go func() {
+		defer close(events)
+
+		// Notifications report changes of the host, so a fake
+		// SystemProvider is polled.
+		var notify <-chan struct{}
+		if system.isHostSystem() {
+			notify, _ = interfaceNotifications(ctx)
+		}
+		watchInterfaces(ctx, system.provider, notify, watchPollInterval, events)
+	}()
+
+	return events
+}
+
+// watchInterfaces sends the changes of the interfaces of provider to events
+// until ctx is done.  The interfaces are read again whenever notify fires.  If
+// notify is nil or closed, the interfaces are polled every pollInterval
+// instead.
+func watchInterfaces(ctx context.Context, provider SystemProvider, notify <-chan struct{}, pollInterval time.Duration, events chan<- InterfaceEvent) {
+	if notify == nil {
+		notify = pollInterfaces(ctx, pollInterval)
+	}
+
+	// The first successful read is the baseline later reads are compared
+	// with.
+	state, err := readInterfaceState(ctx, provider)
+	haveState := err == nil
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case _, ok := <-notify:
+			if !ok {
+				notify = pollInterfaces(ctx, pollInterval)
+			}
+		}
+
+		// Coalesce the notifications that follow shortly after.
+		settle := time.NewTimer(watchSettleDelay)
+	drain:
+		for {
+			select {
+			case <-ctx.Done():
+				settle.Stop()
+				return
+			case _, ok := <-notify:
+				if !ok {
+					notify = pollInterfaces(ctx, pollInterval)
+				}
+			case <-settle.C:
+				break drain
+			}
+		}
+
+		newState, err := readInterfaceState(ctx, provider)
+		if err != nil {
+			continue
+		}
+		if !haveState {
+			state, haveState = newState, true
+			continue
+		}
+		newState.keepUnreadAddrs(state)
+
+		for _, event := range diffInterfaceState(state, newState) {
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+		state = newState
+	}
+}
+
+// pollInterfaces returns a channel that fires every interval until ctx is
+// done.
+func pollInterfaces(ctx context.Context, interval time.Duration) <-chan struct{} {
+	polls := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				select {
+				case polls <- struct{}{}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return polls
+}
+
+// interfaceState is a read of the interfaces of the host that is compared
+// with the previous read by WatchInterfaces.
+type interfaceState struct {
+	links         map[string]net.Interface
+	addrs         map[string]IfAddr
+	defaultRoutes string
+	defaultIf     net.Interface
+
+	// unreadIfs are the names of the interfaces whose addresses could not
+	// be read.
+	unreadIfs map[string]bool
+}
+
+// keepUnreadAddrs copies the addresses of the interfaces whose addresses
+// could not be read from prev, so that a failed read is not reported as the
+// removal of their addresses.
+func (s *interfaceState) keepUnreadAddrs(prev interfaceState) {
+	for key, ifAddr := range prev.addrs {
+		if s.unreadIfs[ifAddr.Name] {
+			s.addrs[key] = ifAddr
+		}
+	}
+}
+
+// readInterfaceState reads the interfaces, addresses and default routes of the
+// host through provider.  Interfaces and addresses that can not be read are
+// skipped, and the interfaces whose addresses could not be read are recorded
+// in unreadIfs.
+func readInterfaceState(ctx context.Context, provider SystemProvider) (interfaceState, error) {
+	system := NewSystem(ctx, provider)
+	ifs, err := provider.Interfaces()
+	if err != nil {
+		return interfaceState{}, err
+	}
+
+	ifAddrs, err := system.GetAllInterfacesPartial()
+	var ifErrs InterfaceErrors
+	if err != nil && !errors.As(err, &ifErrs) {
+		return interfaceState{}, err
+	}
+
+	state := interfaceState{
+		links:     make(map[string]net.Interface, len(ifs)),
+		addrs:     make(map[string]IfAddr, len(ifAddrs)),
+		unreadIfs: make(map[string]bool, len(ifErrs)),
+	}
+	for _, ifi := range ifs {
+		state.links[ifi.Name] = ifi
+	}
+	for _, ifErr := range ifErrs {
+		if ifErr.Address == "" {
+			state.unreadIfs[ifErr.Interface] = true
+		}
+	}
+	for _, ifAddr := range ifAddrs {
+		state.addrs[ifAddr.Name+"|"+ifAddr.SockAddr.String()] = ifAddr
+	}
+
+	snapshot := system.routeSnapshot()
+	if snapshot.routesErr == nil {
+		var defaults []string
+		for _, sockType := range []SockAddrType{TypeIPv4, TypeIPv6} {
+			for _, route := range defaultRoutes(snapshot.routes, sockType) {
+				var gateway string
+				if route.Gateway != nil {
+					gateway = route.Gateway.String()
+				}
+				defaults = append(defaults, fmt.Sprintf("%s via %s dev %s metric %d", route.Destination, gateway, route.IfName, route.Metric))
+			}
+		}
+		state.defaultRoutes = strings.Join(defaults, "\n")
+	} else if snapshot.defaultIfErr == nil {
+		state.defaultRoutes = snapshot.defaultIfName
+	}
+
+	if ifNames, err := snapshot.defaultIfNames(); err == nil {
+		state.defaultIf = state.links[ifNames[0]]
+	}
+
+	return state, nil
+}
+
+// diffInterfaceState returns the events that turn prev into next.  Link events
+// are reported first, followed by removed and added addresses, and a change
+// of the default routes.
+func diffInterfaceState(prev, next interfaceState) []InterfaceEvent {
+	var events []InterfaceEvent
+
+	isUp := func(ifi net.Interface, found bool) bool {
+		return found && ifi.Flags&net.FlagUp != 0
+	}
+	for _, name := range sortedLinkNames(prev.links, next.links) {
+		prevIf, prevFound := prev.links[name]
+		nextIf, nextFound := next.links[name]
+		switch wasUp, nowUp := isUp(prevIf, prevFound), isUp(nextIf, nextFound); {
+		case !wasUp && nowUp:
+			events = append(events, InterfaceEvent{Type: LinkUp, IfAddr: IfAddr{Interface: nextIf}})
+		case wasUp && !nowUp:
+			ifi := nextIf
+			if !nextFound {
+				ifi = prevIf
+			}
+			events = append(events, InterfaceEvent{Type: LinkDown, IfAddr: IfAddr{Interface: ifi}})
+		}
+	}
+
+	for _, key := range sortedAddrKeys(prev.addrs) {
+		if _, found := next.addrs[key]; !found {
+			events = append(events, InterfaceEvent{Type: AddressRemoved, IfAddr: prev.addrs[key]})
+		}
+	}
+	for _, key := range sortedAddrKeys(next.addrs) {
+		if _, found := prev.addrs[key]; !found {
+			events = append(events, InterfaceEvent{Type: AddressAdded, IfAddr: next.addrs[key]})
+		}
+	}
+
+	if prev.defaultRoutes != next.defaultRoutes {
+		events = append(events, InterfaceEvent{Type: DefaultRouteChanged, IfAddr: IfAddr{Interface: next.defaultIf}})
+	}
+
+	return events
+}
+
+// sortedLinkNames returns the interface names of a and b in lexical order.
+func sortedLinkNames(a, b map[string]net.Interface) []string {
+	names := make([]string, 0, len(a)+len(b))
+	for name := range a {
+		names = append(names, name)
+	}
+	for name := range b {
+		if _, found := a[name]; !found {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// sortedAddrKeys returns the keys of addrs in lexical order.
+func sortedAddrKeys(addrs map[string]IfAddr) []string {
+	keys := make([]string, 0, len(addrs))
+	for key := range addrs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,72 @@
+package sockaddr
+
+import (
+	"context"
+	"os"
+	"syscall"
+)
+
+// Multicast groups of the Linux kernel's <linux/rtnetlink.h> that report link,
+// address and route changes.
+const (
+	rtnlGroupLink       = 0x1
+	rtnlGroupIPv4IfAddr = 0x10
+	rtnlGroupIPv4Route  = 0x40
+	rtnlGroupIPv6IfAddr = 0x100
+	rtnlGroupIPv6Route  = 0x400
+)
+
+// interfaceNotifications subscribes to the netlink link, address and route
+// groups and sends on the returned channel whenever a change is reported.  The
+// socket is closed when ctx is done.  The channel is closed if reading the
+// socket fails, after which changes are no longer reported.
+func interfaceNotifications(ctx context.Context) (<-chan struct{}, error) {
+	fd, err := syscall.Socket(syscall.AF_NETLINK, syscall.SOCK_RAW|syscall.SOCK_CLOEXEC|syscall.SOCK_NONBLOCK, syscall.NETLINK_ROUTE)
+	if err != nil {
+		return nil, os.NewSyscallError("socket", err)
+	}
+
+	addr := &syscall.SockaddrNetlink{
+		Family: syscall.AF_NETLINK,
+		Groups: rtnlGroupLink | rtnlGroupIPv4IfAddr | rtnlGroupIPv4Route | rtnlGroupIPv6IfAddr | rtnlGroupIPv6Route,
+	}
+	if err := syscall.Bind(fd, addr); err != nil {
+		syscall.Close(fd)
+		return nil, os.NewSyscallError("bind", err)
+	}
+
+	// The file is registered with the runtime poller, so closing it unblocks
+	// the pending Read.
+	f := os.NewFile(uintptr(fd), "netlink")
+	go func() {
+		<-ctx.Done()
+		f.Close()
+	}()
+
+	notify := make(chan struct{}, 1)
+	go func() {
+		defer close(notify)
+
+		b := make([]byte, os.Getpagesize())
+		for {
+			if _, err := f.Read(b); err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				// ENOBUFS: notifications were dropped, which is still
+				// a reason to read the interfaces again.
+				if err, ok := err.(*os.PathError); !ok || err.Err != syscall.ENOBUFS {
+					f.Close()
+					return
+				}
+			}
+
+			select {
+			case notify <- struct{}{}:
+			default:
+			}
+		}
+	}()
+
+	return notify, nil
+}
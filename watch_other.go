@@ -0,0 +1,14 @@
+// +build !linux
+
+package sockaddr
+
+import (
+	"context"
+	"errors"
+)
+
+// interfaceNotifications is not supported on this platform, so
+// WatchInterfaces polls the interfaces instead.
+func interfaceNotifications(ctx context.Context) (<-chan struct{}, error) {
+	return nil, errors.New("interface notifications are not supported on this platform")
+}
@@ -0,0 +1,281 @@
+package sockaddr
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_diffInterfaceState(t *testing.T) {
+	eth0 := net.Interface{Index: 2, Name: "eth0", Flags: net.FlagUp}
+	eth0Down := net.Interface{Index: 2, Name: "eth0"}
+	tun0 := net.Interface{Index: 3, Name: "tun0", Flags: net.FlagUp | net.FlagPointToPoint}
+
+	state := func(links []net.Interface, addrs []IfAddr, defaultRoutes string) interfaceState {
+		s := interfaceState{
+			links:         make(map[string]net.Interface),
+			addrs:         make(map[string]IfAddr),
+			defaultRoutes: defaultRoutes,
+		}
+		for _, link := range links {
+			s.links[link.Name] = link
+		}
+		for _, addr := range addrs {
+			s.addrs[addr.Name+"|"+addr.SockAddr.String()] = addr
+		}
+		return s
+	}
+
+	prev := state(
+		[]net.Interface{eth0},
+		[]IfAddr{{SockAddr: MustIPv4Addr("10.1.2.5/24"), Interface: eth0}},
+		"0.0.0.0/0 via 10.1.2.1 dev eth0 metric 100",
+	)
+
+	tests := []struct {
+		name string
+		next interfaceState
+		want []string
+	}{
+		{
+			name: "unchanged",
+			next: prev,
+		},
+		{
+			name: "DHCP renewal with a new address",
+			next: state(
+				[]net.Interface{eth0},
+				[]IfAddr{{SockAddr: MustIPv4Addr("10.1.2.9/24"), Interface: eth0}},
+				"0.0.0.0/0 via 10.1.2.1 dev eth0 metric 100",
+			),
+			want: []string{
+				"address removed eth0 10.1.2.5/24",
+				"address added eth0 10.1.2.9/24",
+			},
+		},
+		{
+			name: "VPN comes up",
+			next: state(
+				[]net.Interface{eth0, tun0},
+				[]IfAddr{
+					{SockAddr: MustIPv4Addr("10.1.2.5/24"), Interface: eth0},
+					{SockAddr: MustIPv4Addr("172.30.0.2/32"), Interface: tun0},
+				},
+				"0.0.0.0/0 via  dev tun0 metric 50\n0.0.0.0/0 via 10.1.2.1 dev eth0 metric 100",
+			),
+			want: []string{
+				"link up tun0 <nil>",
+				"address added tun0 172.30.0.2",
+				"default route changed  <nil>",
+			},
+		},
+		{
+			name: "link down",
+			next: state([]net.Interface{eth0Down}, nil, ""),
+			want: []string{
+				"link down eth0 <nil>",
+				"address removed eth0 10.1.2.5/24",
+				"default route changed  <nil>",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			events := diffInterfaceState(prev, test.next)
+			if len(events) != len(test.want) {
+				t.Fatalf("want %d events, got %d: %+v", len(test.want), len(events), events)
+			}
+			for i, event := range events {
+				got := event.Type.String() + " " + event.IfAddr.Name + " " + sockAddrString(event.IfAddr.SockAddr)
+				if got != test.want[i] {
+					t.Errorf("event %d: want %q, got %q", i, test.want[i], got)
+				}
+			}
+		})
+	}
+}
+
+func TestWatchInterfaces(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	events := WatchInterfaces(ctx)
+	cancel()
+
+	select {
+	case _, ok := <-events:
+		if ok {
+			// An event may be sent before the cancellation is seen.
+			for range events {
+			}
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected the channel to be closed after the context is done")
+	}
+}
+
+// flakySystem is a FakeSystem whose interfaces can not be listed the first
+// failures times.  It counts how often the interfaces are listed.
+type flakySystem struct {
+	*FakeSystem
+
+	lock     sync.Mutex
+	failures int
+	reads    int
+}
+
+func (s *flakySystem) Interfaces() ([]net.Interface, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.reads++
+	if s.failures > 0 {
+		s.failures--
+		return nil, errors.New("interfaces unavailable")
+	}
+	return s.FakeSystem.Interfaces()
+}
+
+// waitForReads waits until the interfaces of s have been listed at least n
+// times.
+func (s *flakySystem) waitForReads(t *testing.T, n int) {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		s.lock.Lock()
+		reads := s.reads
+		s.lock.Unlock()
+		if reads >= n {
+			return
+		}
+	}
+	t.Fatalf("the interfaces were not listed %d times", n)
+}
+
+func Test_watchInterfacesClosedNotify(t *testing.T) {
+	provider := &flakySystem{FakeSystem: NewFakeSystem().
+		AddInterface(net.Interface{Index: 1, Name: "eth0", Flags: net.FlagUp}, MustIPv4Addr("10.0.0.5/24"))}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// A closed notify channel triggers one read, after which the
+	// interfaces are polled instead of read in a busy loop.
+	notify := make(chan struct{})
+	close(notify)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		watchInterfaces(ctx, provider, notify, time.Hour, make(chan InterfaceEvent))
+	}()
+
+	// Each read of the interface state lists the interfaces twice.
+	provider.waitForReads(t, 4)
+	time.Sleep(5 * watchSettleDelay)
+	provider.lock.Lock()
+	reads := provider.reads
+	provider.lock.Unlock()
+	if reads != 4 {
+		t.Errorf("want 4 reads before the next poll, got %d", reads)
+	}
+
+	cancel()
+	<-done
+}
+
+func Test_watchInterfacesBaseline(t *testing.T) {
+	provider := &flakySystem{
+		FakeSystem: NewFakeSystem().
+			AddInterface(net.Interface{Index: 1, Name: "eth0", Flags: net.FlagUp}, MustIPv4Addr("10.0.0.5/24")),
+		failures: 1,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	notify := make(chan struct{}, 1)
+	events := make(chan InterfaceEvent, 16)
+	go watchInterfaces(ctx, provider, notify, time.Hour, events)
+
+	// The first read fails, so the next read is the baseline and does not
+	// report the existing interfaces as new.
+	provider.waitForReads(t, 1)
+	notify <- struct{}{}
+	provider.waitForReads(t, 3)
+
+	provider.AddInterface(net.Interface{Index: 2, Name: "eth1", Flags: net.FlagUp}, MustIPv4Addr("192.168.1.5/24"))
+	notify <- struct{}{}
+
+	want := []string{"link up eth1 <nil>", "address added eth1 192.168.1.5/24"}
+	for i := range want {
+		select {
+		case event := <-events:
+			got := event.Type.String() + " " + event.IfAddr.Name + " " + sockAddrString(event.IfAddr.SockAddr)
+			if got != want[i] {
+				t.Errorf("event %d: want %q, got %q", i, want[i], got)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for event %d", i)
+		}
+	}
+}
+
+// unreadableSystem is a FakeSystem whose tun0 addresses can not be read while
+// unreadable is set.
+type unreadableSystem struct {
+	*FakeSystem
+	unreadable bool
+}
+
+func (s *unreadableSystem) Addrs(ifi net.Interface) ([]net.Addr, error) {
+	if s.unreadable && ifi.Name == "tun0" {
+		return nil, errors.New("tunnel is gone")
+	}
+	return s.FakeSystem.Addrs(ifi)
+}
+
+func Test_readInterfaceStateUnreadable(t *testing.T) {
+	provider := &unreadableSystem{FakeSystem: NewFakeSystem().
+		AddInterface(net.Interface{Index: 1, Name: "eth0", Flags: net.FlagUp}, MustIPv4Addr("10.0.0.5/24")).
+		AddInterface(net.Interface{Index: 2, Name: "tun0", Flags: net.FlagUp}, MustIPv4Addr("172.16.0.5/30"))}
+
+	prev, err := readInterfaceState(context.Background(), provider)
+	if err != nil {
+		t.Fatalf("unable to read the interfaces: %v", err)
+	}
+
+	provider.unreadable = true
+	next, err := readInterfaceState(context.Background(), provider)
+	if err != nil {
+		t.Fatalf("expected the tun0 error to be skipped, got %v", err)
+	}
+	if len(next.addrs) != 1 || !next.unreadIfs["tun0"] {
+		t.Fatalf("want the eth0 address and tun0 unread, got %v %v", next.addrs, next.unreadIfs)
+	}
+
+	// The addresses of tun0 are not reported as removed.
+	next.keepUnreadAddrs(prev)
+	if events := diffInterfaceState(prev, next); len(events) != 0 {
+		t.Errorf("want no events, got %v", events)
+	}
+
+	// Nor are they reported as added once they can be read again.
+	provider.unreadable = false
+	last, err := readInterfaceState(context.Background(), provider)
+	if err != nil {
+		t.Fatalf("unable to read the interfaces: %v", err)
+	}
+	last.keepUnreadAddrs(next)
+	if events := diffInterfaceState(next, last); len(events) != 0 {
+		t.Errorf("want no events, got %v", events)
+	}
+}
+
+// sockAddrString returns the string of sa, or "<nil>" if sa is nil.
+func sockAddrString(sa SockAddr) string {
+	if sa == nil {
+		return "<nil>"
+	}
+	return sa.String()
+}